@@ -0,0 +1,61 @@
+package http
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// AttachmentHandler serves file uploads that threads and replies link by ID.
+type AttachmentHandler struct {
+	svc usecases.AttachmentService
+}
+
+func NewAttachmentHandler(svc usecases.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc}
+}
+
+// Upload handles POST /attachments, a multipart form with a single "file" field. The
+// returned ID goes into attachment_ids when creating a thread or reply.
+func (h *AttachmentHandler) Upload(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing file"})
+	}
+	src, err := fh.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "failed to open uploaded file"})
+	}
+	defer src.Close()
+	userID, _ := c.Locals("user_id").(int)
+	a, err := h.svc.Upload(c.UserContext(), userID, fh.Filename, src)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(a)
+}
+
+// Download handles GET /attachments/:id. The sniffed type is sent with nosniff, and only
+// images are shown inline so uploaded documents cannot run in the site's origin.
+func (h *AttachmentHandler) Download(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid attachment id"})
+	}
+	a, rc, err := h.svc.Open(c.UserContext(), id)
+	if err != nil {
+		return respondError(c, err)
+	}
+	disposition := "attachment"
+	if strings.HasPrefix(a.ContentType, "image/") {
+		disposition = "inline"
+	}
+	c.Set(fiber.HeaderContentType, a.ContentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	// fasthttp closes rc once the body is sent
+	return c.SendStream(rc, int(a.Size))
+}
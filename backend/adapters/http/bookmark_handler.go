@@ -0,0 +1,85 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// BookmarkHandler serves the authenticated user's bookmarks.
+type BookmarkHandler struct {
+	svc usecases.BookmarkService
+}
+
+func NewBookmarkHandler(svc usecases.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{svc: svc}
+}
+
+type bookmarkReq struct {
+	ThreadID *int `json:"thread_id"`
+	ReplyID  *int `json:"reply_id"`
+	// Folder is optional; empty leaves the bookmark unfiled
+	Folder string `json:"folder"`
+}
+
+// parseBookmarkReq reads the target from the JSON body, falling back to the thread_id or
+// reply_id query parameter as RetractVote does.
+func parseBookmarkReq(c *fiber.Ctx) (*bookmarkReq, error) {
+	var req bookmarkReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return nil, err
+		}
+	}
+	if req.ThreadID == nil && req.ReplyID == nil {
+		if id := c.QueryInt("thread_id"); id != 0 {
+			req.ThreadID = &id
+		} else if id := c.QueryInt("reply_id"); id != 0 {
+			req.ReplyID = &id
+		}
+	}
+	return &req, nil
+}
+
+// AddBookmark handles POST /bookmarks; bookmarking a post again moves it to the given folder.
+func (h *BookmarkHandler) AddBookmark(c *fiber.Ctx) error {
+	req, err := parseBookmarkReq(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	b := &entities.Bookmark{UserID: userID, ThreadID: req.ThreadID, ReplyID: req.ReplyID, Folder: req.Folder}
+	if err := h.svc.AddBookmark(c.UserContext(), b); err != nil {
+		return respondError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(b)
+}
+
+// RemoveBookmark handles DELETE /bookmarks.
+func (h *BookmarkHandler) RemoveBookmark(c *fiber.Ctx) error {
+	req, err := parseBookmarkReq(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	if err := h.svc.RemoveBookmark(c.UserContext(), &entities.Bookmark{UserID: userID, ThreadID: req.ThreadID, ReplyID: req.ReplyID}); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListBookmarks handles GET /users/me/bookmarks?limit=&cursor=&folder=. Without folder all
+// bookmarks are listed; an empty folder= lists the unfiled ones.
+func (h *BookmarkHandler) ListBookmarks(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(int)
+	var folder *string
+	if c.Context().QueryArgs().Has("folder") {
+		f := c.Query("folder")
+		folder = &f
+	}
+	page, err := h.svc.ListBookmarks(c.UserContext(), userID, folder, c.QueryInt("limit", usecases.DefaultBookmarkPageSize), c.Query("cursor"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(page)
+}
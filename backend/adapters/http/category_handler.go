@@ -0,0 +1,119 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// CategoryHandler serves category browsing and admin endpoints. Category thread listings reuse ThreadService.
+type CategoryHandler struct {
+	svc       usecases.CategoryService
+	threadSvc usecases.ThreadService
+}
+
+func NewCategoryHandler(svc usecases.CategoryService, threadSvc usecases.ThreadService) *CategoryHandler {
+	return &CategoryHandler{svc: svc, threadSvc: threadSvc}
+}
+
+// ListCategories serves GET /categories as a tree of root categories.
+func (h *CategoryHandler) ListCategories(c *fiber.Ctx) error {
+	cats, err := h.svc.ListCategories(c.UserContext())
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"categories": cats})
+}
+
+// GetCategory serves GET /categories/:slug.
+func (h *CategoryHandler) GetCategory(c *fiber.Ctx) error {
+	cat, err := h.svc.GetCategory(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(cat)
+}
+
+// GetCategoryThreads serves GET /categories/:slug/threads with the same sort/limit/cursor params
+// as GET /threads. Threads in subcategories are included.
+func (h *CategoryHandler) GetCategoryThreads(c *fiber.Ctx) error {
+	cat, err := h.svc.GetCategory(c.UserContext(), c.Params("slug"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	opts, bad := threadListOptions(c)
+	if bad != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": bad})
+	}
+	opts.Category, opts.Tag = cat.Slug, c.Query("tag")
+	page, err := h.threadSvc.ListThreads(c.UserContext(), opts, c.Query("cursor"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"category": cat, "threads": page.Threads, "next_cursor": page.NextCursor})
+}
+
+type categoryReq struct {
+	ParentID    *int                      `json:"parent_id"`
+	Slug        string                    `json:"slug"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Position    int                       `json:"position"`
+	Settings    entities.CategorySettings `json:"settings"`
+}
+
+func (r categoryReq) category(id int) *entities.Category {
+	return &entities.Category{
+		ID:          id,
+		ParentID:    r.ParentID,
+		Slug:        r.Slug,
+		Name:        r.Name,
+		Description: r.Description,
+		Position:    r.Position,
+		Settings:    r.Settings,
+	}
+}
+
+// CreateCategory serves POST /categories (admin). The slug defaults to one derived from the name.
+func (h *CategoryHandler) CreateCategory(c *fiber.Ctx) error {
+	var req categoryReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	cat := req.category(0)
+	id, err := h.svc.CreateCategory(c.UserContext(), cat)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": id, "slug": cat.Slug})
+}
+
+// UpdateCategory serves PUT /categories/:id (admin), replacing all editable fields.
+func (h *CategoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req categoryReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	if err := h.svc.UpdateCategory(c.UserContext(), req.category(id)); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteCategory serves DELETE /categories/:id (admin). Only empty leaf categories can be deleted.
+func (h *CategoryHandler) DeleteCategory(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	if err := h.svc.DeleteCategory(c.UserContext(), id); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
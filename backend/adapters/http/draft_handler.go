@@ -0,0 +1,55 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// DraftHandler serves the caller's autosaved drafts. Every route requires authentication.
+type DraftHandler struct {
+	svc usecases.DraftService
+}
+
+func NewDraftHandler(svc usecases.DraftService) *DraftHandler {
+	return &DraftHandler{svc: svc}
+}
+
+type saveDraftReq struct {
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Tags  []string `json:"tags"`
+}
+
+// SaveDraft handles PUT /drafts/:key; saving again overwrites the draft and extends its expiry.
+func (h *DraftHandler) SaveDraft(c *fiber.Ctx) error {
+	var req saveDraftReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	d := &entities.Draft{Key: c.Params("key"), Title: req.Title, Body: req.Body, Tags: req.Tags}
+	if err := h.svc.SaveDraft(c.UserContext(), userID, d); err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(d)
+}
+
+// ListDrafts handles GET /drafts.
+func (h *DraftHandler) ListDrafts(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(int)
+	drafts, err := h.svc.ListDrafts(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"drafts": drafts})
+}
+
+// DeleteDraft handles DELETE /drafts/:key.
+func (h *DraftHandler) DeleteDraft(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(int)
+	if err := h.svc.DeleteDraft(c.UserContext(), userID, c.Params("key")); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
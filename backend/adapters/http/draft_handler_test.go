@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/nocson47/beaconofknowledge/adapters/jwt"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDraftService implements usecases.DraftService, remembering saved drafts by user
+type fakeDraftService struct {
+	saved map[int]*entities.Draft
+}
+
+func (f *fakeDraftService) SaveDraft(ctx context.Context, userID int, d *entities.Draft) error {
+	f.saved[userID] = d
+	return nil
+}
+func (f *fakeDraftService) ListDrafts(ctx context.Context, userID int) ([]*entities.Draft, error) {
+	return nil, nil
+}
+func (f *fakeDraftService) DeleteDraft(ctx context.Context, userID int, key string) error { return nil }
+func (f *fakeDraftService) PurgeExpiredDrafts(ctx context.Context) (int64, error)         { return 0, nil }
+
+// tokenExpiredAgo signs an access token for userID that expired the given time ago.
+func tokenExpiredAgo(t *testing.T, userID int, ago time.Duration) string {
+	token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(-ago).Unix(),
+	})
+	s, err := token.SignedString(jwt.JwtSecret())
+	require.NoError(t, err)
+	return s
+}
+
+func TestSaveDraft_AcceptsRecentlyExpiredToken(t *testing.T) {
+	svc := &fakeDraftService{saved: map[int]*entities.Draft{}}
+	h := NewDraftHandler(svc)
+	app := fiber.New()
+	app.Get("/drafts", RequireAuth(), h.ListDrafts)
+	app.Put("/drafts/:key", RequireAuthGrace(DraftAutosaveGrace), h.SaveDraft)
+
+	save := func(token string) *http.Response {
+		req := httptest.NewRequest("PUT", "/drafts/thread:new", strings.NewReader(`{"title":"t","body":"unsaved work"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	fresh, err := jwt.GenerateToken(7)
+	require.NoError(t, err)
+	resp := save(fresh)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("X-Token-Expired"))
+
+	// an autosave just after the token lapsed still lands, and the client is told to sign in again
+	expired := tokenExpiredAgo(t, 8, time.Minute)
+	resp = save(expired)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get("X-Token-Expired"))
+	require.Equal(t, "unsaved work", svc.saved[8].Body)
+
+	// the grace is only for saving
+	req := httptest.NewRequest("GET", "/drafts", nil)
+	req.Header.Set("Authorization", "Bearer "+expired)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+
+	resp = save(tokenExpiredAgo(t, 9, 2*DraftAutosaveGrace))
+	require.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	require.Nil(t, svc.saved[9])
+}
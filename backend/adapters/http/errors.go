@@ -0,0 +1,29 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// errorStatus maps usecase error kinds to HTTP status codes; anything else is a 500.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidInput):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecases.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecases.ErrForbidden):
+		return fiber.StatusForbidden
+	case errors.Is(err, usecases.ErrConflict):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// respondError writes err as the usual {"error": ...} body with a status derived from its kind.
+func respondError(c *fiber.Ctx, err error) error {
+	return c.Status(errorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+}
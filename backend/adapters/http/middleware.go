@@ -14,6 +14,36 @@ import (
 	"github.com/nocson47/beaconofknowledge/internal/usecases"
 )
 
+// bearerToken extracts the token from an Authorization header value.
+func bearerToken(auth string) string {
+	// Support headers like: "Bearer <token>" (case-insensitive) and trim spaces
+	token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	if token == "" {
+		// try lowercase bearer
+		token = strings.TrimSpace(strings.TrimPrefix(auth, "bearer "))
+	}
+	return token
+}
+
+// setViewer stores the authenticated user in locals and in the request context for services.
+func setViewer(c *fiber.Ctx, uid int) {
+	c.Locals("user_id", uid)
+	c.SetUserContext(usecases.WithViewer(c.UserContext(), uid))
+}
+
+// OptionalAuth identifies the caller when a valid Bearer token is sent and otherwise lets the
+// request through anonymously. Public reads use it to include per-user state.
+func OptionalAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if auth := c.Get("Authorization"); auth != "" {
+			if uid, err := jwt.ParseToken(bearerToken(auth)); err == nil && uid != 0 {
+				setViewer(c, uid)
+			}
+		}
+		return c.Next()
+	}
+}
+
 // RequireAuth checks Authorization Bearer token and sets user id in locals
 func RequireAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -21,12 +51,7 @@ func RequireAuth() fiber.Handler {
 		if auth == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "missing authorization"})
 		}
-		// Support headers like: "Bearer <token>" (case-insensitive) and trim spaces
-		token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
-		if token == "" {
-			// try lowercase bearer
-			token = strings.TrimSpace(strings.TrimPrefix(auth, "bearer "))
-		}
+		token := bearerToken(auth)
 		uid, err := jwt.ParseToken(token)
 		if err != nil || uid == 0 {
 			// Log parse error to help debugging token issues (don't log the token value)
@@ -37,7 +62,34 @@ func RequireAuth() fiber.Handler {
 			}
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
 		}
-		c.Locals("user_id", uid)
+		setViewer(c, uid)
+		return c.Next()
+	}
+}
+
+// DraftAutosaveGrace is how long after expiry an access token may still autosave drafts.
+const DraftAutosaveGrace = time.Hour
+
+// RequireAuthGrace is RequireAuth but also accepts a token that expired less than grace ago,
+// so background writes such as draft autosave do not lose work the moment the 15-minute
+// token lapses. Such requests get an X-Token-Expired header telling the client to sign in again.
+func RequireAuthGrace(grace time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		auth := c.Get("Authorization")
+		if auth == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "missing authorization"})
+		}
+		token := bearerToken(auth)
+		uid, err := jwt.ParseToken(token)
+		if err != nil {
+			if uid, err = jwt.ParseTokenWithLeeway(token, grace); err == nil {
+				c.Set("X-Token-Expired", "true")
+			}
+		}
+		if err != nil || uid == 0 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token"})
+		}
+		setViewer(c, uid)
 		return c.Next()
 	}
 }
@@ -113,6 +165,26 @@ func RateLimiterStrict() fiber.Handler {
 	})
 }
 
+// BodyLimit rejects requests whose body is larger than limit with 413. The server-wide
+// fiber limit has to admit the largest upload, so this keeps every route except the upload
+// endpoint at the usual size.
+func BodyLimit(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if isUpload(c) {
+			return c.Next()
+		}
+		if c.Request().Header.ContentLength() > limit || len(c.Body()) > limit {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "request body too large"})
+		}
+		return c.Next()
+	}
+}
+
+// isUpload matches POST /attachments, the only route allowed past the default body limit.
+func isUpload(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodPost && strings.TrimSuffix(c.Path(), "/") == "/attachments"
+}
+
 // AdminOnly ensures the authenticated user has role "admin".
 // It requires a UserService to lookup the user and check role.
 func AdminOnly(userSvc usecases.UserService) fiber.Handler {
@@ -265,6 +337,7 @@ func Cors() fiber.Handler {
 		AllowOrigins:     "http://localhost:5173",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
 		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		ExposeHeaders:    "X-Token-Expired",
 		AllowCredentials: true,
 	})
 }
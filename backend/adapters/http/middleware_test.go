@@ -0,0 +1,33 @@
+package http
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimit_OnlyUploadsMayBeLarge(t *testing.T) {
+	app := fiber.New(fiber.Config{BodyLimit: 1 << 10})
+	app.Use(BodyLimit(16))
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+	app.Post("/threads", ok)
+	app.Post("/attachments", ok)
+
+	body := strings.Repeat("x", 64)
+	for path, want := range map[string]int{
+		"/threads":      fiber.StatusRequestEntityTooLarge,
+		"/attachments":  fiber.StatusNoContent,
+		"/attachments/": fiber.StatusNoContent,
+	} {
+		resp, err := app.Test(httptest.NewRequest("POST", path, strings.NewReader(body)))
+		require.NoError(t, err)
+		require.Equal(t, want, resp.StatusCode, path)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/threads", strings.NewReader("small")))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// NotificationHandler serves the authenticated user's in-app notifications.
+type NotificationHandler struct {
+	svc usecases.NotificationService
+}
+
+func NewNotificationHandler(svc usecases.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: svc}
+}
+
+// ListNotifications handles GET /notifications?limit=&cursor=&unread=true.
+func (h *NotificationHandler) ListNotifications(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(int)
+	page, err := h.svc.ListNotifications(c.UserContext(), userID, c.QueryInt("limit", usecases.DefaultNotificationPageSize), c.Query("cursor"), c.QueryBool("unread"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(page)
+}
+
+type markReadReq struct {
+	IDs []int `json:"ids"`
+	// All marks every notification read; IDs are ignored
+	All bool `json:"all"`
+}
+
+// MarkRead handles POST /notifications/read with either {"ids": [...]} or {"all": true}.
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	var req markReadReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	var err error
+	if req.All {
+		err = h.svc.MarkAllRead(c.UserContext(), userID)
+	} else {
+		err = h.svc.MarkRead(c.UserContext(), userID, req.IDs)
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnreadCount handles GET /notifications/unread-count.
+func (h *NotificationHandler) UnreadCount(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(int)
+	n, err := h.svc.UnreadCount(c.UserContext(), userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"unread": n})
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// PollHandler serves the poll attached to a thread.
+type PollHandler struct {
+	svc usecases.PollService
+}
+
+func NewPollHandler(svc usecases.PollService) *PollHandler {
+	return &PollHandler{svc: svc}
+}
+
+type createPollReq struct {
+	Question          string     `json:"question"`
+	Options           []string   `json:"options"`
+	MultipleChoice    bool       `json:"multiple_choice"`
+	ResultsBeforeVote *bool      `json:"results_before_vote"`
+	ClosesAt          *time.Time `json:"closes_at"`
+}
+
+// CreatePoll handles POST /threads/:id/poll. results_before_vote defaults to true.
+func (h *PollHandler) CreatePoll(c *fiber.Ctx) error {
+	threadID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req createPollReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	p := &entities.Poll{
+		ThreadID:          threadID,
+		Question:          req.Question,
+		MultipleChoice:    req.MultipleChoice,
+		ResultsBeforeVote: req.ResultsBeforeVote == nil || *req.ResultsBeforeVote,
+		ClosesAt:          req.ClosesAt,
+	}
+	for _, text := range req.Options {
+		p.Options = append(p.Options, entities.PollOption{Text: text})
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	if _, err := h.svc.CreatePoll(c.UserContext(), p, actorID); err != nil {
+		return respondError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(p)
+}
+
+// GetPoll handles GET /threads/:id/poll.
+func (h *PollHandler) GetPoll(c *fiber.Ctx) error {
+	threadID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	p, err := h.svc.GetPoll(c.UserContext(), threadID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(p)
+}
+
+type castBallotReq struct {
+	OptionIDs []int `json:"option_ids"`
+}
+
+// CastBallot handles PUT /threads/:id/poll/ballot; casting again replaces the earlier ballot.
+func (h *PollHandler) CastBallot(c *fiber.Ctx) error {
+	threadID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req castBallotReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	p, err := h.svc.CastBallot(c.UserContext(), threadID, userID, req.OptionIDs)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(p)
+}
+
+// ClosePoll handles POST /threads/:id/poll/close.
+func (h *PollHandler) ClosePoll(c *fiber.Ctx) error {
+	threadID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	if err := h.svc.ClosePoll(c.UserContext(), threadID, actorID); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// ReactionHandler serves emoji reactions on threads and replies.
+type ReactionHandler struct {
+	svc usecases.ReactionService
+}
+
+func NewReactionHandler(svc usecases.ReactionService) *ReactionHandler {
+	return &ReactionHandler{svc: svc}
+}
+
+type reactionReq struct {
+	ThreadID *int   `json:"thread_id"`
+	ReplyID  *int   `json:"reply_id"`
+	Emoji    string `json:"emoji"`
+}
+
+// parseReactionReq reads the JSON body, falling back to query parameters for DELETE
+// clients that cannot send one.
+func parseReactionReq(c *fiber.Ctx) (*reactionReq, error) {
+	var req reactionReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return nil, err
+		}
+	}
+	if req.ThreadID == nil && req.ReplyID == nil {
+		if id := c.QueryInt("thread_id"); id != 0 {
+			req.ThreadID = &id
+		} else if id := c.QueryInt("reply_id"); id != 0 {
+			req.ReplyID = &id
+		}
+	}
+	if req.Emoji == "" {
+		req.Emoji = c.Query("emoji")
+	}
+	return &req, nil
+}
+
+// ListEmojis handles GET /reactions, the configured reaction set.
+func (h *ReactionHandler) ListEmojis(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"emojis": h.svc.Emojis()})
+}
+
+// AddReaction handles POST /reactions and responds with the post's updated counts.
+func (h *ReactionHandler) AddReaction(c *fiber.Ctx) error {
+	req, err := parseReactionReq(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	r := &entities.Reaction{UserID: userID, ThreadID: req.ThreadID, ReplyID: req.ReplyID, Emoji: req.Emoji}
+	if err := h.svc.AddReaction(c.UserContext(), r); err != nil {
+		return respondError(c, err)
+	}
+	return h.respondCounts(c, fiber.StatusCreated, r)
+}
+
+// RemoveReaction handles DELETE /reactions and responds with the post's updated counts.
+func (h *ReactionHandler) RemoveReaction(c *fiber.Ctx) error {
+	req, err := parseReactionReq(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	r := &entities.Reaction{UserID: userID, ThreadID: req.ThreadID, ReplyID: req.ReplyID, Emoji: req.Emoji}
+	if err := h.svc.RemoveReaction(c.UserContext(), r); err != nil {
+		return respondError(c, err)
+	}
+	return h.respondCounts(c, fiber.StatusOK, r)
+}
+
+func (h *ReactionHandler) respondCounts(c *fiber.Ctx, status int, r *entities.Reaction) error {
+	counts, err := h.svc.Counts(c.UserContext(), r.ThreadID, r.ReplyID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.Status(status).JSON(fiber.Map{"thread_id": r.ThreadID, "reply_id": r.ReplyID, "reactions": counts})
+}
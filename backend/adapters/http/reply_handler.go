@@ -25,6 +25,8 @@ type createReplyReq struct {
 	ThreadID int    `json:"thread_id"`
 	ParentID *int   `json:"parent_id,omitempty"`
 	Body     string `json:"body"`
+	// AttachmentIDs link uploads from POST /attachments to the reply
+	AttachmentIDs []int `json:"attachment_ids,omitempty"`
 }
 
 func (h *ReplyHandler) CreateReply(c *fiber.Ctx) error {
@@ -43,14 +45,15 @@ func (h *ReplyHandler) CreateReply(c *fiber.Ctx) error {
 	}
 
 	rep := &entities.Reply{
-		ThreadID: req.ThreadID,
-		UserID:   uid,
-		ParentID: req.ParentID,
-		Body:     req.Body,
+		ThreadID:      req.ThreadID,
+		UserID:        uid,
+		ParentID:      req.ParentID,
+		Body:          req.Body,
+		AttachmentIDs: req.AttachmentIDs,
 	}
 	id, err := h.svc.CreateReply(c.UserContext(), rep)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		return respondError(c, err)
 	}
 	// invalidate thread cache
 	if h.cache != nil {
@@ -69,11 +72,42 @@ func (h *ReplyHandler) GetRepliesByThread(c *fiber.Ctx) error {
 	}
 	reps, err := h.svc.GetRepliesByThread(c.UserContext(), id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return respondError(c, err)
 	}
 	return c.JSON(reps)
 }
 
+// GetThreadReplies serves GET /threads/:id/replies. The default view is the flat list returned by
+// GET /replies/thread/:thread_id; view=tree nests replies and accepts depth, limit, child_limit
+// and cursor (a next_cursor or more_children_cursor from a previous tree response).
+func (h *ReplyHandler) GetThreadReplies(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid thread id"})
+	}
+	switch c.Query("view", "flat") {
+	case "flat":
+		reps, err := h.svc.GetRepliesByThread(c.UserContext(), id)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(reps)
+	case "tree":
+		opts := entities.ReplyTreeOptions{
+			MaxDepth:   c.QueryInt("depth", usecases.DefaultReplyTreeDepth),
+			Limit:      c.QueryInt("limit", usecases.DefaultReplyPageSize),
+			ChildLimit: c.QueryInt("child_limit", usecases.DefaultReplyChildren),
+		}
+		tree, err := h.svc.GetReplyTree(c.UserContext(), id, opts, c.Query("cursor"))
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(tree)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "view must be flat or tree"})
+	}
+}
+
 func (h *ReplyHandler) DeleteReply(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := strconv.Atoi(idStr)
@@ -95,23 +129,21 @@ func (h *ReplyHandler) DeleteReply(c *fiber.Ctx) error {
 	if uerr == nil && usr != nil && usr.Role == "admin" {
 		isAdmin = true
 	}
-	if err := h.svc.DeleteReply(c.UserContext(), id, uid, isAdmin); err != nil {
-		// Forbidden vs other errors
-		if err.Error() == "forbidden: cannot delete others' replies" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	threadID, err := h.svc.DeleteReply(c.UserContext(), id, uid, isAdmin)
+	if err != nil {
+		return respondError(c, err)
 	}
-	// Note: we don't know the thread id here easily; a simple approach is to evict a generic key
+	// the cached thread carries the reply count and may name this reply as its accepted answer
 	if h.cache != nil {
-		// If you want precise invalidation, ReplyService.DeleteReply could return threadID.
-		// For now, evict a small pattern could be used by admins, but redis doesn't support DEL by pattern efficiently without scan.
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", threadID))
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 type updateReplyReq struct {
 	Body string `json:"body"`
+	// EditReason is an optional note stored with the revision this edit creates
+	EditReason string `json:"edit_reason,omitempty"`
 }
 
 func (h *ReplyHandler) UpdateReply(c *fiber.Ctx) error {
@@ -140,11 +172,9 @@ func (h *ReplyHandler) UpdateReply(c *fiber.Ctx) error {
 	}
 
 	rep := &entities.Reply{ID: id, Body: req.Body}
-	if err := h.svc.UpdateReply(c.UserContext(), rep, uid, isAdmin); err != nil {
-		if err.Error() == "forbidden: cannot edit others' replies" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
-		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	edit := entities.EditInfo{EditorID: uid, Reason: req.EditReason}
+	if err := h.svc.UpdateReply(c.UserContext(), rep, edit, isAdmin); err != nil {
+		return respondError(c, err)
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
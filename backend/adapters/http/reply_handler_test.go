@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReplyService deletes replies of thread 1 and fails edits with updateErr; the methods it
+// does not need are left nil
+type fakeReplyService struct {
+	usecases.ReplyService
+	updateErr error
+}
+
+func (f *fakeReplyService) DeleteReply(ctx context.Context, id int, actorUserID int, isAdmin bool) (int, error) {
+	return 1, nil
+}
+
+func (f *fakeReplyService) UpdateReply(ctx context.Context, r *entities.Reply, edit entities.EditInfo, isAdmin bool) error {
+	return f.updateErr
+}
+
+// fakeUserService knows only plain members
+type fakeUserService struct {
+	usecases.UserService
+}
+
+func (f *fakeUserService) GetUserByID(ctx context.Context, id int) (*entities.User, error) {
+	return &entities.User{ID: id}, nil
+}
+
+func TestDeleteReply_EvictsCachedThread(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	require.NoError(t, mr.Set("thread:1", `{"id":1}`))
+
+	h := NewReplyHandler(&fakeReplyService{}, &fakeUserService{}, rdb)
+	app := fiber.New()
+	app.Delete("/replies/:id", func(c *fiber.Ctx) error {
+		c.Locals("user_id", 2)
+		return h.DeleteReply(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/replies/5", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	require.False(t, mr.Exists("thread:1"))
+}
+
+func TestUpdateReply_LockedThreadIsForbidden(t *testing.T) {
+	// the service rejects a member's edit on a locked thread as forbidden
+	svc := &fakeReplyService{updateErr: fmt.Errorf("thread is locked: %w", usecases.ErrForbidden)}
+	h := NewReplyHandler(svc, &fakeUserService{}, nil)
+	app := fiber.New()
+	app.Put("/replies/:id", func(c *fiber.Ctx) error {
+		c.Locals("user_id", 2)
+		return h.UpdateReply(c)
+	})
+
+	req := httptest.NewRequest("PUT", "/replies/5", strings.NewReader(`{"body":"edited"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// RevisionHandler serves edit history for threads and replies. The same handlers are mounted
+// under /threads/:id and /replies/:id; the kind is fixed when the route is registered.
+type RevisionHandler struct {
+	svc   usecases.RevisionService
+	cache *redis.Client
+}
+
+func NewRevisionHandler(svc usecases.RevisionService, cache *redis.Client) *RevisionHandler {
+	return &RevisionHandler{svc: svc, cache: cache}
+}
+
+// ListRevisions serves GET /threads/:id/revisions and GET /replies/:id/revisions.
+func (h *RevisionHandler) ListRevisions(kind entities.RevisionKind) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+		}
+		revs, err := h.svc.ListRevisions(c.UserContext(), kind, id)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(fiber.Map{"revisions": revs})
+	}
+}
+
+// Diff serves GET .../revisions/diff?from=<rev>&to=<rev|current>. Omitting either side means current.
+func (h *RevisionHandler) Diff(kind entities.RevisionKind) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+		}
+		from, ferr := revisionParam(c.Query("from"))
+		to, terr := revisionParam(c.Query("to"))
+		if ferr != nil || terr != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from and to must be revision ids or \"current\""})
+		}
+		d, err := h.svc.Diff(c.UserContext(), kind, id, from, to)
+		if err != nil {
+			return respondError(c, err)
+		}
+		return c.JSON(d)
+	}
+}
+
+// revisionParam parses a revision id where "" and "current" mean the live version (0).
+func revisionParam(s string) (int, error) {
+	if s == "" || s == "current" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+type revertReq struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// Revert serves POST .../revisions/:rev/revert (admin only).
+func (h *RevisionHandler) Revert(kind entities.RevisionKind) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+		}
+		revID, err := strconv.Atoi(c.Params("rev"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid revision id"})
+		}
+		var req revertReq
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+			}
+		}
+		uid, _ := c.Locals("user_id").(int)
+		if err := h.svc.Revert(c.UserContext(), kind, id, revID, entities.EditInfo{EditorID: uid, Reason: req.Reason}); err != nil {
+			return respondError(c, err)
+		}
+		if h.cache != nil && kind == entities.RevisionKindThread {
+			h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -2,10 +2,15 @@ package http
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
 	"github.com/nocson47/beaconofknowledge/internal/usecases"
 )
 
-func SetupRouter(app *fiber.App, userHandler *UserHandler, userSvc usecases.UserService, threadHandler *ThreadHandler, threadSvc usecases.ThreadService, voteHandler *VoteHandler, replyHandler *ReplyHandler, reportHandler *ReportHandler, authHandler *AuthHandler) {
+func SetupRouter(app *fiber.App, userHandler *UserHandler, userSvc usecases.UserService, threadHandler *ThreadHandler, threadSvc usecases.ThreadService, voteHandler *VoteHandler, replyHandler *ReplyHandler, reportHandler *ReportHandler, authHandler *AuthHandler, searchHandler *SearchHandler, tagHandler *TagHandler, revisionHandler *RevisionHandler, categoryHandler *CategoryHandler, pollHandler *PollHandler, draftHandler *DraftHandler, subscriptionHandler *SubscriptionHandler, notificationHandler *NotificationHandler, bookmarkHandler *BookmarkHandler, reactionHandler *ReactionHandler, attachmentHandler *AttachmentHandler) {
+	// Identify the caller on every route when a token is sent, so public reads can include
+	// per-user state such as my_vote; RequireAuth still guards the protected routes
+	app.Use(OptionalAuth())
+
 	// Debug endpoints (file upload for avatar)
 	dbg := NewDebugHandler()
 	app.Post("/debug/avatar", dbg.UploadAvatar)
@@ -20,6 +25,8 @@ func SetupRouter(app *fiber.App, userHandler *UserHandler, userSvc usecases.User
 	users.Get("/", userHandler.GetAllUsers)
 	// current user info
 	users.Get("/me", RequireAuth(), userHandler.GetMe)
+	users.Get("/me/watching", RequireAuth(), subscriptionHandler.ListWatching) // GET /users/me/watching
+	users.Get("/me/bookmarks", RequireAuth(), bookmarkHandler.ListBookmarks)   // GET /users/me/bookmarks
 	users.Get(":id", userHandler.GetUserByID)
 
 	// use a separate path for username lookup to avoid conflicting with the :id route
@@ -35,16 +42,45 @@ func SetupRouter(app *fiber.App, userHandler *UserHandler, userSvc usecases.User
 
 	// Thread routes
 	threads := app.Group("/threads")
-	threads.Get("/", threadHandler.GetAllThreads)                                   // GET /threads
+	threads.Get("/", threadHandler.ListThreads)                                     // GET /threads?sort=&limit=&cursor=&author=
 	threads.Post("/", RequireAuth(), RateLimiterAuth(), threadHandler.CreateThread) // POST /threads
+	threads.Get("/trending", threadHandler.ListTrending)                            // GET /threads/trending?limit=&cursor=
 	threads.Get("/:id", threadHandler.GetThreadByID)                                // GET /threads/:id
+	threads.Get("/:id/related", threadHandler.GetRelatedThreads)                    // GET /threads/:id/related?limit=
 	// Only owner or admin may update/delete a thread
 	threads.Put("/:id", RequireAuth(), RateLimiterAuth(), OwnerOrAdmin(userSvc, threadSvc), threadHandler.UpdateThread)    // PUT /threads/:id
 	threads.Delete("/:id", RequireAuth(), RateLimiterAuth(), OwnerOrAdmin(userSvc, threadSvc), threadHandler.DeleteThread) // DELETE /threads/:id
+	// Moderation: locked threads reject new replies and votes
+	threads.Post("/:id/lock", RequireAuth(), AdminOnly(userSvc), threadHandler.LockThread)     // POST /threads/:id/lock
+	threads.Post("/:id/unlock", RequireAuth(), AdminOnly(userSvc), threadHandler.UnlockThread) // POST /threads/:id/unlock
+	threads.Post("/:id/pin", RequireAuth(), AdminOnly(userSvc), threadHandler.PinThread)       // POST /threads/:id/pin
+	threads.Post("/:id/unpin", RequireAuth(), AdminOnly(userSvc), threadHandler.UnpinThread)   // POST /threads/:id/unpin
+	threads.Post("/:id/merge", RequireAuth(), AdminOnly(userSvc), threadHandler.MergeThread)   // POST /threads/:id/merge
+	threads.Post("/:id/split", RequireAuth(), AdminOnly(userSvc), threadHandler.SplitThread)   // POST /threads/:id/split
+	// Subscriptions: level all, replies-to-me or muted
+	threads.Post("/:id/watch", RequireAuth(), subscriptionHandler.Watch)     // POST /threads/:id/watch
+	threads.Delete("/:id/watch", RequireAuth(), subscriptionHandler.Unwatch) // DELETE /threads/:id/watch
+	// Scheduled publishing: unpublished threads can be rescheduled or published now
+	threads.Put("/:id/publishing", RequireAuth(), OwnerOrAdmin(userSvc, threadSvc), threadHandler.SetPublishing) // PUT /threads/:id/publishing
+	// Q&A: the thread author or an admin picks the accepted answer
+	threads.Post("/:id/accept", RequireAuth(), threadHandler.AcceptAnswer)     // POST /threads/:id/accept
+	threads.Delete("/:id/accept", RequireAuth(), threadHandler.UnacceptAnswer) // DELETE /threads/:id/accept
+	// Polls: one per thread; ballots freeze when the poll closes or the thread is locked
+	threads.Get("/:id/poll", pollHandler.GetPoll)                                             // GET /threads/:id/poll
+	threads.Post("/:id/poll", RequireAuth(), RateLimiterAuth(), pollHandler.CreatePoll)       // POST /threads/:id/poll
+	threads.Put("/:id/poll/ballot", RequireAuth(), RateLimiterAuth(), pollHandler.CastBallot) // PUT /threads/:id/poll/ballot
+	threads.Post("/:id/poll/close", RequireAuth(), pollHandler.ClosePoll)                     // POST /threads/:id/poll/close
 
 	// Vote routes
 	votes := app.Group("/votes")
-	votes.Post("/", RequireAuth(), RateLimiterAuth(), voteHandler.CreateVote) // POST /votes
+	votes.Post("/", RequireAuth(), RateLimiterAuth(), voteHandler.CreateVote)    // POST /votes
+	votes.Delete("/", RequireAuth(), RateLimiterAuth(), voteHandler.RetractVote) // DELETE /votes
+
+	// Emoji reactions on threads and replies; they leave the vote counters alone
+	reactions := app.Group("/reactions")
+	reactions.Get("/", reactionHandler.ListEmojis)                                          // GET /reactions
+	reactions.Post("/", RequireAuth(), RateLimiterAuth(), reactionHandler.AddReaction)      // POST /reactions
+	reactions.Delete("/", RequireAuth(), RateLimiterAuth(), reactionHandler.RemoveReaction) // DELETE /reactions
 
 	// Thread vote counts
 	threads.Get("/:id/votes", voteHandler.GetThreadCounts) // GET /threads/:id/votes
@@ -53,10 +89,65 @@ func SetupRouter(app *fiber.App, userHandler *UserHandler, userSvc usecases.User
 	replies := app.Group("/replies")
 	replies.Post("/", RequireAuth(), RateLimiterAuth(), replyHandler.CreateReply)
 	replies.Get("/thread/:thread_id", replyHandler.GetRepliesByThread)
+	threads.Get("/:id/replies", replyHandler.GetThreadReplies) // GET /threads/:id/replies?view=flat|tree
+	replies.Get("/:id/votes", voteHandler.GetReplyCounts)      // GET /replies/:id/votes
 	// Allow owner or admin to update or delete a reply
 	replies.Put(":id", RequireAuth(), RateLimiterAuth(), OwnerOrAdminReply(userSvc, replyHandler.svc), replyHandler.UpdateReply)
 	replies.Delete(":id", RequireAuth(), RateLimiterAuth(), OwnerOrAdminReply(userSvc, replyHandler.svc), replyHandler.DeleteReply)
 
+	// Tags: public browsing, admin-only maintenance
+	tags := app.Group("/tags")
+	tags.Get("/", tagHandler.ListTags)                   // GET /tags
+	tags.Get("/:name/threads", tagHandler.GetTagThreads) // GET /tags/:name/threads
+	tags.Delete("/aliases/:alias", RequireAuth(), AdminOnly(userSvc), tagHandler.RemoveAlias)
+	tags.Put("/:name", RequireAuth(), AdminOnly(userSvc), tagHandler.RenameTag)
+	tags.Post("/:name/merge", RequireAuth(), AdminOnly(userSvc), tagHandler.MergeTag)
+	tags.Post("/:name/aliases", RequireAuth(), AdminOnly(userSvc), tagHandler.AddAlias)
+	tags.Delete("/:name", RequireAuth(), AdminOnly(userSvc), tagHandler.DeleteTag)
+
+	// Categories: public browsing, admin-only maintenance
+	categories := app.Group("/categories")
+	categories.Get("/", categoryHandler.ListCategories)                  // GET /categories
+	categories.Get("/:slug", categoryHandler.GetCategory)                // GET /categories/:slug
+	categories.Get("/:slug/threads", categoryHandler.GetCategoryThreads) // GET /categories/:slug/threads
+	categories.Post("/", RequireAuth(), AdminOnly(userSvc), categoryHandler.CreateCategory)
+	categories.Put("/:id", RequireAuth(), AdminOnly(userSvc), categoryHandler.UpdateCategory)
+	categories.Delete("/:id", RequireAuth(), AdminOnly(userSvc), categoryHandler.DeleteCategory)
+
+	// Drafts: autosaved posts, private to their author. Saving also accepts a recently expired
+	// token so an autosave that races the token expiry is not lost
+	drafts := app.Group("/drafts")
+	drafts.Get("/", RequireAuth(), draftHandler.ListDrafts)                                              // GET /drafts
+	drafts.Put("/:key", RequireAuthGrace(DraftAutosaveGrace), RateLimiterAuth(), draftHandler.SaveDraft) // PUT /drafts/:key
+	drafts.Delete("/:key", RequireAuth(), draftHandler.DeleteDraft)                                      // DELETE /drafts/:key
+
+	// Notifications for the authenticated user
+	notifications := app.Group("/notifications", RequireAuth())
+	notifications.Get("/", notificationHandler.ListNotifications)       // GET /notifications?limit=&cursor=&unread=
+	notifications.Post("/read", notificationHandler.MarkRead)           // POST /notifications/read
+	notifications.Get("/unread-count", notificationHandler.UnreadCount) // GET /notifications/unread-count
+
+	// Bookmarks on threads and replies, optionally filed into folders
+	bookmarks := app.Group("/bookmarks", RequireAuth())
+	bookmarks.Post("/", bookmarkHandler.AddBookmark)      // POST /bookmarks
+	bookmarks.Delete("/", bookmarkHandler.RemoveBookmark) // DELETE /bookmarks
+
+	// Attachments: uploads stay private to their owner until a thread or reply links them
+	attachments := app.Group("/attachments")
+	attachments.Post("/", RequireAuth(), RateLimiterAuth(), attachmentHandler.Upload) // POST /attachments
+	attachments.Get("/:id", attachmentHandler.Download)                               // GET /attachments/:id
+
+	// Full-text search over threads and replies
+	app.Get("/search", searchHandler.Search) // GET /search?q=&type=&tags=&author=&cursor=
+
+	// Edit history: visible to the post owner and admins, revert is admin-only
+	threads.Get("/:id/revisions", RequireAuth(), OwnerOrAdmin(userSvc, threadSvc), revisionHandler.ListRevisions(entities.RevisionKindThread))
+	threads.Get("/:id/revisions/diff", RequireAuth(), OwnerOrAdmin(userSvc, threadSvc), revisionHandler.Diff(entities.RevisionKindThread))
+	threads.Post("/:id/revisions/:rev/revert", RequireAuth(), AdminOnly(userSvc), revisionHandler.Revert(entities.RevisionKindThread))
+	replies.Get("/:id/revisions", RequireAuth(), OwnerOrAdminReply(userSvc, replyHandler.svc), revisionHandler.ListRevisions(entities.RevisionKindReply))
+	replies.Get("/:id/revisions/diff", RequireAuth(), OwnerOrAdminReply(userSvc, replyHandler.svc), revisionHandler.Diff(entities.RevisionKindReply))
+	replies.Post("/:id/revisions/:rev/revert", RequireAuth(), AdminOnly(userSvc), revisionHandler.Revert(entities.RevisionKindReply))
+
 	// Reports
 	app.Post("/reports", reportHandler.CreateReport)
 	app.Get("/reports", RequireAuth(), AdminOnly(userSvc), reportHandler.GetReports)
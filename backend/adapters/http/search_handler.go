@@ -0,0 +1,35 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+type SearchHandler struct {
+	svc usecases.SearchService
+}
+
+func NewSearchHandler(svc usecases.SearchService) *SearchHandler {
+	return &SearchHandler{svc: svc}
+}
+
+// Search serves GET /search?q=&type=thread|reply|all&tags=a,b&author=&limit=&cursor=
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	q := entities.SearchQuery{
+		Text:   c.Query("q"),
+		Kind:   entities.SearchKind(c.Query("type")),
+		Author: c.Query("author"),
+		Limit:  c.QueryInt("limit", usecases.DefaultSearchPageSize),
+	}
+	if tags := c.Query("tags"); tags != "" {
+		q.Tags = strings.Split(tags, ",")
+	}
+	page, err := h.svc.Search(c.UserContext(), q, c.Query("cursor"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(page)
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// SubscriptionHandler serves thread watch endpoints for the authenticated user.
+type SubscriptionHandler struct {
+	svc usecases.SubscriptionService
+}
+
+func NewSubscriptionHandler(svc usecases.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{svc: svc}
+}
+
+type watchReq struct {
+	// Level is all (default), replies-to-me or muted
+	Level string `json:"level"`
+}
+
+// Watch handles POST /threads/:id/watch; posting again changes the level.
+func (h *SubscriptionHandler) Watch(c *fiber.Ctx) error {
+	threadID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req watchReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+		}
+	}
+	userID, _ := c.Locals("user_id").(int)
+	if err := h.svc.Watch(c.UserContext(), userID, threadID, entities.WatchLevel(req.Level)); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Unwatch handles DELETE /threads/:id/watch.
+func (h *SubscriptionHandler) Unwatch(c *fiber.Ctx) error {
+	threadID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	userID, _ := c.Locals("user_id").(int)
+	if err := h.svc.Unwatch(c.UserContext(), userID, threadID); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListWatching handles GET /users/me/watching?limit=&cursor=.
+func (h *SubscriptionHandler) ListWatching(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(int)
+	page, err := h.svc.ListWatching(c.UserContext(), userID, c.QueryInt("limit", usecases.DefaultWatchingPageSize), c.Query("cursor"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(page)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+)
+
+// TagHandler serves tag browsing and admin endpoints. Tag thread listings reuse ThreadService.
+type TagHandler struct {
+	svc       usecases.TagService
+	threadSvc usecases.ThreadService
+}
+
+func NewTagHandler(svc usecases.TagService, threadSvc usecases.ThreadService) *TagHandler {
+	return &TagHandler{svc: svc, threadSvc: threadSvc}
+}
+
+// ListTags serves GET /tags?limit= with usage counts, most used first.
+func (h *TagHandler) ListTags(c *fiber.Ctx) error {
+	tags, err := h.svc.ListTags(c.UserContext(), c.QueryInt("limit", usecases.DefaultTagListSize))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"tags": tags})
+}
+
+// GetTagThreads serves GET /tags/:name/threads with the same sort/limit/cursor params as GET /threads.
+// Aliases resolve to their canonical tag.
+func (h *TagHandler) GetTagThreads(c *fiber.Ctx) error {
+	tag, err := h.svc.GetTag(c.UserContext(), c.Params("name"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	opts, bad := threadListOptions(c)
+	if bad != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": bad})
+	}
+	opts.Tag = tag.Name
+	page, err := h.threadSvc.ListThreads(c.UserContext(), opts, c.Query("cursor"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"tag": tag, "threads": page.Threads, "next_cursor": page.NextCursor})
+}
+
+type renameTagReq struct {
+	Name string `json:"name"`
+}
+
+// RenameTag serves PUT /tags/:name (admin).
+func (h *TagHandler) RenameTag(c *fiber.Ctx) error {
+	var req renameTagReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	tag, err := h.svc.RenameTag(c.UserContext(), c.Params("name"), req.Name)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(tag)
+}
+
+type mergeTagReq struct {
+	Into string `json:"into"`
+}
+
+// MergeTag serves POST /tags/:name/merge (admin), folding :name into the body's "into" tag.
+func (h *TagHandler) MergeTag(c *fiber.Ctx) error {
+	var req mergeTagReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	tag, err := h.svc.MergeTags(c.UserContext(), c.Params("name"), req.Into)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(tag)
+}
+
+type aliasTagReq struct {
+	Alias string `json:"alias"`
+}
+
+// AddAlias serves POST /tags/:name/aliases (admin).
+func (h *TagHandler) AddAlias(c *fiber.Ctx) error {
+	var req aliasTagReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	tag, err := h.svc.AddAlias(c.UserContext(), c.Params("name"), req.Alias)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(tag)
+}
+
+// RemoveAlias serves DELETE /tags/aliases/:alias (admin).
+func (h *TagHandler) RemoveAlias(c *fiber.Ctx) error {
+	if err := h.svc.RemoveAlias(c.UserContext(), c.Params("alias")); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteTag serves DELETE /tags/:name (admin); threads keep existing, only the tag links go.
+func (h *TagHandler) DeleteTag(c *fiber.Ctx) error {
+	if err := h.svc.DeleteTag(c.UserContext(), c.Params("name")); err != nil {
+		return respondError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
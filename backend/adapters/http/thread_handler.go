@@ -15,20 +15,32 @@ import (
 )
 
 // ThreadHandler depends on the ThreadService port (usecase) and optional Redis client for caching.
+// views, when set, counts unique viewers of published threads.
 type ThreadHandler struct {
 	svc   usecases.ThreadService
 	cache *redis.Client
+	views usecases.ViewService
 }
 
-func NewThreadHandler(svc usecases.ThreadService, cache *redis.Client) *ThreadHandler {
-	return &ThreadHandler{svc: svc, cache: cache}
+func NewThreadHandler(svc usecases.ThreadService, cache *redis.Client, views usecases.ViewService) *ThreadHandler {
+	return &ThreadHandler{svc: svc, cache: cache, views: views}
 }
 
+// threadCacheTimeout bounds the cache lookup so a slow Redis cannot hold up thread reads.
+const threadCacheTimeout = 100 * time.Millisecond
+
 type createThreadReq struct {
-	UserID int      `json:"user_id"`
-	Title  string   `json:"title"`
-	Body   string   `json:"body"`
-	Tags   []string `json:"tags,omitempty"`
+	UserID int `json:"user_id"`
+	// CategoryID is required; see GET /categories
+	CategoryID int      `json:"category_id"`
+	Title      string   `json:"title"`
+	Body       string   `json:"body"`
+	Tags       []string `json:"tags,omitempty"`
+	// Status is draft, scheduled or published (the default); publish_at schedules the thread
+	Status    string     `json:"status,omitempty"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// AttachmentIDs link uploads from POST /attachments to the thread
+	AttachmentIDs []int `json:"attachment_ids,omitempty"`
 }
 
 func (h *ThreadHandler) CreateThread(c *fiber.Ctx) error {
@@ -44,14 +56,23 @@ func (h *ThreadHandler) CreateThread(c *fiber.Ctx) error {
 		}
 	}
 	thread := &entities.Thread{
-		UserID: userID,
-		Title:  req.Title,
-		Body:   req.Body,
-		Tags:   req.Tags,
+		UserID:        userID,
+		CategoryID:    req.CategoryID,
+		Title:         req.Title,
+		Body:          req.Body,
+		Tags:          req.Tags,
+		Status:        entities.ThreadStatus(req.Status),
+		PublishAt:     req.PublishAt,
+		AttachmentIDs: req.AttachmentIDs,
+	}
+	if req.Status != "" {
+		if _, ok := entities.ParseThreadStatus(req.Status); !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid status"})
+		}
 	}
 	id, err := h.svc.CreateThread(c.UserContext(), thread)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		return respondError(c, err)
 	}
 	// invalidate cache for this thread id if present
 	if h.cache != nil {
@@ -71,10 +92,15 @@ func (h *ThreadHandler) GetThreadByID(c *fiber.Ctx) error {
 	// try cache-aside: look up Redis first
 	if h.cache != nil {
 		key := fmt.Sprintf("thread:%d", id)
-		ctx := context.Background()
-		if data, err := h.cache.Get(ctx, key).Result(); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), threadCacheTimeout)
+		data, err := h.cache.Get(ctx, key).Result()
+		cancel()
+		if err == nil {
 			var t entities.Thread
-			if jerr := json.Unmarshal([]byte(data), &t); jerr == nil {
+			// only published threads are cached; anything else is re-checked against the caller
+			if jerr := json.Unmarshal([]byte(data), &t); jerr == nil && !t.Unpublished() {
+				h.recordView(c, id)
+				h.svc.ApplyViewerState(c.UserContext(), &t)
 				return c.JSON(t)
 			}
 			// if unmarshal fails, fallthrough to DB fetch
@@ -84,8 +110,8 @@ func (h *ThreadHandler) GetThreadByID(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
 	}
-	// cache the result (best-effort)
-	if h.cache != nil {
+	// cache the result (best-effort); unpublished threads are per-viewer and never cached
+	if h.cache != nil && !thread.Unpublished() {
 		key := fmt.Sprintf("thread:%d", id)
 		ctx := context.Background()
 		if b, jerr := json.Marshal(thread); jerr == nil {
@@ -93,23 +119,99 @@ func (h *ThreadHandler) GetThreadByID(c *fiber.Ctx) error {
 			h.cache.Set(ctx, key, b, 60*time.Second)
 		}
 	}
+	if !thread.Unpublished() {
+		h.recordView(c, id)
+	}
+	// per-user state is added after caching so it never leaks into the shared copy
+	h.svc.ApplyViewerState(c.UserContext(), thread)
 	return c.JSON(thread)
 }
 
-func (h *ThreadHandler) GetAllThreads(c *fiber.Ctx) error {
-	threads, err := h.svc.GetAllThreads(c.UserContext())
+// recordView counts the caller as a viewer of a published thread. Signed-in users are
+// deduplicated by ID and anonymous readers by IP. It runs in the background so an
+// unreachable Redis never slows the read down.
+func (h *ThreadHandler) recordView(c *fiber.Ctx, threadID int) {
+	if h.views == nil {
+		return
+	}
+	visitor := "ip:" + c.IP()
+	if uid := usecases.ViewerID(c.UserContext()); uid != 0 {
+		visitor = "u:" + strconv.Itoa(uid)
+	}
+	go h.views.RecordView(context.Background(), threadID, visitor)
+}
+
+// ListThreads serves GET /threads?sort=&limit=&cursor=&tag=&category=&solved=&author= as a keyset-paginated page.
+// category is a slug and includes its subcategories; solved is true or false; author is a user ID.
+func (h *ThreadHandler) ListThreads(c *fiber.Ctx) error {
+	opts, bad := threadListOptions(c)
+	if bad != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": bad})
+	}
+	opts.Tag, opts.Category = c.Query("tag"), c.Query("category")
+	page, err := h.svc.ListThreads(c.UserContext(), opts, c.Query("cursor"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		return respondError(c, err)
+	}
+	return c.JSON(page)
+}
+
+// GetRelatedThreads serves GET /threads/:id/related?limit=, threads sharing tags with or
+// titled like the thread.
+func (h *ThreadHandler) GetRelatedThreads(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
 	}
-	return c.JSON(threads)
+	threads, err := h.svc.GetRelatedThreads(c.UserContext(), id, c.QueryInt("limit", usecases.DefaultRelatedThreads))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"threads": threads})
+}
+
+// ListTrending serves GET /threads/trending?limit=&cursor=, threads ranked by a score that
+// decays with age; the ranking is refreshed every few minutes.
+func (h *ThreadHandler) ListTrending(c *fiber.Ctx) error {
+	page, err := h.svc.ListTrending(c.UserContext(), c.QueryInt("limit", usecases.DefaultThreadPageSize), c.Query("cursor"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(page)
+}
+
+// threadListOptions reads the sort, limit, author and solved query parameters shared by the thread
+// list endpoints. It returns the message of a 400 response when one of them is invalid.
+func threadListOptions(c *fiber.Ctx) (entities.ThreadListOptions, string) {
+	opts := entities.ThreadListOptions{Limit: c.QueryInt("limit", usecases.DefaultThreadPageSize)}
+	var ok bool
+	if opts.Sort, ok = entities.ParseThreadSort(c.Query("sort")); !ok {
+		return opts, "invalid sort"
+	}
+	if s := c.Query("author"); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil || id <= 0 {
+			return opts, "invalid author"
+		}
+		opts.AuthorID = id
+	}
+	// an empty solved parameter means no filter
+	if s := c.Query("solved"); s != "" {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return opts, "invalid solved filter"
+		}
+		opts.Solved = &v
+	}
+	return opts, ""
 }
 
 type updateThreadReq struct {
-	Title     string   `json:"title"`
-	Body      string   `json:"body"`
-	Tags      []string `json:"tags,omitempty"`
-	IsLocked  bool     `json:"is_locked"`
-	IsDeleted bool     `json:"is_deleted"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Tags  []string `json:"tags,omitempty"`
+	// EditReason is an optional note stored with the revision this edit creates
+	EditReason string `json:"edit_reason,omitempty"`
 }
 
 func (h *ThreadHandler) UpdateThread(c *fiber.Ctx) error {
@@ -123,15 +225,16 @@ func (h *ThreadHandler) UpdateThread(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
 	}
 	thread := &entities.Thread{
-		ID:        id,
-		Title:     req.Title,
-		Body:      req.Body,
-		Tags:      req.Tags,
-		IsLocked:  req.IsLocked,
-		IsDeleted: req.IsDeleted,
+		ID:    id,
+		Title: req.Title,
+		Body:  req.Body,
+		Tags:  req.Tags,
 	}
-	if err := h.svc.UpdateThread(c.UserContext(), thread); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	// OwnerOrAdmin has already run, so user_id is present
+	editorID, _ := c.Locals("user_id").(int)
+	edit := entities.EditInfo{EditorID: editorID, Reason: req.EditReason}
+	if err := h.svc.UpdateThread(c.UserContext(), thread, edit); err != nil {
+		return respondError(c, err)
 	}
 	// invalidate cache
 	if h.cache != nil {
@@ -159,4 +262,222 @@ func (h *ThreadHandler) DeleteThread(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+type lockThreadReq struct {
+	Reason string `json:"reason"`
+}
+
+// LockThread handles POST /threads/:id/lock (admin only).
+func (h *ThreadHandler) LockThread(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req lockThreadReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+		}
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	if err := h.svc.LockThread(c.UserContext(), id, actorID, req.Reason); err != nil {
+		return respondError(c, err)
+	}
+	if h.cache != nil {
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnlockThread handles POST /threads/:id/unlock (admin only).
+func (h *ThreadHandler) UnlockThread(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	if err := h.svc.UnlockThread(c.UserContext(), id, actorID); err != nil {
+		return respondError(c, err)
+	}
+	if h.cache != nil {
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type pinThreadReq struct {
+	// Scope is "global" (default) or "tag"; Tag is required for tag pins
+	Scope     string     `json:"scope"`
+	Tag       string     `json:"tag"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// PinThread handles POST /threads/:id/pin (admin only).
+func (h *ThreadHandler) PinThread(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req pinThreadReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+		}
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	pin := entities.ThreadPin{Scope: entities.PinScope(req.Scope), Tag: req.Tag, ExpiresAt: req.ExpiresAt}
+	if err := h.svc.PinThread(c.UserContext(), id, actorID, pin); err != nil {
+		return respondError(c, err)
+	}
+	if h.cache != nil {
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnpinThread handles POST /threads/:id/unpin (admin only).
+func (h *ThreadHandler) UnpinThread(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	if err := h.svc.UnpinThread(c.UserContext(), id); err != nil {
+		return respondError(c, err)
+	}
+	if h.cache != nil {
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type acceptAnswerReq struct {
+	ReplyID int `json:"reply_id"`
+}
+
+// AcceptAnswer handles POST /threads/:id/accept; it also changes an existing answer.
+func (h *ThreadHandler) AcceptAnswer(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req acceptAnswerReq
+	if err := c.BodyParser(&req); err != nil || req.ReplyID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reply_id is required"})
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	if err := h.svc.AcceptAnswer(c.UserContext(), id, req.ReplyID, actorID); err != nil {
+		return respondError(c, err)
+	}
+	if h.cache != nil {
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnacceptAnswer handles DELETE /threads/:id/accept.
+func (h *ThreadHandler) UnacceptAnswer(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	if err := h.svc.UnacceptAnswer(c.UserContext(), id, actorID); err != nil {
+		return respondError(c, err)
+	}
+	if h.cache != nil {
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type mergeThreadReq struct {
+	// Into is the thread that receives the replies and votes
+	Into int `json:"into"`
+}
+
+// MergeThread handles POST /threads/:id/merge (admin only). The thread is left as a locked
+// stub whose merged_into points at the target.
+func (h *ThreadHandler) MergeThread(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req mergeThreadReq
+	if err := c.BodyParser(&req); err != nil || req.Into <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "into is required"})
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	if err := h.svc.MergeThread(c.UserContext(), id, req.Into, actorID); err != nil {
+		return respondError(c, err)
+	}
+	h.invalidateThreads(id, req.Into)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type splitThreadReq struct {
+	// ReplyID is the root of the subtree that moves
+	ReplyID int    `json:"reply_id"`
+	Title   string `json:"title"`
+	Body    string `json:"body,omitempty"`
+}
+
+// SplitThread handles POST /threads/:id/split (admin only) and returns the new thread's id.
+func (h *ThreadHandler) SplitThread(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req splitThreadReq
+	if err := c.BodyParser(&req); err != nil || req.ReplyID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reply_id is required"})
+	}
+	actorID, _ := c.Locals("user_id").(int)
+	newID, err := h.svc.SplitThread(c.UserContext(), entities.ThreadSplit{ThreadID: id, ReplyID: req.ReplyID, ActorID: actorID, Title: req.Title, Body: req.Body})
+	if err != nil {
+		return respondError(c, err)
+	}
+	h.invalidateThreads(id, newID)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": newID})
+}
+
+// invalidateThreads drops the cached copies of every thread touched by a moderator operation.
+func (h *ThreadHandler) invalidateThreads(ids ...int) {
+	if h.cache == nil {
+		return
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fmt.Sprintf("thread:%d", id)
+	}
+	h.cache.Del(context.Background(), keys...)
+}
+
+type publishingReq struct {
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// SetPublishing handles PUT /threads/:id/publishing for threads that are not yet published.
+func (h *ThreadHandler) SetPublishing(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	var req publishingReq
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+	}
+	if req.Status != "" {
+		if _, ok := entities.ParseThreadStatus(req.Status); !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid status"})
+		}
+	}
+	if err := h.svc.SetPublishing(c.UserContext(), id, entities.ThreadStatus(req.Status), req.PublishAt); err != nil {
+		return respondError(c, err)
+	}
+	if h.cache != nil {
+		h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", id))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 // ...existing code...
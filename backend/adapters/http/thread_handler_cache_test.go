@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
+	redisadapters "github.com/nocson47/beaconofknowledge/adapters/redis"
 	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
 	"github.com/stretchr/testify/require"
 )
 
@@ -26,11 +30,48 @@ func (f *fakeThreadService) GetThreadByID(ctx context.Context, id int) (*entitie
 	f.called++
 	return f.thread, nil
 }
-func (f *fakeThreadService) GetAllThreads(ctx context.Context) ([]*entities.Thread, error) {
+func (f *fakeThreadService) ListThreads(ctx context.Context, opts entities.ThreadListOptions, cursor string) (*entities.ThreadPage, error) {
+	return &entities.ThreadPage{}, nil
+}
+func (f *fakeThreadService) UpdateThread(ctx context.Context, t *entities.Thread, edit entities.EditInfo) error {
+	return nil
+}
+func (f *fakeThreadService) DeleteThread(ctx context.Context, id int) error { return nil }
+func (f *fakeThreadService) LockThread(ctx context.Context, id int, actorID int, reason string) error {
+	return nil
+}
+func (f *fakeThreadService) UnlockThread(ctx context.Context, id int, actorID int) error { return nil }
+func (f *fakeThreadService) PinThread(ctx context.Context, id int, actorID int, pin entities.ThreadPin) error {
+	return nil
+}
+func (f *fakeThreadService) ApplyViewerState(ctx context.Context, threads ...*entities.Thread) {}
+func (f *fakeThreadService) UnpinThread(ctx context.Context, id int) error                     { return nil }
+func (f *fakeThreadService) AcceptAnswer(ctx context.Context, threadID, replyID, actorID int) error {
+	return nil
+}
+func (f *fakeThreadService) UnacceptAnswer(ctx context.Context, threadID, actorID int) error {
+	return nil
+}
+func (f *fakeThreadService) SetPublishing(ctx context.Context, id int, status entities.ThreadStatus, publishAt *time.Time) error {
+	return nil
+}
+func (f *fakeThreadService) PublishDueThreads(ctx context.Context) ([]int, error) { return nil, nil }
+func (f *fakeThreadService) ClearExpiredPins(ctx context.Context) ([]int, error) {
 	return nil, nil
 }
-func (f *fakeThreadService) UpdateThread(ctx context.Context, t *entities.Thread) error { return nil }
-func (f *fakeThreadService) DeleteThread(ctx context.Context, id int) error             { return nil }
+func (f *fakeThreadService) ListTrending(ctx context.Context, limit int, cursor string) (*entities.ThreadPage, error) {
+	return &entities.ThreadPage{}, nil
+}
+func (f *fakeThreadService) GetRelatedThreads(ctx context.Context, id int, limit int) ([]*entities.Thread, error) {
+	return nil, nil
+}
+func (f *fakeThreadService) MergeThread(ctx context.Context, sourceID, targetID, actorID int) error {
+	return nil
+}
+func (f *fakeThreadService) SplitThread(ctx context.Context, split entities.ThreadSplit) (int, error) {
+	return 0, nil
+}
+func (f *fakeThreadService) RefreshTrending(ctx context.Context) (int, error) { return 0, nil }
 
 func TestGetThreadByID_CacheAside(t *testing.T) {
 	// start miniredis
@@ -44,7 +85,7 @@ func TestGetThreadByID_CacheAside(t *testing.T) {
 	th := &entities.Thread{ID: 1, UserID: 1, Title: "Hi", Body: "Body"}
 	fake := &fakeThreadService{thread: th}
 
-	handler := NewThreadHandler(fake, rdb)
+	handler := NewThreadHandler(fake, rdb, nil)
 
 	app := fiber.New()
 	app.Get("/threads/:id", handler.GetThreadByID)
@@ -73,3 +114,48 @@ func TestGetThreadByID_CacheAside(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, th.Title, cached.Title)
 }
+
+func TestGetThreadByID_CountsUniqueViewsAndSurvivesRedisOutage(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	counter := redisadapters.NewViewCounter(rdb)
+
+	fake := &fakeThreadService{thread: &entities.Thread{ID: 1, UserID: 1, Title: "Hi", Body: "Body"}}
+	handler := NewThreadHandler(fake, rdb, usecases.NewViewService(counter, nil))
+	app := fiber.New()
+	app.Get("/threads/:id", func(c *fiber.Ctx) error {
+		if uid := c.Get("X-User"); uid != "" {
+			id, _ := strconv.Atoi(uid)
+			c.SetUserContext(usecases.WithViewer(c.UserContext(), id))
+		}
+		return handler.GetThreadByID(c)
+	})
+
+	// a miss, then cache hits: the same user twice and one anonymous reader
+	for _, user := range []string{"7", "7", ""} {
+		req := httptest.NewRequest("GET", "/threads/1", nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+	}
+	require.Equal(t, 1, fake.called)
+	require.Eventually(t, func() bool {
+		counts, err := counter.Counts(context.Background(), []int{1})
+		return err == nil && counts[1] == 2
+	}, time.Second, 10*time.Millisecond)
+	dirty, err := counter.PopDirty(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, dirty)
+
+	// with Redis gone the read falls through to the service
+	mr.Close()
+	resp, err := app.Test(httptest.NewRequest("GET", "/threads/1", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, 2, fake.called)
+}
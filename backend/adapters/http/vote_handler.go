@@ -83,7 +83,7 @@ func (h *VoteHandler) CreateVote(c *fiber.Ctx) error {
 
 	id, err := h.svc.CreateVote(c.UserContext(), vote)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		return respondError(c, err)
 	}
 
 	// invalidate thread cache if vote affected a thread
@@ -93,10 +93,16 @@ func (h *VoteHandler) CreateVote(c *fiber.Ctx) error {
 		h.cache.Del(ctx, key)
 	}
 
-	// If vote is for a thread, return updated counts
+	// return the updated counts of the voted post
 	resp := fiber.Map{"id": id, "value": vote.ValueString()}
-	if vote.ThreadID != nil {
-		up, down, gerr := h.svc.GetVoteCountsForThread(c.UserContext(), *vote.ThreadID)
+	if vote.ThreadID != nil || vote.ReplyID != nil {
+		var up, down int
+		var gerr error
+		if vote.ThreadID != nil {
+			up, down, gerr = h.svc.GetVoteCountsForThread(c.UserContext(), *vote.ThreadID)
+		} else {
+			up, down, gerr = h.svc.GetVoteCountsForReply(c.UserContext(), *vote.ReplyID)
+		}
 		if gerr != nil {
 			// non-fatal for client; still return created id
 			resp["warning"] = fmt.Sprintf("failed to fetch counts: %v", gerr)
@@ -121,3 +127,60 @@ func (h *VoteHandler) GetThreadCounts(c *fiber.Ctx) error {
 	}
 	return c.JSON(fiber.Map{"upvotes": up, "downvotes": down})
 }
+
+// GetReplyCounts serves GET /replies/:id/votes.
+func (h *VoteHandler) GetReplyCounts(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid id"})
+	}
+	up, down, err := h.svc.GetVoteCountsForReply(c.UserContext(), id)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(fiber.Map{"upvotes": up, "downvotes": down})
+}
+
+type retractVoteReq struct {
+	ThreadID *int `json:"thread_id,omitempty"`
+	ReplyID  *int `json:"reply_id,omitempty"`
+}
+
+// RetractVote serves DELETE /votes, removing the caller's vote on a thread or reply. The target
+// is read from the JSON body or, for clients that cannot send a DELETE body, from ?thread_id= or
+// ?reply_id=. The response carries the post's updated counts.
+func (h *VoteHandler) RetractVote(c *fiber.Ctx) error {
+	var req retractVoteReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payload"})
+		}
+	}
+	if req.ThreadID == nil && req.ReplyID == nil {
+		if id := c.QueryInt("thread_id"); id != 0 {
+			req.ThreadID = &id
+		} else if id := c.QueryInt("reply_id"); id != 0 {
+			req.ReplyID = &id
+		}
+	}
+	userID, _ := c.Locals("user_id").(int)
+	vote := &entities.Vote{UserID: userID, ThreadID: req.ThreadID, ReplyID: req.ReplyID}
+	if err := h.svc.RetractVote(c.UserContext(), vote); err != nil {
+		return respondError(c, err)
+	}
+
+	var up, down int
+	var err error
+	if vote.ThreadID != nil {
+		if h.cache != nil {
+			h.cache.Del(context.Background(), fmt.Sprintf("thread:%d", *vote.ThreadID))
+		}
+		up, down, err = h.svc.GetVoteCountsForThread(c.UserContext(), *vote.ThreadID)
+	} else {
+		up, down, err = h.svc.GetVoteCountsForReply(c.UserContext(), *vote.ReplyID)
+	}
+	if err != nil {
+		return c.JSON(fiber.Map{"warning": fmt.Sprintf("failed to fetch counts: %v", err)})
+	}
+	return c.JSON(fiber.Map{"upvotes": up, "downvotes": down})
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVoteService implements usecases.VoteService with counts for a fixed set of replies
+type fakeVoteService struct {
+	replyCounts map[int][2]int
+}
+
+func (f *fakeVoteService) CreateVote(ctx context.Context, v *entities.Vote) (int, error) {
+	return 0, nil
+}
+func (f *fakeVoteService) GetVoteByID(ctx context.Context, id int) (*entities.Vote, error) {
+	return nil, nil
+}
+func (f *fakeVoteService) GetVoteCountsForThread(ctx context.Context, threadID int) (int, int, error) {
+	return 0, 0, nil
+}
+func (f *fakeVoteService) GetVoteCountsForReply(ctx context.Context, replyID int) (int, int, error) {
+	c, ok := f.replyCounts[replyID]
+	if !ok {
+		return 0, 0, fmt.Errorf("reply not found: %w", usecases.ErrNotFound)
+	}
+	return c[0], c[1], nil
+}
+func (f *fakeVoteService) RetractVote(ctx context.Context, v *entities.Vote) error { return nil }
+
+func TestGetReplyCounts_NotFound(t *testing.T) {
+	h := NewVoteHandler(&fakeVoteService{replyCounts: map[int][2]int{1: {3, 1}}}, nil)
+	app := fiber.New()
+	app.Get("/replies/:id/votes", h.GetReplyCounts)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/replies/1/votes", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/replies/2/votes", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
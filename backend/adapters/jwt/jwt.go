@@ -29,12 +29,17 @@ func GenerateToken(userID int) (string, error) {
 
 // Parse and validate a JWT token, return user_id if valid
 func ParseToken(tokenString string) (int, error) {
+	return ParseTokenWithLeeway(tokenString, 0)
+}
+
+// ParseTokenWithLeeway is ParseToken but also accepts tokens that expired less than leeway ago.
+func ParseTokenWithLeeway(tokenString string, leeway time.Duration) (int, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
 		}
 		return JwtSecret(), nil
-	})
+	}, jwt.WithLeeway(leeway))
 	if err != nil {
 		return 0, err
 	}
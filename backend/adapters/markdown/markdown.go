@@ -0,0 +1,52 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// Goldmark parses CommonMark with the GFM extensions (tables, strikethrough, autolinks,
+// task lists). Raw HTML in the source is dropped rather than passed through.
+type Goldmark struct {
+	md goldmark.Markdown
+}
+
+func NewGoldmark() *Goldmark {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+	return &Goldmark{md: md}
+}
+
+func (g *Goldmark) ToHTML(src string) (string, error) {
+	var buf bytes.Buffer
+	if err := g.md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Sanitizer applies the post allowlist: bluemonday's user-generated-content policy plus
+// fenced-code language classes and the disabled checkboxes GFM task lists produce.
+type Sanitizer struct {
+	policy *bluemonday.Policy
+}
+
+func NewSanitizer() *Sanitizer {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return &Sanitizer{policy: p}
+}
+
+func (s *Sanitizer) Sanitize(html string) string {
+	return s.policy.Sanitize(html)
+}
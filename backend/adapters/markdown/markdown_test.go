@@ -0,0 +1,30 @@
+package markdown
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/usecases"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, src string) string {
+	t.Helper()
+	p := usecases.NewContentPipeline(NewGoldmark(), NewSanitizer(), nil)
+	return p.Render(context.Background(), src)
+}
+
+func TestRender_GFMTablesAndCodeFences(t *testing.T) {
+	out := render(t, "| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nfmt.Println(1)\n```\n")
+	require.Contains(t, out, "<table>")
+	require.Contains(t, out, "<td>1</td>")
+	require.Contains(t, out, `<code class="language-go">`)
+}
+
+func TestRender_StripsUnsafeHTML(t *testing.T) {
+	out := render(t, "hi <script>alert(1)</script> [x](javascript:alert(1)) <img src=x onerror=alert(1)>")
+	require.NotContains(t, strings.ToLower(out), "<script")
+	require.NotContains(t, out, "javascript:")
+	require.NotContains(t, out, "onerror")
+}
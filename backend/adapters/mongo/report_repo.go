@@ -57,35 +57,55 @@ func (m *MongoReportRepo) GetReports(ctx context.Context, kind *string) ([]*enti
 		if err := cur.Decode(&doc); err != nil {
 			return nil, err
 		}
-		rep := &entities.Report{}
-		if idv, ok := doc["_id"].(primitive.ObjectID); ok {
-			rep.ID = idv.Hex()
-		}
-		if v, ok := doc["reporter_id"].(int32); ok {
-			vi := int(v)
-			rep.ReporterID = &vi
-		}
-		if v, ok := doc["kind"].(string); ok {
-			rep.Kind = v
-		}
-		if v, ok := doc["target_id"].(int32); ok {
-			rep.TargetID = int(v)
-		}
-		if v, ok := doc["reason"].(string); ok {
-			rep.Reason = v
-		}
-		if v, ok := doc["status"].(string); ok {
-			rep.Status = v
-		}
-		if v, ok := doc["created_at"].(primitive.DateTime); ok {
-			t := v.Time()
-			rep.CreatedAt = t
-		}
-		out = append(out, rep)
+		out = append(out, decodeReport(doc))
 	}
 	return out, nil
 }
 
+func (m *MongoReportRepo) GetReportByID(ctx context.Context, id string) (*entities.Report, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+	var doc bson.M
+	if err := m.col.FindOne(ctx, bson.M{"_id": oid}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mongo find one: %w", err)
+	}
+	return decodeReport(doc), nil
+}
+
+// decodeReport maps a stored report document onto the entity.
+func decodeReport(doc bson.M) *entities.Report {
+	rep := &entities.Report{}
+	if idv, ok := doc["_id"].(primitive.ObjectID); ok {
+		rep.ID = idv.Hex()
+	}
+	if v, ok := doc["reporter_id"].(int32); ok {
+		vi := int(v)
+		rep.ReporterID = &vi
+	}
+	if v, ok := doc["kind"].(string); ok {
+		rep.Kind = v
+	}
+	if v, ok := doc["target_id"].(int32); ok {
+		rep.TargetID = int(v)
+	}
+	if v, ok := doc["reason"].(string); ok {
+		rep.Reason = v
+	}
+	if v, ok := doc["status"].(string); ok {
+		rep.Status = v
+	}
+	if v, ok := doc["created_at"].(primitive.DateTime); ok {
+		t := v.Time()
+		rep.CreatedAt = t
+	}
+	return rep
+}
+
 func (m *MongoReportRepo) UpdateReportStatus(ctx context.Context, id string, status string, resolvedBy *int) error {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
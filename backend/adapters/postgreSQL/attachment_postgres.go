@@ -0,0 +1,180 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type AttachmentPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewAttachmentPostgres(db *pgxpool.Pool) repositories.AttachmentRepository {
+	return &AttachmentPostgres{db: db}
+}
+
+const attachmentSelect = `SELECT id, user_id, thread_id, reply_id, filename, content_type, size, storage_key, created_at FROM attachments`
+
+func scanAttachment(row pgx.Row) (*entities.Attachment, error) {
+	var a entities.Attachment
+	if err := row.Scan(&a.ID, &a.UserID, &a.ThreadID, &a.ReplyID, &a.Filename, &a.ContentType, &a.Size, &a.StorageKey, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *AttachmentPostgres) queryAttachments(ctx context.Context, query string, args ...interface{}) ([]*entities.Attachment, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*entities.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *AttachmentPostgres) CreateAttachment(ctx context.Context, a *entities.Attachment, quota int64) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// serialize uploads per user so concurrent ones cannot both slip under the quota
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('attachments'), $1)`, a.UserID); err != nil {
+		return false, fmt.Errorf("lock quota: %w", err)
+	}
+	var used int64
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = $1`, a.UserID).Scan(&used); err != nil {
+		return false, fmt.Errorf("sum uploads: %w", err)
+	}
+	if used+a.Size > quota {
+		return false, nil
+	}
+	err = tx.QueryRow(ctx, `
+	INSERT INTO attachments (user_id, filename, content_type, size, storage_key, created_at)
+	VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+	RETURNING id, created_at`, a.UserID, a.Filename, a.ContentType, a.Size, a.StorageKey).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("insert attachment: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	return true, nil
+}
+
+func (r *AttachmentPostgres) GetAttachment(ctx context.Context, id int) (*entities.Attachment, error) {
+	a, err := scanAttachment(r.db.QueryRow(ctx, attachmentSelect+` WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get attachment: %w", err)
+	}
+	return a, nil
+}
+
+func (r *AttachmentPostgres) GetAttachments(ctx context.Context, ids []int) ([]*entities.Attachment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	out, err := r.queryAttachments(ctx, attachmentSelect+` WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get attachments: %w", err)
+	}
+	return out, nil
+}
+
+// linkAttachments attaches userID's unlinked uploads among ids to a new post inside tx. Unless
+// every one of them is linked it fails with repositories.ErrAttachmentsUnavailable, so the
+// caller's transaction rolls back the post too.
+func linkAttachments(ctx context.Context, tx pgx.Tx, userID int, ids []int, threadID, replyID *int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	tag, err := tx.Exec(ctx, `
+	UPDATE attachments SET thread_id = $3, reply_id = $4
+	WHERE id = ANY($1) AND user_id = $2 AND thread_id IS NULL AND reply_id IS NULL`, ids, userID, threadID, replyID)
+	if err != nil {
+		return fmt.Errorf("link attachments: %w", err)
+	}
+	if int(tag.RowsAffected()) != len(ids) {
+		return repositories.ErrAttachmentsUnavailable
+	}
+	return nil
+}
+
+func (r *AttachmentPostgres) GetThreadAttachments(ctx context.Context, threadIDs []int) (map[int][]entities.Attachment, error) {
+	return r.postAttachments(ctx, "thread_id", threadIDs)
+}
+
+func (r *AttachmentPostgres) GetReplyAttachments(ctx context.Context, replyIDs []int) (map[int][]entities.Attachment, error) {
+	return r.postAttachments(ctx, "reply_id", replyIDs)
+}
+
+// postAttachments groups the attachments of posts in upload order; column is thread_id or reply_id.
+func (r *AttachmentPostgres) postAttachments(ctx context.Context, column string, ids []int) (map[int][]entities.Attachment, error) {
+	out := map[int][]entities.Attachment{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	list, err := r.queryAttachments(ctx, fmt.Sprintf(attachmentSelect+` WHERE %[1]s = ANY($1) ORDER BY %[1]s, id`, column), ids)
+	if err != nil {
+		return nil, fmt.Errorf("get post attachments: %w", err)
+	}
+	for _, a := range list {
+		postID := a.ThreadID
+		if column == "reply_id" {
+			postID = a.ReplyID
+		}
+		out[*postID] = append(out[*postID], *a)
+	}
+	return out, nil
+}
+
+func (r *AttachmentPostgres) ListUnlinkedAttachments(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Attachment, error) {
+	out, err := r.queryAttachments(ctx, attachmentSelect+`
+	WHERE thread_id IS NULL AND reply_id IS NULL AND created_at < $1
+	ORDER BY id
+	LIMIT $2`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list unlinked attachments: %w", err)
+	}
+	return out, nil
+}
+
+func (r *AttachmentPostgres) DeleteUnlinkedAttachments(ctx context.Context, ids []int) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := r.db.Query(ctx, `
+	DELETE FROM attachments WHERE id = ANY($1) AND thread_id IS NULL AND reply_id IS NULL
+	RETURNING storage_key`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("delete attachments: %w", err)
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,179 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type BookmarkPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewBookmarkPostgres(db *pgxpool.Pool) repositories.BookmarkRepository {
+	return &BookmarkPostgres{db: db}
+}
+
+func (r *BookmarkPostgres) SaveBookmark(ctx context.Context, b *entities.Bookmark) error {
+	// each target has its own partial unique index, so the conflict target names the one in use
+	target := `(user_id, thread_id) WHERE thread_id IS NOT NULL`
+	if b.ReplyID != nil {
+		target = `(user_id, reply_id) WHERE reply_id IS NOT NULL`
+	}
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO bookmarks (user_id, thread_id, reply_id, folder, created_at)
+	VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+	ON CONFLICT `+target+` DO UPDATE SET folder = EXCLUDED.folder
+	RETURNING id, created_at`, b.UserID, b.ThreadID, b.ReplyID, b.Folder).Scan(&b.ID, &b.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save bookmark: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkPostgres) DeleteBookmark(ctx context.Context, userID int, threadID, replyID *int) (bool, error) {
+	query := `DELETE FROM bookmarks WHERE user_id = $1 AND thread_id = $2`
+	arg := threadID
+	if replyID != nil {
+		query, arg = `DELETE FROM bookmarks WHERE user_id = $1 AND reply_id = $2`, replyID
+	}
+	tag, err := r.db.Exec(ctx, query, userID, *arg)
+	if err != nil {
+		return false, fmt.Errorf("delete bookmark: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *BookmarkPostgres) ListBookmarks(ctx context.Context, userID int, folder *string, beforeID, limit int) ([]*entities.Bookmark, error) {
+	conds := []string{
+		"b.user_id = $1",
+		"t.is_deleted = false",
+		"(t.status = 'published' OR t.user_id = $1)",
+		"(b.reply_id IS NULL OR r.is_deleted = false)",
+	}
+	args := []interface{}{userID, limit}
+	if folder != nil {
+		args = append(args, *folder)
+		conds = append(conds, fmt.Sprintf("b.folder = $%d", len(args)))
+	}
+	if beforeID > 0 {
+		args = append(args, beforeID)
+		conds = append(conds, fmt.Sprintf("b.id < $%d", len(args)))
+	}
+	rows, err := r.db.Query(ctx, `
+	SELECT b.id, b.user_id, b.thread_id, b.reply_id, b.folder, b.created_at, t.id
+	FROM bookmarks b
+	LEFT JOIN replies r ON r.id = b.reply_id
+	JOIN threads t ON t.id = COALESCE(b.thread_id, r.thread_id)
+	WHERE `+strings.Join(conds, " AND ")+`
+	ORDER BY b.id DESC
+	LIMIT $2`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list bookmarks: %w", err)
+	}
+	out := []*entities.Bookmark{}
+	// threadIDs[i] is the thread of out[i], the bookmarked one or the reply's
+	var threadIDs, replyIDs []int
+	for rows.Next() {
+		var b entities.Bookmark
+		var threadID int
+		if err := rows.Scan(&b.ID, &b.UserID, &b.ThreadID, &b.ReplyID, &b.Folder, &b.CreatedAt, &threadID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan bookmark: %w", err)
+		}
+		out = append(out, &b)
+		threadIDs = append(threadIDs, threadID)
+		if b.ReplyID != nil {
+			replyIDs = append(replyIDs, *b.ReplyID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	threads, err := r.threadsByID(ctx, threadIDs)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := r.repliesByID(ctx, replyIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, b := range out {
+		b.Thread = threads[threadIDs[i]]
+		if b.ReplyID != nil {
+			b.Reply = replies[*b.ReplyID]
+		}
+	}
+	return out, nil
+}
+
+func (r *BookmarkPostgres) threadsByID(ctx context.Context, ids []int) (map[int]*entities.Thread, error) {
+	rows, err := r.db.Query(ctx, fmt.Sprintf(threadSelect, fmt.Sprintf("LEFT(t.body, %d)", listBodyPreview))+`
+	WHERE t.id = ANY($1)`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("load bookmarked threads: %w", err)
+	}
+	defer rows.Close()
+	out := map[int]*entities.Thread{}
+	for rows.Next() {
+		t, err := scanThread(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan thread: %w", err)
+		}
+		out[t.ID] = t
+	}
+	return out, rows.Err()
+}
+
+func (r *BookmarkPostgres) repliesByID(ctx context.Context, ids []int) (map[int]*entities.Reply, error) {
+	out := map[int]*entities.Reply{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`SELECT r.id, r.thread_id, r.user_id, COALESCE(u.username, ''), r.parent_id, LEFT(r.body, %d), r.is_deleted,
+		r.upvotes, r.downvotes, COALESCE(th.accepted_reply_id = r.id, false), r.created_at, r.updated_at
+	FROM replies r LEFT JOIN users u ON u.id = r.user_id LEFT JOIN threads th ON th.id = r.thread_id
+	WHERE r.id = ANY($1)`, listBodyPreview), ids)
+	if err != nil {
+		return nil, fmt.Errorf("load bookmarked replies: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rep entities.Reply
+		if err := rows.Scan(&rep.ID, &rep.ThreadID, &rep.UserID, &rep.Author, &rep.ParentID, &rep.Body, &rep.IsDeleted,
+			&rep.Upvotes, &rep.Downvotes, &rep.IsAccepted, &rep.CreatedAt, &rep.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan reply: %w", err)
+		}
+		out[rep.ID] = &rep
+	}
+	return out, rows.Err()
+}
+
+func (r *BookmarkPostgres) GetBookmarkedThreads(ctx context.Context, userID int, threadIDs []int) (map[int]bool, error) {
+	out := map[int]bool{}
+	if len(threadIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, `SELECT thread_id FROM bookmarks WHERE user_id = $1 AND thread_id = ANY($2)`, userID, threadIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get bookmarked threads: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan bookmark: %w", err)
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,115 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type CategoryPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewCategoryPostgres(db *pgxpool.Pool) repositories.CategoryRepository {
+	return &CategoryPostgres{db: db}
+}
+
+// categorySelect projects a category with its direct thread count (published, non-deleted threads only).
+const categorySelect = `
+	SELECT c.id, c.parent_id, c.slug, c.name, COALESCE(c.description, ''), c.position,
+		c.read_only, COALESCE(c.min_post_role, ''), c.default_tags,
+		(SELECT COUNT(*) FROM threads t WHERE t.category_id = c.id AND t.is_deleted = false AND t.status = 'published') AS thread_count,
+		c.created_at, c.updated_at
+	FROM categories c`
+
+func scanCategory(row pgx.Row) (*entities.Category, error) {
+	var c entities.Category
+	if err := row.Scan(&c.ID, &c.ParentID, &c.Slug, &c.Name, &c.Description, &c.Position,
+		&c.Settings.ReadOnly, &c.Settings.MinPostRole, &c.Settings.DefaultTags,
+		&c.ThreadCount, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CategoryPostgres) ListCategories(ctx context.Context) ([]*entities.Category, error) {
+	rows, err := r.db.Query(ctx, categorySelect+` ORDER BY c.position ASC, c.name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list categories: %w", err)
+	}
+	defer rows.Close()
+	var cats []*entities.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan category: %w", err)
+		}
+		cats = append(cats, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return cats, nil
+}
+
+func (r *CategoryPostgres) getCategory(ctx context.Context, where string, arg interface{}) (*entities.Category, error) {
+	c, err := scanCategory(r.db.QueryRow(ctx, categorySelect+` WHERE `+where, arg))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get category: %w", err)
+	}
+	return c, nil
+}
+
+func (r *CategoryPostgres) GetCategoryByID(ctx context.Context, id int) (*entities.Category, error) {
+	return r.getCategory(ctx, "c.id = $1", id)
+}
+
+func (r *CategoryPostgres) GetCategoryBySlug(ctx context.Context, slug string) (*entities.Category, error) {
+	return r.getCategory(ctx, "c.slug = $1", slug)
+}
+
+func (r *CategoryPostgres) CreateCategory(ctx context.Context, c *entities.Category) (int, error) {
+	query := `INSERT INTO categories (parent_id, slug, name, description, position, read_only, min_post_role, default_tags, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, NULLIF($7, ''), $8, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`
+	var id int
+	err := r.db.QueryRow(ctx, query, c.ParentID, c.Slug, c.Name, c.Description, c.Position,
+		c.Settings.ReadOnly, c.Settings.MinPostRole, defaultTagsArg(c.Settings.DefaultTags)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert category: %w", err)
+	}
+	return id, nil
+}
+
+func (r *CategoryPostgres) UpdateCategory(ctx context.Context, c *entities.Category) error {
+	query := `UPDATE categories SET parent_id = $2, slug = $3, name = $4, description = NULLIF($5, ''), position = $6,
+		read_only = $7, min_post_role = NULLIF($8, ''), default_tags = $9, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, c.ID, c.ParentID, c.Slug, c.Name, c.Description, c.Position,
+		c.Settings.ReadOnly, c.Settings.MinPostRole, defaultTagsArg(c.Settings.DefaultTags))
+	if err != nil {
+		return fmt.Errorf("update category: %w", err)
+	}
+	return nil
+}
+
+func (r *CategoryPostgres) DeleteCategory(ctx context.Context, id int) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM categories WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+	return nil
+}
+
+// defaultTagsArg keeps the NOT NULL default_tags column an empty array rather than NULL.
+func defaultTagsArg(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
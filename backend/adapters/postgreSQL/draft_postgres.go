@@ -0,0 +1,76 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type DraftPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewDraftPostgres(db *pgxpool.Pool) repositories.DraftRepository {
+	return &DraftPostgres{db: db}
+}
+
+func (r *DraftPostgres) SaveDraft(ctx context.Context, d *entities.Draft) error {
+	tags := d.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO drafts (user_id, key, title, body, tags, updated_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, $6)
+	ON CONFLICT (user_id, key) DO UPDATE SET
+		title = EXCLUDED.title, body = EXCLUDED.body, tags = EXCLUDED.tags,
+		updated_at = EXCLUDED.updated_at, expires_at = EXCLUDED.expires_at
+	RETURNING updated_at`, d.UserID, d.Key, d.Title, d.Body, tags, d.ExpiresAt).Scan(&d.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("save draft: %w", err)
+	}
+	return nil
+}
+
+func (r *DraftPostgres) ListDrafts(ctx context.Context, userID int) ([]*entities.Draft, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT user_id, key, title, body, tags, updated_at, expires_at
+	FROM drafts
+	WHERE user_id = $1 AND expires_at > CURRENT_TIMESTAMP
+	ORDER BY updated_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list drafts: %w", err)
+	}
+	defer rows.Close()
+	drafts := []*entities.Draft{}
+	for rows.Next() {
+		var d entities.Draft
+		if err := rows.Scan(&d.UserID, &d.Key, &d.Title, &d.Body, &d.Tags, &d.UpdatedAt, &d.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scan draft: %w", err)
+		}
+		drafts = append(drafts, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+func (r *DraftPostgres) DeleteDraft(ctx context.Context, userID int, key string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM drafts WHERE user_id = $1 AND key = $2`, userID, key)
+	if err != nil {
+		return false, fmt.Errorf("delete draft: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *DraftPostgres) DeleteExpiredDrafts(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM drafts WHERE expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired drafts: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,115 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type MentionPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewMentionPostgres(db *pgxpool.Pool) repositories.MentionRepository {
+	return &MentionPostgres{db: db}
+}
+
+// postCond selects the rows of one post: a thread's own body has no reply_id.
+func postCond(replyID *int) (string, []interface{}) {
+	if replyID == nil {
+		return `thread_id = $1 AND reply_id IS NULL`, nil
+	}
+	return `thread_id = $1 AND reply_id = $2`, []interface{}{*replyID}
+}
+
+func (r *MentionPostgres) SetMentions(ctx context.Context, threadID int, replyID *int, mentions []entities.Mention) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cond, extra := postCond(replyID)
+	args := append([]interface{}{threadID}, extra...)
+	if _, err := tx.Exec(ctx, `DELETE FROM post_mentions WHERE `+cond, args...); err != nil {
+		return fmt.Errorf("clear mentions: %w", err)
+	}
+	for _, m := range mentions {
+		if _, err := tx.Exec(ctx, `INSERT INTO post_mentions (thread_id, reply_id, user_id, username, start_offset, end_offset)
+		VALUES ($1, $2, $3, $4, $5, $6)`, threadID, replyID, m.UserID, m.Username, m.Start, m.End); err != nil {
+			return fmt.Errorf("insert mention: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit mentions: %w", err)
+	}
+	return nil
+}
+
+func (r *MentionPostgres) MarkMentionsNotified(ctx context.Context, threadID int, replyID *int, userIDs []int) ([]int, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	// a reply is identified by reply_id alone so the record follows it through merges and splits
+	query := `INSERT INTO post_mention_notices (thread_id, user_id) SELECT $1, unnest($2::int[])
+	ON CONFLICT DO NOTHING RETURNING user_id`
+	args := []interface{}{threadID, userIDs}
+	if replyID != nil {
+		query = `INSERT INTO post_mention_notices (reply_id, user_id) SELECT $1, unnest($2::int[])
+		ON CONFLICT DO NOTHING RETURNING user_id`
+		args[0] = *replyID
+	}
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("mark mentions notified: %w", err)
+	}
+	defer rows.Close()
+	var fresh []int
+	for rows.Next() {
+		var uid int
+		if err := rows.Scan(&uid); err != nil {
+			return nil, fmt.Errorf("scan notified user: %w", err)
+		}
+		fresh = append(fresh, uid)
+	}
+	return fresh, rows.Err()
+}
+
+func (r *MentionPostgres) GetThreadMentions(ctx context.Context, threadIDs []int) (map[int][]entities.Mention, error) {
+	return r.getMentions(ctx, `SELECT thread_id, user_id, username, start_offset, end_offset
+	FROM post_mentions WHERE thread_id = ANY($1) AND reply_id IS NULL
+	ORDER BY thread_id, start_offset`, threadIDs)
+}
+
+func (r *MentionPostgres) GetReplyMentions(ctx context.Context, replyIDs []int) (map[int][]entities.Mention, error) {
+	return r.getMentions(ctx, `SELECT reply_id, user_id, username, start_offset, end_offset
+	FROM post_mentions WHERE reply_id = ANY($1)
+	ORDER BY reply_id, start_offset`, replyIDs)
+}
+
+func (r *MentionPostgres) getMentions(ctx context.Context, query string, ids []int) (map[int][]entities.Mention, error) {
+	out := map[int][]entities.Mention{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get mentions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID int
+		var m entities.Mention
+		if err := rows.Scan(&postID, &m.UserID, &m.Username, &m.Start, &m.End); err != nil {
+			return nil, fmt.Errorf("scan mention: %w", err)
+		}
+		out[postID] = append(out[postID], m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
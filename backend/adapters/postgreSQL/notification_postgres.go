@@ -0,0 +1,123 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type NotificationPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationPostgres(db *pgxpool.Pool) repositories.NotificationRepository {
+	return &NotificationPostgres{db: db}
+}
+
+func (r *NotificationPostgres) CreateNotifications(ctx context.Context, ns []*entities.Notification) error {
+	if len(ns) == 0 {
+		return nil
+	}
+	values := make([]string, len(ns))
+	args := make([]interface{}, 0, len(ns)*8)
+	for i, n := range ns {
+		b := i * 8
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, NULLIF($%d, ''), NULLIF($%d, ''), NULLIF($%d, ''), CURRENT_TIMESTAMP)", b+1, b+2, b+3, b+4, b+5, b+6, b+7, b+8)
+		args = append(args, n.UserID, string(n.Kind), n.ActorID, n.ThreadID, n.ReplyID, n.ReportID, n.Message, n.DedupeKey)
+	}
+	rows, err := r.db.Query(ctx, `INSERT INTO notifications (user_id, kind, actor_id, thread_id, reply_id, report_id, message, dedupe_key, created_at)
+	VALUES `+strings.Join(values, ", ")+`
+	ON CONFLICT DO NOTHING
+	RETURNING id, created_at, user_id, COALESCE(dedupe_key, '')`, args...)
+	if err != nil {
+		return fmt.Errorf("insert notifications: %w", err)
+	}
+	defer rows.Close()
+	// RETURNING yields rows in VALUES order for a single multi-row INSERT; only rows with a
+	// dedupe key can be skipped, so walk past those that do not match the returned row
+	i := 0
+	for rows.Next() {
+		var id, userID int
+		var createdAt time.Time
+		var key string
+		if err := rows.Scan(&id, &createdAt, &userID, &key); err != nil {
+			return fmt.Errorf("scan notification: %w", err)
+		}
+		for i < len(ns) && (ns[i].UserID != userID || ns[i].DedupeKey != key) {
+			i++
+		}
+		if i < len(ns) {
+			ns[i].ID, ns[i].CreatedAt = id, createdAt
+			i++
+		}
+	}
+	return rows.Err()
+}
+
+func (r *NotificationPostgres) ListNotifications(ctx context.Context, userID, beforeID, limit int, unreadOnly bool) ([]*entities.Notification, error) {
+	conds := []string{"n.user_id = $1"}
+	args := []interface{}{userID, limit}
+	if beforeID > 0 {
+		args = append(args, beforeID)
+		conds = append(conds, fmt.Sprintf("n.id < $%d", len(args)))
+	}
+	if unreadOnly {
+		conds = append(conds, "n.read_at IS NULL")
+	}
+	rows, err := r.db.Query(ctx, `
+	SELECT n.id, n.user_id, n.kind, n.actor_id, COALESCE(u.username, ''), n.thread_id, COALESCE(t.title, ''),
+		n.reply_id, COALESCE(n.report_id, ''), COALESCE(n.message, ''), n.read_at IS NOT NULL, n.created_at
+	FROM notifications n
+	LEFT JOIN users u ON u.id = n.actor_id
+	LEFT JOIN threads t ON t.id = n.thread_id
+	WHERE `+strings.Join(conds, " AND ")+`
+	ORDER BY n.id DESC
+	LIMIT $2`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+	out := []*entities.Notification{}
+	for rows.Next() {
+		var n entities.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.ActorID, &n.Actor, &n.ThreadID, &n.ThreadTitle,
+			&n.ReplyID, &n.ReportID, &n.Message, &n.Read, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		out = append(out, &n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *NotificationPostgres) MarkRead(ctx context.Context, userID int, ids []int) (int64, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE notifications SET read_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND id = ANY($2) AND read_at IS NULL`, userID, ids)
+	if err != nil {
+		return 0, fmt.Errorf("mark notifications read: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *NotificationPostgres) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE notifications SET read_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("mark all notifications read: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *NotificationPostgres) CountUnread(ctx context.Context, userID int) (int, error) {
+	var n int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,156 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type PollPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewPollPostgres(db *pgxpool.Pool) repositories.PollRepository {
+	return &PollPostgres{db: db}
+}
+
+func (r *PollPostgres) CreatePoll(ctx context.Context, p *entities.Poll) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `INSERT INTO polls (thread_id, question, multiple_choice, results_before_vote, closes_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+		ON CONFLICT (thread_id) DO NOTHING
+		RETURNING id, created_at`,
+		p.ThreadID, p.Question, p.MultipleChoice, p.ResultsBeforeVote, p.ClosesAt, p.CreatedBy).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("insert poll: %w", err)
+	}
+	for i := range p.Options {
+		p.Options[i].Position = i
+		if err := tx.QueryRow(ctx, `INSERT INTO poll_options (poll_id, position, text) VALUES ($1, $2, $3) RETURNING id`,
+			p.ID, i, p.Options[i].Text).Scan(&p.Options[i].ID); err != nil {
+			return 0, fmt.Errorf("insert poll option: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return p.ID, nil
+}
+
+func (r *PollPostgres) GetPollByThread(ctx context.Context, threadID int) (*entities.Poll, error) {
+	var p entities.Poll
+	err := r.db.QueryRow(ctx, `
+	SELECT p.id, p.thread_id, p.question, p.multiple_choice, p.results_before_vote, p.closes_at, p.closed_at,
+		(SELECT COUNT(*) FROM poll_ballots b WHERE b.poll_id = p.id) AS voters,
+		p.created_by, p.created_at
+	FROM polls p WHERE p.thread_id = $1`, threadID).Scan(&p.ID, &p.ThreadID, &p.Question, &p.MultipleChoice,
+		&p.ResultsBeforeVote, &p.ClosesAt, &p.ClosedAt, &p.Voters, &p.CreatedBy, &p.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get poll: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+	SELECT o.id, o.position, o.text, COUNT(c.user_id)
+	FROM poll_options o
+	LEFT JOIN poll_ballot_choices c ON c.option_id = o.id
+	WHERE o.poll_id = $1
+	GROUP BY o.id
+	ORDER BY o.position ASC`, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get poll options: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o entities.PollOption
+		if err := rows.Scan(&o.ID, &o.Position, &o.Text, &o.Votes); err != nil {
+			return nil, fmt.Errorf("scan poll option: %w", err)
+		}
+		p.Options = append(p.Options, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PollPostgres) GetBallot(ctx context.Context, pollID, userID int) ([]int, error) {
+	rows, err := r.db.Query(ctx, `SELECT option_id FROM poll_ballot_choices WHERE poll_id = $1 AND user_id = $2 ORDER BY option_id`, pollID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get ballot: %w", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan ballot: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *PollPostgres) CastBallot(ctx context.Context, pollID, userID int, optionIDs []int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// lock the poll and its thread so a concurrent close or lock cannot slip in between
+	// the check and the write
+	var open, multiple bool
+	err = tx.QueryRow(ctx, `
+	SELECT p.closed_at IS NULL AND (p.closes_at IS NULL OR p.closes_at > CURRENT_TIMESTAMP)
+		AND NOT th.is_locked AND NOT th.is_deleted, p.multiple_choice
+	FROM polls p JOIN threads th ON th.id = p.thread_id
+	WHERE p.id = $1
+	FOR UPDATE OF p FOR SHARE OF th`, pollID).Scan(&open, &multiple)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("check poll: %w", err)
+	}
+	if !open {
+		return false, nil
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO poll_ballots (poll_id, user_id, created_at, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (poll_id, user_id) DO UPDATE SET updated_at = CURRENT_TIMESTAMP`, pollID, userID); err != nil {
+		return false, fmt.Errorf("upsert ballot: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM poll_ballot_choices WHERE poll_id = $1 AND user_id = $2`, pollID, userID); err != nil {
+		return false, fmt.Errorf("clear ballot: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO poll_ballot_choices (poll_id, user_id, option_id, single_choice)
+		SELECT $1, $2, o, $4 FROM unnest($3::int[]) AS o`, pollID, userID, optionIDs, !multiple); err != nil {
+		return false, fmt.Errorf("insert ballot choices: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	return true, nil
+}
+
+func (r *PollPostgres) ClosePoll(ctx context.Context, pollID int) error {
+	if _, err := r.db.Exec(ctx, `UPDATE polls SET closed_at = CURRENT_TIMESTAMP WHERE id = $1 AND closed_at IS NULL`, pollID); err != nil {
+		return fmt.Errorf("close poll: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type ReactionPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewReactionPostgres(db *pgxpool.Pool) repositories.ReactionRepository {
+	return &ReactionPostgres{db: db}
+}
+
+func (r *ReactionPostgres) AddReaction(ctx context.Context, re *entities.Reaction) (bool, error) {
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO reactions (user_id, thread_id, reply_id, emoji, created_at)
+	VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+	ON CONFLICT DO NOTHING
+	RETURNING created_at`, re.UserID, re.ThreadID, re.ReplyID, re.Emoji).Scan(&re.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("add reaction: %w", err)
+	}
+	return true, nil
+}
+
+func (r *ReactionPostgres) RemoveReaction(ctx context.Context, userID int, threadID, replyID *int, emoji string) (bool, error) {
+	query := `DELETE FROM reactions WHERE user_id = $1 AND thread_id = $2 AND emoji = $3`
+	target := threadID
+	if replyID != nil {
+		query, target = `DELETE FROM reactions WHERE user_id = $1 AND reply_id = $2 AND emoji = $3`, replyID
+	}
+	tag, err := r.db.Exec(ctx, query, userID, *target, emoji)
+	if err != nil {
+		return false, fmt.Errorf("remove reaction: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *ReactionPostgres) GetThreadReactions(ctx context.Context, viewerID int, threadIDs []int) (map[int][]entities.ReactionCount, error) {
+	return r.countReactions(ctx, "thread_id", viewerID, threadIDs)
+}
+
+func (r *ReactionPostgres) GetReplyReactions(ctx context.Context, viewerID int, replyIDs []int) (map[int][]entities.ReactionCount, error) {
+	return r.countReactions(ctx, "reply_id", viewerID, replyIDs)
+}
+
+// countReactions aggregates reactions per post; column is thread_id or reply_id.
+func (r *ReactionPostgres) countReactions(ctx context.Context, column string, viewerID int, ids []int) (map[int][]entities.ReactionCount, error) {
+	out := map[int][]entities.ReactionCount{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+	SELECT %[1]s, emoji, COUNT(*), COALESCE(BOOL_OR(user_id = $2), false)
+	FROM reactions
+	WHERE %[1]s = ANY($1)
+	GROUP BY %[1]s, emoji
+	ORDER BY %[1]s, COUNT(*) DESC, MIN(created_at)`, column), ids, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("count reactions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID int
+		var rc entities.ReactionCount
+		if err := rows.Scan(&postID, &rc.Emoji, &rc.Count, &rc.Reacted); err != nil {
+			return nil, fmt.Errorf("scan reaction count: %w", err)
+		}
+		out[postID] = append(out[postID], rc)
+	}
+	return out, rows.Err()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/nocson47/beaconofknowledge/internal/entities"
 	"github.com/nocson47/beaconofknowledge/internal/repositories"
@@ -18,19 +19,36 @@ func NewReplyPostgres(db *pgxpool.Pool) repositories.ReplyRepository {
 }
 
 func (r *ReplyPostgres) CreateReply(ctx context.Context, rep *entities.Reply) (int, error) {
+	// insert the reply and bump the thread's denormalized reply counters together
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `INSERT INTO replies (thread_id, user_id, parent_id, body, is_deleted, created_at) VALUES ($1,$2,$3,$4,false,NOW()) RETURNING id`
 	var id int
-	err := r.db.QueryRow(ctx, query, rep.ThreadID, rep.UserID, rep.ParentID, rep.Body).Scan(&id)
+	err = tx.QueryRow(ctx, query, rep.ThreadID, rep.UserID, rep.ParentID, rep.Body).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("create reply: %w", err)
 	}
+	_, err = tx.Exec(ctx, `UPDATE threads SET reply_count = reply_count + 1, last_activity_at = NOW() WHERE id = $1`, rep.ThreadID)
+	if err != nil {
+		return 0, fmt.Errorf("update thread reply counters: %w", err)
+	}
+	if err := linkAttachments(ctx, tx, rep.UserID, rep.AttachmentIDs, nil, &id); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
 	return id, nil
 }
 
 func (r *ReplyPostgres) GetRepliesByThread(ctx context.Context, threadID int) ([]entities.Reply, error) {
 	// Join users to include username as author for read responses
 	// only return replies that are not soft-deleted
-	query := `SELECT r.id, r.thread_id, r.user_id, u.username AS author, r.parent_id, r.body, r.is_deleted, r.created_at, r.updated_at FROM replies r LEFT JOIN users u ON u.id = r.user_id WHERE r.thread_id = $1 AND r.is_deleted = false ORDER BY r.created_at ASC`
+	query := `SELECT r.id, r.thread_id, r.user_id, u.username AS author, r.parent_id, r.body, r.is_deleted, r.upvotes, r.downvotes, COALESCE(th.accepted_reply_id = r.id, false), r.created_at, r.updated_at FROM replies r LEFT JOIN users u ON u.id = r.user_id LEFT JOIN threads th ON th.id = r.thread_id WHERE r.thread_id = $1 AND r.is_deleted = false ORDER BY COALESCE(th.accepted_reply_id = r.id, false) DESC, r.created_at ASC`
 	rows, err := r.db.Query(ctx, query, threadID)
 	if err != nil {
 		return nil, fmt.Errorf("get replies: %w", err)
@@ -39,7 +57,7 @@ func (r *ReplyPostgres) GetRepliesByThread(ctx context.Context, threadID int) ([
 	var reps []entities.Reply
 	for rows.Next() {
 		var rep entities.Reply
-		if err := rows.Scan(&rep.ID, &rep.ThreadID, &rep.UserID, &rep.Author, &rep.ParentID, &rep.Body, &rep.IsDeleted, &rep.CreatedAt, &rep.UpdatedAt); err != nil {
+		if err := rows.Scan(&rep.ID, &rep.ThreadID, &rep.UserID, &rep.Author, &rep.ParentID, &rep.Body, &rep.IsDeleted, &rep.Upvotes, &rep.Downvotes, &rep.IsAccepted, &rep.CreatedAt, &rep.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan reply: %w", err)
 		}
 		reps = append(reps, rep)
@@ -48,27 +66,112 @@ func (r *ReplyPostgres) GetRepliesByThread(ctx context.Context, threadID int) ([
 }
 
 func (r *ReplyPostgres) DeleteReply(ctx context.Context, id int) error {
-	_, err := r.db.Exec(ctx, `UPDATE replies SET is_deleted = true, updated_at = NOW() WHERE id = $1`, id)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// only decrement the thread counter when this call actually soft-deletes the reply
+	var threadID int
+	err = tx.QueryRow(ctx, `UPDATE replies SET is_deleted = true, updated_at = NOW() WHERE id = $1 AND is_deleted = false RETURNING thread_id`, id).Scan(&threadID)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("delete reply: %w", err)
 	}
+	// a deleted reply can no longer be the accepted answer
+	_, err = tx.Exec(ctx, `UPDATE threads SET reply_count = GREATEST(reply_count - 1, 0),
+		accepted_reply_id = NULLIF(accepted_reply_id, $2) WHERE id = $1`, threadID, id)
+	if err != nil {
+		return fmt.Errorf("update thread reply counters: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
 	return nil
 }
 
-func (r *ReplyPostgres) UpdateReply(ctx context.Context, rep *entities.Reply) error {
-	_, err := r.db.Exec(ctx, `UPDATE replies SET body=$1, updated_at = NOW() WHERE id = $2`, rep.Body, rep.ID)
+func (r *ReplyPostgres) UpdateReply(ctx context.Context, rep *entities.Reply, edit entities.EditInfo) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM replies WHERE id = $1 FOR UPDATE`, rep.ID); err != nil {
+		return fmt.Errorf("lock reply: %w", err)
+	}
+	// keep the previous body as a revision when it changes
+	_, err = tx.Exec(ctx, `INSERT INTO post_revisions (reply_id, editor_id, reason, body, created_at)
+		SELECT id, $2, NULLIF($3, ''), body, NOW() FROM replies WHERE id = $1 AND body <> $4`, rep.ID, edit.EditorID, edit.Reason, rep.Body)
+	if err != nil {
+		return fmt.Errorf("record revision: %w", err)
+	}
+	_, err = tx.Exec(ctx, `UPDATE replies SET body=$1, updated_at = NOW() WHERE id = $2`, rep.Body, rep.ID)
 	if err != nil {
 		return fmt.Errorf("update reply: %w", err)
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
 	return nil
 }
 
 func (r *ReplyPostgres) GetReplyByID(ctx context.Context, id int) (*entities.Reply, error) {
 	var rep entities.Reply
 	// only return reply if not soft-deleted
-	row := r.db.QueryRow(ctx, `SELECT r.id, r.thread_id, r.user_id, u.username AS author, r.parent_id, r.body, r.is_deleted, r.created_at, r.updated_at FROM replies r LEFT JOIN users u ON u.id = r.user_id WHERE r.id = $1 AND r.is_deleted = false`, id)
-	if err := row.Scan(&rep.ID, &rep.ThreadID, &rep.UserID, &rep.Author, &rep.ParentID, &rep.Body, &rep.IsDeleted, &rep.CreatedAt, &rep.UpdatedAt); err != nil {
+	row := r.db.QueryRow(ctx, `SELECT r.id, r.thread_id, r.user_id, u.username AS author, r.parent_id, r.body, r.is_deleted, r.upvotes, r.downvotes, COALESCE(th.accepted_reply_id = r.id, false), r.created_at, r.updated_at FROM replies r LEFT JOIN users u ON u.id = r.user_id LEFT JOIN threads th ON th.id = r.thread_id WHERE r.id = $1 AND r.is_deleted = false`, id)
+	if err := row.Scan(&rep.ID, &rep.ThreadID, &rep.UserID, &rep.Author, &rep.ParentID, &rep.Body, &rep.IsDeleted, &rep.Upvotes, &rep.Downvotes, &rep.IsAccepted, &rep.CreatedAt, &rep.UpdatedAt); err != nil {
 		return nil, fmt.Errorf("get reply by id: %w", err)
 	}
 	return &rep, nil
 }
+
+// replyVisible keeps deleted replies in tree views only while they still have live replies
+// under them, so their subtree stays reachable.
+const replyVisible = `(%[1]s.is_deleted = false OR EXISTS (SELECT 1 FROM replies k WHERE k.parent_id = %[1]s.id AND k.is_deleted = false))`
+
+func (r *ReplyPostgres) ListReplyChildren(ctx context.Context, threadID int, parentIDs []int, after *entities.ReplyCursor, limit int) ([]*entities.ReplyNode, error) {
+	args := []interface{}{threadID, parentIDs, limit}
+	keyset := ""
+	if after != nil && after.At != nil {
+		args = append(args, *after.At, after.ID)
+		keyset = "AND (r.created_at, r.id) > ($4, $5)"
+	}
+	query := fmt.Sprintf(`
+	SELECT c.id, c.thread_id, c.user_id, c.author, c.parent_id, c.body, c.is_deleted, c.upvotes, c.downvotes, c.is_accepted, c.created_at, c.updated_at, c.child_count
+	FROM unnest($2::int[]) AS p(id)
+	CROSS JOIN LATERAL (
+		SELECT r.id, r.thread_id, r.user_id, COALESCE(u.username, '') AS author, r.parent_id,
+			CASE WHEN r.is_deleted THEN '' ELSE r.body END AS body, r.is_deleted, r.upvotes, r.downvotes,
+			COALESCE(th.accepted_reply_id = r.id, false) AS is_accepted, r.created_at, r.updated_at,
+			(SELECT COUNT(*) FROM replies ch WHERE ch.parent_id = r.id AND %[2]s) AS child_count
+		FROM replies r
+		LEFT JOIN users u ON u.id = r.user_id
+		LEFT JOIN threads th ON th.id = r.thread_id
+		WHERE r.thread_id = $1 AND r.parent_id IS NOT DISTINCT FROM NULLIF(p.id, 0) AND %[1]s %[3]s
+		ORDER BY r.created_at ASC, r.id ASC
+		LIMIT $3
+	) c
+	ORDER BY c.created_at ASC, c.id ASC`, fmt.Sprintf(replyVisible, "r"), fmt.Sprintf(replyVisible, "ch"), keyset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list reply children: %w", err)
+	}
+	defer rows.Close()
+	var nodes []*entities.ReplyNode
+	for rows.Next() {
+		var n entities.ReplyNode
+		if err := rows.Scan(&n.ID, &n.ThreadID, &n.UserID, &n.Author, &n.ParentID, &n.Body, &n.IsDeleted, &n.Upvotes, &n.Downvotes, &n.IsAccepted, &n.CreatedAt, &n.UpdatedAt, &n.ChildCount); err != nil {
+			return nil, fmt.Errorf("scan reply: %w", err)
+		}
+		nodes = append(nodes, &n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
@@ -60,6 +60,23 @@ func (r *ReportPostgres) GetReports(ctx context.Context, kind *string) ([]*entit
 	return out, nil
 }
 
+func (r *ReportPostgres) GetReportByID(ctx context.Context, id string) (*entities.Report, error) {
+	iid, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, nil
+	}
+	var rep entities.Report
+	err = r.db.QueryRow(ctx, `SELECT id, reporter_id, kind, target_id, COALESCE(reason, ''), status, created_at, resolved_by, resolved_at FROM reports WHERE id=$1`, iid).
+		Scan(&rep.ID, &rep.ReporterID, &rep.Kind, &rep.TargetID, &rep.Reason, &rep.Status, &rep.CreatedAt, &rep.ResolvedBy, &rep.ResolvedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get report: %w", err)
+	}
+	return &rep, nil
+}
+
 func (r *ReportPostgres) UpdateReportStatus(ctx context.Context, id string, status string, resolvedBy *int) error {
 	// convert id string to int
 	iid, err := strconv.Atoi(id)
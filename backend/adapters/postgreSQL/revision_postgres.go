@@ -0,0 +1,74 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type RevisionPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewRevisionPostgres(db *pgxpool.Pool) repositories.RevisionRepository {
+	return &RevisionPostgres{db: db}
+}
+
+const revisionSelect = `
+	SELECT pr.id, pr.thread_id, pr.reply_id, COALESCE(pr.editor_id, 0), COALESCE(u.username, ''), COALESCE(pr.reason, ''),
+		COALESCE(pr.title, ''), pr.body, COALESCE(pr.tags, '{}'), pr.created_at
+	FROM post_revisions pr
+	LEFT JOIN users u ON u.id = pr.editor_id`
+
+func scanRevision(row pgx.Row) (*entities.Revision, error) {
+	var rev entities.Revision
+	var threadID, replyID *int
+	if err := row.Scan(&rev.ID, &threadID, &replyID, &rev.EditorID, &rev.EditorName, &rev.Reason, &rev.Title, &rev.Body, &rev.Tags, &rev.CreatedAt); err != nil {
+		return nil, err
+	}
+	if replyID != nil {
+		rev.Kind, rev.TargetID = entities.RevisionKindReply, *replyID
+	} else if threadID != nil {
+		rev.Kind, rev.TargetID = entities.RevisionKindThread, *threadID
+	}
+	return &rev, nil
+}
+
+func (r *RevisionPostgres) ListRevisions(ctx context.Context, kind entities.RevisionKind, targetID int) ([]entities.Revision, error) {
+	column := "pr.thread_id"
+	if kind == entities.RevisionKindReply {
+		column = "pr.reply_id"
+	}
+	rows, err := r.db.Query(ctx, revisionSelect+` WHERE `+column+` = $1 ORDER BY pr.created_at DESC, pr.id DESC`, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	defer rows.Close()
+	var revs []entities.Revision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan revision: %w", err)
+		}
+		revs = append(revs, *rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+func (r *RevisionPostgres) GetRevision(ctx context.Context, id int) (*entities.Revision, error) {
+	rev, err := scanRevision(r.db.QueryRow(ctx, revisionSelect+` WHERE pr.id = $1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get revision: %w", err)
+	}
+	return rev, nil
+}
@@ -0,0 +1,126 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type SearchPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewSearchPostgres(db *pgxpool.Pool) repositories.SearchRepository {
+	return &SearchPostgres{db: db}
+}
+
+// ts_headline wraps matches in these control characters; they are swapped for <mark>
+// only after the snippet has been HTML-escaped, so post bodies can't inject markup.
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+// Search ranks threads (title weighted above body) and replies against a websearch-style
+// query using the generated search_vector columns. Headlines are computed only for the
+// returned page because ts_headline re-parses the whole document.
+func (r *SearchPostgres) Search(ctx context.Context, q entities.SearchQuery) ([]entities.SearchResult, error) {
+	args := []interface{}{q.Text}
+	var threadConds, replyConds []string
+	if q.Author != "" {
+		args = append(args, q.Author)
+		threadConds = append(threadConds, fmt.Sprintf("u.username = $%d", len(args)))
+		replyConds = append(replyConds, fmt.Sprintf("u.username = $%d", len(args)))
+	}
+	if len(q.Tags) > 0 {
+		// every requested tag must be on the thread (for replies: on the parent thread); aliases
+		// are resolved first, so two names for the same tag count once
+		args = append(args, q.Tags)
+		wanted := fmt.Sprintf("SELECT %s FROM unnest($%d::text[]) AS want(name)", fmt.Sprintf(canonicalTag, "want.name"), len(args))
+		tagCond := fmt.Sprintf(`(SELECT COUNT(DISTINCT tg.name) FROM thread_tags tt JOIN tags tg ON tg.id = tt.tag_id
+			WHERE tt.thread_id = t.id AND tg.name IN (%s)) = (SELECT COUNT(DISTINCT name) FROM (%s) AS wanted(name))`, wanted, wanted)
+		threadConds = append(threadConds, tagCond)
+		replyConds = append(replyConds, tagCond)
+	}
+
+	threadHits := `
+		SELECT 'thread' AS kind, t.id AS thread_id, NULL::int AS reply_id, t.user_id, COALESCE(u.username, '') AS author, t.title,
+			t.body, ts_rank(t.search_vector, q.query) AS rank, t.created_at
+		FROM threads t CROSS JOIN q
+		LEFT JOIN users u ON u.id = t.user_id
+		WHERE t.is_deleted = false AND t.status = 'published' AND t.search_vector @@ q.query` + andConds(threadConds)
+	replyHits := `
+		SELECT 'reply' AS kind, r.thread_id, r.id AS reply_id, r.user_id, COALESCE(u.username, '') AS author, t.title,
+			r.body, ts_rank(r.search_vector, q.query) AS rank, r.created_at
+		FROM replies r CROSS JOIN q
+		JOIN threads t ON t.id = r.thread_id AND t.is_deleted = false AND t.status = 'published'
+		LEFT JOIN users u ON u.id = r.user_id
+		WHERE r.is_deleted = false AND r.search_vector @@ q.query` + andConds(replyConds)
+
+	var hits string
+	switch q.Kind {
+	case entities.SearchKindThread:
+		hits = threadHits
+	case entities.SearchKindReply:
+		hits = replyHits
+	default:
+		hits = threadHits + "\n\t\tUNION ALL" + replyHits
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	query := `
+	WITH q AS (SELECT websearch_to_tsquery('english', $1) AS query),
+	page AS (
+		SELECT * FROM (` + hits + `
+		) hits
+		ORDER BY rank DESC, created_at DESC, thread_id DESC, reply_id DESC NULLS FIRST
+		LIMIT $` + fmt.Sprint(len(args)-1) + ` OFFSET $` + fmt.Sprint(len(args)) + `
+	)
+	SELECT page.kind, page.thread_id, page.reply_id, page.user_id, page.author, page.title,
+		ts_headline('english', page.body, q.query, 'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=30, MinWords=10'),
+		COALESCE((SELECT array_agg(tg.name ORDER BY tg.name) FROM thread_tags tt JOIN tags tg ON tg.id = tt.tag_id WHERE tt.thread_id = page.thread_id), '{}'),
+		page.rank, page.created_at
+	FROM page CROSS JOIN q
+	ORDER BY page.rank DESC, page.created_at DESC, page.thread_id DESC, page.reply_id DESC NULLS FIRST`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	defer rows.Close()
+
+	var out []entities.SearchResult
+	for rows.Next() {
+		var res entities.SearchResult
+		var kind, snippet string
+		if err := rows.Scan(&kind, &res.ThreadID, &res.ReplyID, &res.UserID, &res.Author, &res.Title, &snippet, &res.Tags, &res.Rank, &res.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		res.Kind = entities.SearchKind(kind)
+		res.Snippet = markHeadline(snippet)
+		out = append(out, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// markHeadline escapes a ts_headline fragment and turns the sentinel markers into <mark> tags.
+func markHeadline(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, headlineStart, "<mark>")
+	return strings.ReplaceAll(s, headlineStop, "</mark>")
+}
+
+func andConds(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " AND " + strings.Join(conds, " AND ")
+}
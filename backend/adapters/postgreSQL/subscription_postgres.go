@@ -0,0 +1,118 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type SubscriptionPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewSubscriptionPostgres(db *pgxpool.Pool) repositories.SubscriptionRepository {
+	return &SubscriptionPostgres{db: db}
+}
+
+func (r *SubscriptionPostgres) Watch(ctx context.Context, userID, threadID int, level entities.WatchLevel) error {
+	_, err := r.db.Exec(ctx, `
+	INSERT INTO thread_subscriptions (user_id, thread_id, level, created_at, updated_at)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	ON CONFLICT (user_id, thread_id) DO UPDATE SET level = EXCLUDED.level, updated_at = CURRENT_TIMESTAMP`,
+		userID, threadID, string(level))
+	if err != nil {
+		return fmt.Errorf("watch thread: %w", err)
+	}
+	return nil
+}
+
+func (r *SubscriptionPostgres) WatchIfAbsent(ctx context.Context, userID, threadID int, level entities.WatchLevel) error {
+	_, err := r.db.Exec(ctx, `
+	INSERT INTO thread_subscriptions (user_id, thread_id, level, created_at, updated_at)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	ON CONFLICT (user_id, thread_id) DO NOTHING`, userID, threadID, string(level))
+	if err != nil {
+		return fmt.Errorf("auto-watch thread: %w", err)
+	}
+	return nil
+}
+
+func (r *SubscriptionPostgres) Unwatch(ctx context.Context, userID, threadID int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM thread_subscriptions WHERE user_id = $1 AND thread_id = $2`, userID, threadID)
+	if err != nil {
+		return false, fmt.Errorf("unwatch thread: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *SubscriptionPostgres) GetSubscription(ctx context.Context, userID, threadID int) (*entities.ThreadSubscription, error) {
+	var s entities.ThreadSubscription
+	err := r.db.QueryRow(ctx, `SELECT user_id, thread_id, level, created_at, updated_at
+	FROM thread_subscriptions WHERE user_id = $1 AND thread_id = $2`, userID, threadID).
+		Scan(&s.UserID, &s.ThreadID, &s.Level, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get subscription: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *SubscriptionPostgres) ListWatching(ctx context.Context, userID int, after *entities.WatchCursor, limit int) ([]*entities.ThreadSubscription, error) {
+	args := []interface{}{userID, limit}
+	cond := ""
+	if after != nil {
+		args = append(args, after.At, after.ThreadID)
+		cond = ` AND (s.created_at, s.thread_id) < ($3, $4)`
+	}
+	rows, err := r.db.Query(ctx, `
+	SELECT s.user_id, s.thread_id, s.level, t.title, s.created_at, s.updated_at
+	FROM thread_subscriptions s
+	JOIN threads t ON t.id = s.thread_id AND t.is_deleted = false
+	WHERE s.user_id = $1`+cond+`
+	ORDER BY s.created_at DESC, s.thread_id DESC
+	LIMIT $2`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list watching: %w", err)
+	}
+	return scanSubscriptions(rows, true)
+}
+
+func (r *SubscriptionPostgres) ListWatchers(ctx context.Context, threadID int) ([]*entities.ThreadSubscription, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT user_id, thread_id, level, created_at, updated_at
+	FROM thread_subscriptions
+	WHERE thread_id = $1 AND level <> 'muted'
+	ORDER BY user_id`, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchers: %w", err)
+	}
+	return scanSubscriptions(rows, false)
+}
+
+// scanSubscriptions reads subscription rows; withTitle expects a thread title after the level.
+func scanSubscriptions(rows pgx.Rows, withTitle bool) ([]*entities.ThreadSubscription, error) {
+	defer rows.Close()
+	subs := []*entities.ThreadSubscription{}
+	for rows.Next() {
+		var s entities.ThreadSubscription
+		dest := []interface{}{&s.UserID, &s.ThreadID, &s.Level}
+		if withTitle {
+			dest = append(dest, &s.ThreadTitle)
+		}
+		dest = append(dest, &s.CreatedAt, &s.UpdatedAt)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
+		}
+		subs = append(subs, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
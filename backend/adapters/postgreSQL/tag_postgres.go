@@ -0,0 +1,161 @@
+package postgressql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type TagPostgres struct {
+	db *pgxpool.Pool
+}
+
+func NewTagPostgres(db *pgxpool.Pool) repositories.TagRepository {
+	return &TagPostgres{db: db}
+}
+
+// tagSelect projects a tag with its usage count (published, non-deleted threads only) and aliases.
+const tagSelect = `
+	SELECT tg.id, tg.name,
+		(SELECT COUNT(*) FROM thread_tags tt JOIN threads t ON t.id = tt.thread_id WHERE tt.tag_id = tg.id AND t.is_deleted = false AND t.status = 'published') AS thread_count,
+		COALESCE((SELECT array_agg(ta.alias ORDER BY ta.alias) FROM tag_aliases ta WHERE ta.tag_id = tg.id), '{}') AS aliases
+	FROM tags tg`
+
+func (r *TagPostgres) ListTags(ctx context.Context, limit int) ([]entities.Tag, error) {
+	rows, err := r.db.Query(ctx, tagSelect+` ORDER BY thread_count DESC, tg.name ASC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	defer rows.Close()
+	var tags []entities.Tag
+	for rows.Next() {
+		var tag entities.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.ThreadCount, &tag.Aliases); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TagPostgres) GetTagByName(ctx context.Context, name string) (*entities.Tag, error) {
+	query := tagSelect + ` WHERE tg.name = $1 OR tg.id = (SELECT tag_id FROM tag_aliases WHERE alias = $1)`
+	var tag entities.Tag
+	if err := r.db.QueryRow(ctx, query, name).Scan(&tag.ID, &tag.Name, &tag.ThreadCount, &tag.Aliases); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get tag: %w", err)
+	}
+	return &tag, nil
+}
+
+func (r *TagPostgres) RenameTag(ctx context.Context, id int, newName string) error {
+	_, err := r.db.Exec(ctx, `UPDATE tags SET name = $1 WHERE id = $2`, newName, id)
+	if err != nil {
+		return fmt.Errorf("rename tag: %w", err)
+	}
+	return nil
+}
+
+func (r *TagPostgres) MergeTags(ctx context.Context, fromID, intoID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// relink threads; threads that already carry both tags keep a single link
+	_, err = tx.Exec(ctx, `INSERT INTO thread_tags (thread_id, tag_id) SELECT thread_id, $2 FROM thread_tags WHERE tag_id = $1 ON CONFLICT DO NOTHING`, fromID, intoID)
+	if err != nil {
+		return fmt.Errorf("relink threads: %w", err)
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM thread_tags WHERE tag_id = $1`, fromID)
+	if err != nil {
+		return fmt.Errorf("clear merged links: %w", err)
+	}
+	// existing aliases of the merged tag follow it, and its own name becomes an alias
+	_, err = tx.Exec(ctx, `UPDATE tag_aliases SET tag_id = $2 WHERE tag_id = $1`, fromID, intoID)
+	if err != nil {
+		return fmt.Errorf("move aliases: %w", err)
+	}
+	var name string
+	err = tx.QueryRow(ctx, `DELETE FROM tags WHERE id = $1 RETURNING name`, fromID).Scan(&name)
+	if err != nil {
+		return fmt.Errorf("delete merged tag: %w", err)
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO tag_aliases (alias, tag_id) VALUES ($1, $2) ON CONFLICT (alias) DO UPDATE SET tag_id = EXCLUDED.tag_id`, name, intoID)
+	if err != nil {
+		return fmt.Errorf("alias merged tag: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *TagPostgres) AddAlias(ctx context.Context, tagID int, alias string) error {
+	_, err := r.db.Exec(ctx, `INSERT INTO tag_aliases (alias, tag_id) VALUES ($1, $2)`, alias, tagID)
+	if err != nil {
+		return fmt.Errorf("add alias: %w", err)
+	}
+	return nil
+}
+
+func (r *TagPostgres) DeleteAlias(ctx context.Context, alias string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM tag_aliases WHERE alias = $1`, alias)
+	if err != nil {
+		return fmt.Errorf("delete alias: %w", err)
+	}
+	return nil
+}
+
+// DeleteTag removes the tag; thread links and aliases go with it via ON DELETE CASCADE.
+func (r *TagPostgres) DeleteTag(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM tags WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+	return nil
+}
+
+// ensureTagID resolves a normalized tag name to its id inside tx, following aliases and
+// creating the tag when neither the name nor an alias exists.
+func ensureTagID(ctx context.Context, tx pgx.Tx, name string) (int, error) {
+	var tagID int
+	err := tx.QueryRow(ctx, `SELECT tag_id FROM tag_aliases WHERE alias = $1`, name).Scan(&tagID)
+	if err == nil {
+		return tagID, nil
+	}
+	if err != pgx.ErrNoRows {
+		return 0, fmt.Errorf("resolve tag alias: %w", err)
+	}
+	err = tx.QueryRow(ctx, `INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id`, name).Scan(&tagID)
+	if err != nil {
+		return 0, fmt.Errorf("ensure tag: %w", err)
+	}
+	return tagID, nil
+}
+
+// linkTags attaches the given tags to a thread inside tx.
+func linkTags(ctx context.Context, tx pgx.Tx, threadID int, tags []string) error {
+	for _, tag := range tags {
+		tagID, err := ensureTagID(ctx, tx, tag)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO thread_tags (thread_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, threadID, tagID)
+		if err != nil {
+			return fmt.Errorf("link tag: %w", err)
+		}
+	}
+	return nil
+}
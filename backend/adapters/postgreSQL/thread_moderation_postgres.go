@@ -0,0 +1,181 @@
+package postgressql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// refreshThreadCounters recomputes the reply counters of threads whose replies moved.
+const refreshThreadCounters = `
+	UPDATE threads t SET
+		reply_count = (SELECT COUNT(*) FROM replies r WHERE r.thread_id = t.id AND r.is_deleted = false),
+		last_activity_at = GREATEST(t.created_at, COALESCE((SELECT MAX(r.created_at) FROM replies r WHERE r.thread_id = t.id AND r.is_deleted = false), t.created_at))
+	WHERE t.id = ANY($1)`
+
+// logModAction writes the audit entry of a moderator operation inside tx.
+func logModAction(ctx context.Context, tx pgx.Tx, actorID int, action entities.ModAction, threadID, targetID int, details map[string]int64) error {
+	b, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO moderation_log (actor_id, action, thread_id, target_thread_id, details) VALUES ($1, $2, $3, $4, $5)`,
+		actorID, string(action), threadID, targetID, b)
+	if err != nil {
+		return fmt.Errorf("write moderation log: %w", err)
+	}
+	return nil
+}
+
+func (r *ThreadPostgres) MergeThreads(ctx context.Context, sourceID, targetID, actorID int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// lock both rows in id order so concurrent merges cannot deadlock
+	var live int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM (SELECT id FROM threads WHERE id = ANY($1) AND merged_into IS NULL ORDER BY id FOR UPDATE) locked`,
+		[]int{sourceID, targetID}).Scan(&live)
+	if err != nil {
+		return false, fmt.Errorf("lock threads: %w", err)
+	}
+	if live != 2 {
+		return false, nil
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE replies SET thread_id = $2 WHERE thread_id = $1`, sourceID, targetID)
+	if err != nil {
+		return false, fmt.Errorf("move replies: %w", err)
+	}
+	replies := tag.RowsAffected()
+	if _, err := tx.Exec(ctx, `UPDATE post_mentions SET thread_id = $2 WHERE thread_id = $1 AND reply_id IS NOT NULL`, sourceID, targetID); err != nil {
+		return false, fmt.Errorf("move mentions: %w", err)
+	}
+	// a user who voted on both threads keeps the vote on the target
+	tag, err = tx.Exec(ctx, `
+	UPDATE votes v SET thread_id = $2
+	WHERE v.thread_id = $1 AND v.reply_id IS NULL
+		AND NOT EXISTS (SELECT 1 FROM votes t WHERE t.thread_id = $2 AND t.reply_id IS NULL AND t.user_id = v.user_id)`, sourceID, targetID)
+	if err != nil {
+		return false, fmt.Errorf("move votes: %w", err)
+	}
+	votes := tag.RowsAffected()
+	if _, err := tx.Exec(ctx, `DELETE FROM votes WHERE thread_id = $1 AND reply_id IS NULL`, sourceID); err != nil {
+		return false, fmt.Errorf("drop duplicate votes: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+	UPDATE threads t SET
+		upvotes = (SELECT COUNT(*) FROM votes v WHERE v.thread_id = t.id AND v.reply_id IS NULL AND v.value = 1),
+		downvotes = (SELECT COUNT(*) FROM votes v WHERE v.thread_id = t.id AND v.reply_id IS NULL AND v.value = -1)
+	WHERE t.id = ANY($1)`, []int{sourceID, targetID})
+	if err != nil {
+		return false, fmt.Errorf("recount votes: %w", err)
+	}
+	// watchers of the source follow the conversation to the target
+	_, err = tx.Exec(ctx, `
+	INSERT INTO thread_subscriptions (user_id, thread_id, level, created_at)
+	SELECT user_id, $2, level, created_at FROM thread_subscriptions WHERE thread_id = $1
+	ON CONFLICT DO NOTHING`, sourceID, targetID)
+	if err != nil {
+		return false, fmt.Errorf("move subscriptions: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+	UPDATE threads SET merged_into = $2, accepted_reply_id = NULL,
+		is_locked = true, locked_by = $3, locked_at = CURRENT_TIMESTAMP, lock_reason = $4,
+		pin_scope = NULL, pin_tag = NULL, pinned_until = NULL, pinned_by = NULL, pinned_at = NULL,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1`, sourceID, targetID, actorID, fmt.Sprintf("merged into #%d", targetID))
+	if err != nil {
+		return false, fmt.Errorf("leave redirect stub: %w", err)
+	}
+	if _, err := tx.Exec(ctx, refreshThreadCounters, []int{sourceID, targetID}); err != nil {
+		return false, fmt.Errorf("refresh counters: %w", err)
+	}
+	if err := logModAction(ctx, tx, actorID, entities.ModActionMerge, sourceID, targetID, map[string]int64{"replies": replies, "votes": votes}); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	return true, nil
+}
+
+func (r *ThreadPostgres) SplitThread(ctx context.Context, split entities.ThreadSplit) (int, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var categoryID int
+	err = tx.QueryRow(ctx, `SELECT category_id FROM threads WHERE id = $1 AND merged_into IS NULL FOR UPDATE`, split.ThreadID).Scan(&categoryID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("lock thread: %w", err)
+	}
+	// the new thread belongs to whoever started the tangent, not the moderator
+	var authorID int
+	err = tx.QueryRow(ctx, `SELECT user_id FROM replies WHERE id = $1 AND thread_id = $2 AND is_deleted = false`, split.ReplyID, split.ThreadID).Scan(&authorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, true, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("find reply: %w", err)
+	}
+	var newID int
+	err = tx.QueryRow(ctx, `
+	INSERT INTO threads (user_id, category_id, title, body, is_locked, is_deleted, status, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, false, false, 'published', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`,
+		authorID, categoryID, split.Title, split.Body).Scan(&newID)
+	if err != nil {
+		return 0, false, fmt.Errorf("insert thread: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO thread_tags (thread_id, tag_id) SELECT $2, tag_id FROM thread_tags WHERE thread_id = $1`, split.ThreadID, newID); err != nil {
+		return 0, false, fmt.Errorf("copy tags: %w", err)
+	}
+
+	// parent_id links inside the subtree are kept; only the root loses its parent
+	tag, err := tx.Exec(ctx, `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM replies WHERE id = $2 AND thread_id = $1
+		UNION ALL
+		SELECT r.id FROM replies r JOIN subtree s ON r.parent_id = s.id
+	)
+	UPDATE replies SET thread_id = $3, parent_id = CASE WHEN id = $2 THEN NULL ELSE parent_id END
+	WHERE id IN (SELECT id FROM subtree)`, split.ThreadID, split.ReplyID, newID)
+	if err != nil {
+		return 0, false, fmt.Errorf("move replies: %w", err)
+	}
+	replies := tag.RowsAffected()
+	if _, err := tx.Exec(ctx, `UPDATE post_mentions m SET thread_id = $2 FROM replies r WHERE r.id = m.reply_id AND r.thread_id = $2 AND m.thread_id = $1`, split.ThreadID, newID); err != nil {
+		return 0, false, fmt.Errorf("move mentions: %w", err)
+	}
+	// an accepted answer that moved away no longer answers the source
+	_, err = tx.Exec(ctx, `
+	UPDATE threads SET accepted_reply_id = NULL
+	WHERE id = $1 AND accepted_reply_id IN (SELECT id FROM replies WHERE thread_id = $2)`, split.ThreadID, newID)
+	if err != nil {
+		return 0, false, fmt.Errorf("clear accepted reply: %w", err)
+	}
+	if _, err := tx.Exec(ctx, refreshThreadCounters, []int{split.ThreadID, newID}); err != nil {
+		return 0, false, fmt.Errorf("refresh counters: %w", err)
+	}
+	if err := logModAction(ctx, tx, split.ActorID, entities.ModActionSplit, split.ThreadID, newID, map[string]int64{"reply_id": int64(split.ReplyID), "replies": replies}); err != nil {
+		return 0, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, false, fmt.Errorf("commit tx: %w", err)
+	}
+	return newID, true, nil
+}
@@ -3,7 +3,10 @@ package postgressql
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/nocson47/beaconofknowledge/internal/entities"
 	"github.com/nocson47/beaconofknowledge/internal/repositories"
@@ -17,7 +20,7 @@ func NewThreadPostgres(db *pgxpool.Pool) repositories.ThreadRepository {
 	return &ThreadPostgres{db: db}
 }
 
-// CreateThread inserts thread and links tags (creates tags if needed).
+// CreateThread inserts thread and links tags (creates tags if needed, resolving aliases).
 func (r *ThreadPostgres) CreateThread(ctx context.Context, thread *entities.Thread) (int, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -25,30 +28,24 @@ func (r *ThreadPostgres) CreateThread(ctx context.Context, thread *entities.Thre
 	}
 	defer tx.Rollback(ctx)
 
-	insertThread := `INSERT INTO threads (user_id, title, body, is_locked, is_deleted, created_at, updated_at)
-                     VALUES ($1,$2,$3,$4,$5,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP) RETURNING id`
+	status := thread.Status
+	if status == "" {
+		status = entities.ThreadStatusPublished
+	}
+	insertThread := `INSERT INTO threads (user_id, category_id, title, body, is_locked, is_deleted, status, publish_at, created_at, updated_at)
+                     VALUES ($1,$2,$3,$4,$5,$6,$7,$8,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP) RETURNING id`
 	var id int
-	err = tx.QueryRow(ctx, insertThread, thread.UserID, thread.Title, thread.Body, thread.IsLocked, thread.IsDeleted).Scan(&id)
+	err = tx.QueryRow(ctx, insertThread, thread.UserID, thread.CategoryID, thread.Title, thread.Body, thread.IsLocked, thread.IsDeleted, string(status), thread.PublishAt).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("insert thread: %w", err)
 	}
 
 	// handle tags (optional)
-	for _, tag := range thread.Tags {
-		var tagID int
-		// upsert tag
-		err = tx.QueryRow(ctx, `INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id`, tag).Scan(&tagID)
-		if err != nil {
-			// if RETURNING fails due to conflict handling, fetch id
-			err = tx.QueryRow(ctx, `SELECT id FROM tags WHERE name = $1`, tag).Scan(&tagID)
-			if err != nil {
-				return 0, fmt.Errorf("ensure tag: %w", err)
-			}
-		}
-		_, err = tx.Exec(ctx, `INSERT INTO thread_tags (thread_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, id, tagID)
-		if err != nil {
-			return 0, fmt.Errorf("link tag: %w", err)
-		}
+	if err := linkTags(ctx, tx, id, thread.Tags); err != nil {
+		return 0, err
+	}
+	if err := linkAttachments(ctx, tx, thread.UserID, thread.AttachmentIDs, &id, nil); err != nil {
+		return 0, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -57,36 +54,92 @@ func (r *ThreadPostgres) CreateThread(ctx context.Context, thread *entities.Thre
 	return id, nil
 }
 
-func (r *ThreadPostgres) GetThreadByID(ctx context.Context, id int) (*entities.Thread, error) {
-	query := `
-	SELECT t.id, t.user_id, u.username AS author, t.title, t.body, t.is_locked, t.is_deleted, t.upvotes, t.downvotes, t.created_at, t.updated_at,
-		COALESCE(array_agg(tags.name) FILTER (WHERE tags.name IS NOT NULL), '{}') AS tags
+// threadSelect is the shared projection for thread reads; tags are aggregated per row
+// so the query composes with keyset filters without a GROUP BY.
+const threadSelect = `
+	SELECT t.id, t.user_id, t.category_id, u.username AS author, t.title, %s, t.is_locked, t.locked_by, t.locked_at, COALESCE(t.lock_reason, ''),
+		t.is_deleted, t.merged_into, CASE WHEN ` + pinActive + ` THEN t.pin_scope ELSE '' END, COALESCE(t.pin_tag, ''), t.pinned_until, t.pinned_by, t.pinned_at, t.upvotes, t.downvotes,
+		t.accepted_reply_id, t.status, t.publish_at, t.reply_count, t.view_count, t.last_activity_at, t.created_at, t.updated_at,
+		COALESCE((SELECT array_agg(tags.name ORDER BY tags.name) FROM thread_tags tt JOIN tags ON tags.id = tt.tag_id WHERE tt.thread_id = t.id), '{}') AS tags
 	FROM threads t
-	LEFT JOIN users u ON u.id = t.user_id
-	LEFT JOIN thread_tags tt ON tt.thread_id = t.id
-	LEFT JOIN tags ON tags.id = tt.tag_id
-	WHERE t.id = $1
-	GROUP BY t.id, u.username;
-    `
-	var thread entities.Thread
+	LEFT JOIN users u ON u.id = t.user_id`
+
+// pinActive is true for rows carrying a pin that has not expired yet. Expired pins are
+// ignored on read so they stop applying on time even before ClearExpiredPins runs.
+const pinActive = `(t.pin_scope IS NOT NULL AND (t.pinned_until IS NULL OR t.pinned_until > CURRENT_TIMESTAMP))`
+
+// canonicalTag resolves the tag name in parameter %[1]s, which may be an alias, to the
+// canonical tag name
+const canonicalTag = `COALESCE((SELECT atg.name FROM tag_aliases ta JOIN tags atg ON atg.id = ta.tag_id WHERE ta.alias = %[1]s), %[1]s)`
+
+// listBodyPreview caps the body length returned by ListThreads so feed pages stay small.
+const listBodyPreview = 280
+
+func scanThread(row pgx.Row) (*entities.Thread, error) {
+	var th entities.Thread
 	var tags []string
-	if err := r.db.QueryRow(ctx, query, id).Scan(&thread.ID, &thread.UserID, &thread.Author, &thread.Title, &thread.Body, &thread.IsLocked, &thread.IsDeleted, &thread.Upvotes, &thread.Downvotes, &thread.CreatedAt, &thread.UpdatedAt, &tags); err != nil {
+	var pin entities.ThreadPin
+	var pinnedAt *time.Time
+	if err := row.Scan(&th.ID, &th.UserID, &th.CategoryID, &th.Author, &th.Title, &th.Body, &th.IsLocked, &th.LockedBy, &th.LockedAt, &th.LockReason,
+		&th.IsDeleted, &th.MergedInto, &pin.Scope, &pin.Tag, &pin.ExpiresAt, &pin.PinnedBy, &pinnedAt, &th.Upvotes, &th.Downvotes,
+		&th.AcceptedReplyID, &th.Status, &th.PublishAt, &th.ReplyCount, &th.Views, &th.LastActivityAt, &th.CreatedAt, &th.UpdatedAt, &tags); err != nil {
+		return nil, err
+	}
+	th.Tags = tags
+	th.Solved = th.AcceptedReplyID != nil
+	if pin.Scope != "" {
+		if pinnedAt != nil {
+			pin.PinnedAt = *pinnedAt
+		}
+		th.Pin = &pin
+	}
+	return &th, nil
+}
+
+func (r *ThreadPostgres) GetThreadByID(ctx context.Context, id int) (*entities.Thread, error) {
+	query := fmt.Sprintf(threadSelect, "t.body") + `
+	WHERE t.id = $1`
+	thread, err := scanThread(r.db.QueryRow(ctx, query, id))
+	if err != nil {
 		// use pgxpool/errors handling at caller
 		return nil, fmt.Errorf("failed to retrieve thread: %w", err)
 	}
-	thread.Tags = tags
-	return &thread, nil
+	return thread, nil
 }
 
-func (r *ThreadPostgres) UpdateThread(ctx context.Context, thread *entities.Thread) error {
+func (r *ThreadPostgres) UpdateThread(ctx context.Context, thread *entities.Thread, edit entities.EditInfo) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	update := `UPDATE threads SET title=$1, body=$2, is_locked=$3, is_deleted=$4, updated_at=CURRENT_TIMESTAMP WHERE id=$5`
-	_, err = tx.Exec(ctx, update, thread.Title, thread.Body, thread.IsLocked, thread.IsDeleted, thread.ID)
+	// lock the row so concurrent edits snapshot distinct previous versions
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM threads WHERE id = $1 FOR UPDATE`, thread.ID); err != nil {
+		return fmt.Errorf("lock thread: %w", err)
+	}
+	// snapshot the previous content, but only when the edit actually changes it
+	snapshot := `
+	INSERT INTO post_revisions (thread_id, editor_id, reason, title, body, tags, created_at)
+	SELECT t.id, $2, NULLIF($3, ''), t.title, t.body, cur.tags, NOW()
+	FROM threads t
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(array_agg(tags.name ORDER BY tags.name), '{}') AS tags
+		FROM thread_tags tt JOIN tags ON tags.id = tt.tag_id WHERE tt.thread_id = t.id
+	) cur
+	WHERE t.id = $1
+		AND (t.title <> $4 OR t.body <> $5 OR cur.tags <> (SELECT COALESCE(array_agg(n ORDER BY n), '{}') FROM unnest($6::text[]) n))`
+	tags := thread.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	if _, err = tx.Exec(ctx, snapshot, thread.ID, edit.EditorID, edit.Reason, thread.Title, thread.Body, tags); err != nil {
+		return fmt.Errorf("record revision: %w", err)
+	}
+
+	// lock and delete state have their own paths (SetThreadLock, DeleteThread)
+	update := `UPDATE threads SET title=$1, body=$2, updated_at=CURRENT_TIMESTAMP WHERE id=$3`
+	_, err = tx.Exec(ctx, update, thread.Title, thread.Body, thread.ID)
 	if err != nil {
 		return fmt.Errorf("update thread: %w", err)
 	}
@@ -96,19 +149,8 @@ func (r *ThreadPostgres) UpdateThread(ctx context.Context, thread *entities.Thre
 	if err != nil {
 		return fmt.Errorf("clear thread tags: %w", err)
 	}
-	for _, tag := range thread.Tags {
-		var tagID int
-		err = tx.QueryRow(ctx, `INSERT INTO tags (name) VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id`, tag).Scan(&tagID)
-		if err != nil {
-			err = tx.QueryRow(ctx, `SELECT id FROM tags WHERE name = $1`, tag).Scan(&tagID)
-			if err != nil {
-				return fmt.Errorf("ensure tag: %w", err)
-			}
-		}
-		_, err = tx.Exec(ctx, `INSERT INTO thread_tags (thread_id, tag_id) VALUES ($1,$2) ON CONFLICT DO NOTHING`, thread.ID, tagID)
-		if err != nil {
-			return fmt.Errorf("link tag: %w", err)
-		}
+	if err := linkTags(ctx, tx, thread.ID, thread.Tags); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -126,32 +168,371 @@ func (r *ThreadPostgres) DeleteThread(ctx context.Context, id int) error {
 	return nil
 }
 
-func (r *ThreadPostgres) GetAllThreads(ctx context.Context) ([]*entities.Thread, error) {
-	// Only return threads that are not soft-deleted so frontend won't show deleted posts
-	query := `
-	SELECT t.id, t.user_id, u.username AS author, t.title, t.body, t.is_locked, t.is_deleted, t.upvotes, t.downvotes, t.created_at, t.updated_at,
-		COALESCE(array_agg(tags.name) FILTER (WHERE tags.name IS NOT NULL), '{}') AS tags
+func (r *ThreadPostgres) SetThreadLock(ctx context.Context, id int, locked bool, actorID int, reason string) error {
+	var query string
+	var args []interface{}
+	if locked {
+		query = `UPDATE threads SET is_locked = true, locked_by = $2, locked_at = CURRENT_TIMESTAMP, lock_reason = NULLIF($3, ''), updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+		args = []interface{}{id, actorID, reason}
+	} else {
+		query = `UPDATE threads SET is_locked = false, locked_by = NULL, locked_at = NULL, lock_reason = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+		args = []interface{}{id}
+	}
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set thread lock: %w", err)
+	}
+	return nil
+}
+
+func (r *ThreadPostgres) ListPinnedThreads(ctx context.Context, opts entities.ThreadListOptions) ([]*entities.Thread, error) {
+	conds, args := threadListFilters(opts)
+	scope := "t.pin_scope = 'global'"
+	if opts.Tag != "" {
+		// threadListFilters passes the tag first
+		scope = fmt.Sprintf("(t.pin_scope = 'global' OR t.pin_tag = %s)", fmt.Sprintf(canonicalTag, "$1"))
+	}
+	conds = append([]string{"t.is_deleted = false", "t.status = 'published'", "t.merged_into IS NULL", pinActive, scope}, conds...)
+	query := fmt.Sprintf(threadSelect, fmt.Sprintf("LEFT(t.body, %d)", listBodyPreview)) + `
+	WHERE ` + strings.Join(conds, " AND ") + `
+	ORDER BY t.pin_scope = 'global' DESC, t.pinned_at DESC, t.id DESC`
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list pinned threads: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []*entities.Thread
+	for rows.Next() {
+		th, err := scanThread(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan thread: %w", err)
+		}
+		threads = append(threads, th)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return threads, nil
+}
+
+func (r *ThreadPostgres) SetThreadPin(ctx context.Context, id int, pin *entities.ThreadPin) error {
+	var query string
+	var args []interface{}
+	if pin != nil {
+		query = `UPDATE threads SET pin_scope = $2, pin_tag = NULLIF($3, ''), pinned_until = $4, pinned_by = $5, pinned_at = CURRENT_TIMESTAMP WHERE id = $1`
+		args = []interface{}{id, string(pin.Scope), pin.Tag, pin.ExpiresAt, pin.PinnedBy}
+	} else {
+		query = `UPDATE threads SET pin_scope = NULL, pin_tag = NULL, pinned_until = NULL, pinned_by = NULL, pinned_at = NULL WHERE id = $1`
+		args = []interface{}{id}
+	}
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("set thread pin: %w", err)
+	}
+	return nil
+}
+
+func (r *ThreadPostgres) ClearExpiredPins(ctx context.Context) ([]int, error) {
+	rows, err := r.db.Query(ctx, `
+	UPDATE threads SET pin_scope = NULL, pin_tag = NULL, pinned_until = NULL, pinned_by = NULL, pinned_at = NULL
+	WHERE pin_scope IS NOT NULL AND pinned_until <= CURRENT_TIMESTAMP
+	RETURNING id`)
+	if err != nil {
+		return nil, fmt.Errorf("clear expired pins: %w", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *ThreadPostgres) SetAcceptedReply(ctx context.Context, threadID int, replyID *int) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+	UPDATE threads SET accepted_reply_id = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND ($2::int IS NULL OR EXISTS (SELECT 1 FROM replies WHERE id = $2 AND thread_id = $1 AND is_deleted = false))`,
+		threadID, replyID)
+	if err != nil {
+		return false, fmt.Errorf("set accepted reply: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *ThreadPostgres) SetThreadPublishing(ctx context.Context, id int, status entities.ThreadStatus, publishAt *time.Time) (bool, error) {
+	query := `UPDATE threads SET status = $2, publish_at = $3, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status <> 'published'`
+	args := []interface{}{id, string(status), publishAt}
+	if status == entities.ThreadStatusPublished {
+		// a thread counts as new from the moment it goes live
+		query = `UPDATE threads SET status = 'published', publish_at = CURRENT_TIMESTAMP, created_at = CURRENT_TIMESTAMP,
+			last_activity_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status <> 'published'`
+		args = args[:1]
+	}
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("set thread publishing: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *ThreadPostgres) PublishDueThreads(ctx context.Context) ([]int, error) {
+	rows, err := r.db.Query(ctx, `
+	UPDATE threads SET status = 'published', created_at = publish_at, last_activity_at = publish_at, updated_at = CURRENT_TIMESTAMP
+	WHERE status = 'scheduled' AND publish_at <= CURRENT_TIMESTAMP
+	RETURNING id`)
+	if err != nil {
+		return nil, fmt.Errorf("publish due threads: %w", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *ThreadPostgres) SetViewCounts(ctx context.Context, counts map[int]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(counts))
+	ns := make([]int64, 0, len(counts))
+	for id, n := range counts {
+		ids = append(ids, id)
+		ns = append(ns, int64(n))
+	}
+	// updated_at is left alone: a view is not an edit
+	_, err := r.db.Exec(ctx, `
+	UPDATE threads t SET view_count = GREATEST(t.view_count, v.n)
+	FROM unnest($1::int[], $2::bigint[]) AS v(id, n)
+	WHERE t.id = v.id`, ids, ns)
+	if err != nil {
+		return fmt.Errorf("set view counts: %w", err)
+	}
+	return nil
+}
+
+func (r *ThreadPostgres) GetThreadsByIDs(ctx context.Context, ids []int) ([]*entities.Thread, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := fmt.Sprintf(threadSelect, fmt.Sprintf("LEFT(t.body, %d)", listBodyPreview)) + `
+	WHERE t.id = ANY($1) AND t.is_deleted = false AND t.status = 'published' AND t.merged_into IS NULL`
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get threads by ids: %w", err)
+	}
+	defer rows.Close()
+	var threads []*entities.Thread
+	for rows.Next() {
+		th, err := scanThread(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan thread: %w", err)
+		}
+		threads = append(threads, th)
+	}
+	return threads, rows.Err()
+}
+
+func (r *ThreadPostgres) ListThreadActivity(ctx context.Context, activeSince, repliesSince time.Time, limit int) ([]entities.ThreadActivity, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT t.id, t.upvotes, t.downvotes, t.created_at,
+		(SELECT COUNT(*) FROM replies r WHERE r.thread_id = t.id AND r.is_deleted = false AND r.created_at >= $2)
 	FROM threads t
-	LEFT JOIN users u ON u.id = t.user_id
-	LEFT JOIN thread_tags tt ON tt.thread_id = t.id
-	LEFT JOIN tags ON tags.id = tt.tag_id
-	WHERE t.is_deleted = false
-	GROUP BY t.id, u.username;`
-	rows, err := r.db.Query(ctx, query)
+	WHERE t.is_deleted = false AND t.status = 'published' AND t.merged_into IS NULL AND t.last_activity_at >= $1
+	ORDER BY t.last_activity_at DESC
+	LIMIT $3`, activeSince, repliesSince, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list thread activity: %w", err)
+	}
+	defer rows.Close()
+	var out []entities.ThreadActivity
+	for rows.Next() {
+		var a entities.ThreadActivity
+		if err := rows.Scan(&a.ThreadID, &a.Upvotes, &a.Downvotes, &a.CreatedAt, &a.RecentReplies); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// RankTrendingThreads computes usecases.trendingScore in SQL: net votes plus weighted recent
+// replies, over the thread's age in hours plus two raised to the gravity.
+func (r *ThreadPostgres) RankTrendingThreads(ctx context.Context, q entities.TrendingQuery) ([]int, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT id FROM (
+		SELECT t.id,
+			((t.upvotes - t.downvotes) + $3 * (SELECT COUNT(*) FROM replies r WHERE r.thread_id = t.id AND r.is_deleted = false AND r.created_at >= $2))
+			/ POWER(GREATEST(EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - t.created_at)) / 3600, 0) + 2, $4) AS score
+		FROM threads t
+		WHERE t.is_deleted = false AND t.status = 'published' AND t.merged_into IS NULL AND t.last_activity_at >= $1
+		ORDER BY t.last_activity_at DESC
+		LIMIT $5
+	) ranked
+	ORDER BY score DESC, id DESC
+	OFFSET $6 LIMIT $7`, q.ActiveSince, q.RepliesSince, q.ReplyWeight, q.Gravity, q.Candidates, q.Offset, q.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("rank trending threads: %w", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Related threads score relatedTagWeight per shared tag, scaled down for common tags, plus
+// relatedTitleWeight times the trigram similarity of the titles.
+const (
+	relatedTagWeight   = 1.0
+	relatedTitleWeight = 1.5
+)
+
+// ListRelatedThreads considers threads sharing a tag with the source and threads whose title
+// passes the pg_trgm similarity threshold (%), which the trigram index serves.
+func (r *ThreadPostgres) ListRelatedThreads(ctx context.Context, threadID int, lockedSince time.Time, limit int) ([]int, error) {
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+	WITH src AS (
+		SELECT title FROM threads WHERE id = $1
+	), tag_weight AS (
+		SELECT st.tag_id, 1.0 / LN(2 + COUNT(*)) AS w
+		FROM thread_tags st JOIN thread_tags tt ON tt.tag_id = st.tag_id
+		WHERE st.thread_id = $1
+		GROUP BY st.tag_id
+	), candidates AS (
+		SELECT tt.thread_id AS id, tw.w AS tag_score
+		FROM thread_tags tt JOIN tag_weight tw ON tw.tag_id = tt.tag_id
+		UNION ALL
+		SELECT t.id, 0 FROM threads t, src WHERE t.title %% src.title
+	)
+	SELECT t.id
+	FROM (SELECT id, SUM(tag_score) AS tag_score FROM candidates WHERE id <> $1 GROUP BY id) c
+	JOIN threads t ON t.id = c.id, src
+	WHERE t.is_deleted = false AND t.status = 'published' AND t.merged_into IS NULL
+		AND NOT (t.is_locked AND COALESCE(t.locked_at, CURRENT_TIMESTAMP) > $2)
+	ORDER BY %v * c.tag_score + %v * similarity(t.title, src.title) DESC, t.id DESC
+	LIMIT $3`, relatedTagWeight, relatedTitleWeight), threadID, lockedSince, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list related threads: %w", err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// threadSortKey maps a sort mode to the column expression used for ordering and keyset comparison.
+func threadSortKey(sort entities.ThreadSort) string {
+	switch sort {
+	case entities.ThreadSortTop:
+		return "(t.upvotes - t.downvotes)"
+	case entities.ThreadSortDiscussed:
+		return "t.reply_count"
+	case entities.ThreadSortViews:
+		return "t.view_count"
+	case entities.ThreadSortLastActivity:
+		return "t.last_activity_at"
+	default:
+		return "t.created_at"
+	}
+}
+
+// threadListFilters returns the tag, category, author and solved conditions of opts with their
+// parameters, the tag first when set. ListThreads and ListPinnedThreads share them so pins
+// only lead listings they belong to.
+func threadListFilters(opts entities.ThreadListOptions) ([]string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	if opts.Tag != "" {
+		args = append(args, opts.Tag)
+		conds = append(conds, fmt.Sprintf("EXISTS (SELECT 1 FROM thread_tags ft JOIN tags ftg ON ftg.id = ft.tag_id WHERE ft.thread_id = t.id AND ftg.name = %s)", fmt.Sprintf(canonicalTag, "$1")))
+	}
+	if opts.CategoryID != 0 {
+		args = append(args, opts.CategoryID)
+		conds = append(conds, fmt.Sprintf(`t.category_id IN (
+		WITH RECURSIVE sub AS (
+			SELECT id FROM categories WHERE id = $%d
+			UNION ALL
+			SELECT c.id FROM categories c JOIN sub ON c.parent_id = sub.id
+		) SELECT id FROM sub)`, len(args)))
+	}
+	if opts.AuthorID != 0 {
+		args = append(args, opts.AuthorID)
+		conds = append(conds, fmt.Sprintf("t.user_id = $%d", len(args)))
+	}
+	if opts.Solved != nil {
+		if *opts.Solved {
+			conds = append(conds, "t.accepted_reply_id IS NOT NULL")
+		} else {
+			conds = append(conds, "t.accepted_reply_id IS NULL")
+		}
+	}
+	return conds, args
+}
+
+// ListThreads implements keyset pagination: rows are ordered by (sort key, id) descending
+// and the cursor carries the last row's pair, so each page is an index range scan.
+func (r *ThreadPostgres) ListThreads(ctx context.Context, opts entities.ThreadListOptions) ([]*entities.Thread, error) {
+	key := threadSortKey(opts.Sort)
+	conds, args := threadListFilters(opts)
+	conds = append([]string{"t.is_deleted = false", "t.status = 'published'", "t.merged_into IS NULL"}, conds...)
+	// ListPinnedThreads serves these, under the same filters
+	if opts.Tag != "" {
+		conds = append(conds, fmt.Sprintf("NOT (%s AND (t.pin_scope = 'global' OR t.pin_tag = %s))", pinActive, fmt.Sprintf(canonicalTag, "$1")))
+	} else {
+		conds = append(conds, fmt.Sprintf("NOT (%s AND t.pin_scope = 'global')", pinActive))
+	}
+	if c := opts.After; c != nil {
+		var cursorKey interface{} = c.Score
+		if opts.Sort == entities.ThreadSortNew || opts.Sort == entities.ThreadSortLastActivity {
+			if c.At == nil {
+				return nil, fmt.Errorf("cursor missing timestamp")
+			}
+			cursorKey = *c.At
+		}
+		args = append(args, cursorKey, c.ID)
+		conds = append(conds, fmt.Sprintf("(%s, t.id) < ($%d, $%d)", key, len(args)-1, len(args)))
+	}
+	args = append(args, opts.Limit)
+	query := fmt.Sprintf(threadSelect, fmt.Sprintf("LEFT(t.body, %d)", listBodyPreview)) + `
+	WHERE ` + strings.Join(conds, " AND ") + fmt.Sprintf(`
+	ORDER BY %s DESC, t.id DESC
+	LIMIT $%d`, key, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve threads: %w", err)
+		return nil, fmt.Errorf("list threads: %w", err)
 	}
 	defer rows.Close()
 
 	var threads []*entities.Thread
 	for rows.Next() {
-		var th entities.Thread
-		var tags []string
-		if err := rows.Scan(&th.ID, &th.UserID, &th.Author, &th.Title, &th.Body, &th.IsLocked, &th.IsDeleted, &th.Upvotes, &th.Downvotes, &th.CreatedAt, &th.UpdatedAt, &tags); err != nil {
+		th, err := scanThread(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan thread: %w", err)
 		}
-		th.Tags = tags
-		threads = append(threads, &th)
+		threads = append(threads, th)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
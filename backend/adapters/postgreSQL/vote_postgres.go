@@ -87,13 +87,19 @@ func (r *VotePostgres) CreateVote(ctx context.Context, v *entities.Vote) (int, e
 		}
 	}
 
-	// update thread counters if this vote is for a thread
+	// update the counters of whichever post the vote is for
 	if v.ThreadID != nil && (deltaUp != 0 || deltaDown != 0) {
 		_, err = tx.Exec(ctx, `UPDATE threads SET upvotes = upvotes + $1, downvotes = downvotes + $2 WHERE id = $3`, deltaUp, deltaDown, *v.ThreadID)
 		if err != nil {
 			return 0, fmt.Errorf("update thread counters: %w", err)
 		}
 	}
+	if v.ReplyID != nil && (deltaUp != 0 || deltaDown != 0) {
+		_, err = tx.Exec(ctx, `UPDATE replies SET upvotes = upvotes + $1, downvotes = downvotes + $2 WHERE id = $3`, deltaUp, deltaDown, *v.ReplyID)
+		if err != nil {
+			return 0, fmt.Errorf("update reply counters: %w", err)
+		}
+	}
 
 	if err = tx.Commit(ctx); err != nil {
 		return 0, fmt.Errorf("commit tx: %w", err)
@@ -149,18 +155,120 @@ func (r *VotePostgres) GetVotesForReply(ctx context.Context, replyID int) ([]ent
 }
 
 func (r *VotePostgres) DeleteVote(ctx context.Context, id int) error {
-	_, err := r.db.Exec(ctx, `DELETE FROM votes WHERE id = $1`, id)
+	_, err := r.deleteVotes(ctx, `id = $1`, id)
+	return err
+}
+
+func (r *VotePostgres) RetractVote(ctx context.Context, userID int, threadID, replyID *int) (bool, error) {
+	if threadID != nil {
+		return r.deleteVotes(ctx, `user_id = $1 AND thread_id = $2`, userID, *threadID)
+	}
+	if replyID != nil {
+		return r.deleteVotes(ctx, `user_id = $1 AND reply_id = $2`, userID, *replyID)
+	}
+	return false, nil
+}
+
+// deleteVotes removes the votes matching where and takes them back out of the thread and
+// reply counters in the same transaction. It reports whether anything was deleted.
+func (r *VotePostgres) deleteVotes(ctx context.Context, where string, args ...interface{}) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `DELETE FROM votes WHERE `+where+` RETURNING thread_id, reply_id, value`, args...)
+	if err != nil {
+		return false, fmt.Errorf("delete vote: %w", err)
+	}
+	type removed struct {
+		threadID, replyID *int
+		value             int
+	}
+	var deleted []removed
+	for rows.Next() {
+		var d removed
+		if err := rows.Scan(&d.threadID, &d.replyID, &d.value); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("scan deleted vote: %w", err)
+		}
+		deleted = append(deleted, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("delete vote: %w", err)
+	}
+
+	for _, d := range deleted {
+		deltaUp, deltaDown := 0, 0
+		if d.value == 1 {
+			deltaUp = -1
+		} else if d.value == -1 {
+			deltaDown = -1
+		}
+		if d.threadID != nil {
+			_, err = tx.Exec(ctx, `UPDATE threads SET upvotes = GREATEST(upvotes + $1, 0), downvotes = GREATEST(downvotes + $2, 0) WHERE id = $3`, deltaUp, deltaDown, *d.threadID)
+			if err != nil {
+				return false, fmt.Errorf("update thread counters: %w", err)
+			}
+		}
+		if d.replyID != nil {
+			_, err = tx.Exec(ctx, `UPDATE replies SET upvotes = GREATEST(upvotes + $1, 0), downvotes = GREATEST(downvotes + $2, 0) WHERE id = $3`, deltaUp, deltaDown, *d.replyID)
+			if err != nil {
+				return false, fmt.Errorf("update reply counters: %w", err)
+			}
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	return len(deleted) > 0, nil
+}
+
+func (r *VotePostgres) GetUserThreadVotes(ctx context.Context, userID int, threadIDs []int) (map[int]int, error) {
+	return r.userVotes(ctx, `SELECT thread_id, value FROM votes WHERE user_id = $1 AND thread_id = ANY($2)`, userID, threadIDs)
+}
+
+func (r *VotePostgres) GetUserReplyVotes(ctx context.Context, userID int, replyIDs []int) (map[int]int, error) {
+	return r.userVotes(ctx, `SELECT reply_id, value FROM votes WHERE user_id = $1 AND reply_id = ANY($2)`, userID, replyIDs)
+}
+
+func (r *VotePostgres) userVotes(ctx context.Context, query string, userID int, ids []int) (map[int]int, error) {
+	votes := make(map[int]int, len(ids))
+	if len(ids) == 0 {
+		return votes, nil
+	}
+	rows, err := r.db.Query(ctx, query, userID, ids)
 	if err != nil {
-		return fmt.Errorf("delete vote: %w", err)
+		return nil, fmt.Errorf("get user votes: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, value int
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, fmt.Errorf("scan vote: %w", err)
+		}
+		votes[id] = value
 	}
-	return nil
+	return votes, rows.Err()
 }
 
 func (r *VotePostgres) GetVoteCountsForThread(ctx context.Context, threadID int) (int, int, error) {
-	query := `SELECT SUM(CASE WHEN value = 1 THEN 1 ELSE 0 END) AS upvotes, SUM(CASE WHEN value = -1 THEN 1 ELSE 0 END) AS downvotes FROM votes WHERE thread_id = $1`
+	query := `SELECT COALESCE(SUM(CASE WHEN value = 1 THEN 1 ELSE 0 END), 0) AS upvotes, COALESCE(SUM(CASE WHEN value = -1 THEN 1 ELSE 0 END), 0) AS downvotes FROM votes WHERE thread_id = $1`
 	var up, down int
 	if err := r.db.QueryRow(ctx, query, threadID).Scan(&up, &down); err != nil {
 		return 0, 0, fmt.Errorf("get vote counts: %w", err)
 	}
 	return up, down, nil
 }
+
+// GetVoteCountsForReply reads the reply's denormalized counters.
+func (r *VotePostgres) GetVoteCountsForReply(ctx context.Context, replyID int) (int, int, error) {
+	var up, down int
+	err := r.db.QueryRow(ctx, `SELECT upvotes, downvotes FROM replies WHERE id = $1 AND is_deleted = false`, replyID).Scan(&up, &down)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get reply vote counts: %w", err)
+	}
+	return up, down, nil
+}
@@ -0,0 +1,85 @@
+package redisadapters
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RelatedCache stores the IDs of a thread's related threads under thread:<id>:related,
+// together with the generation in threads:related:gen they were stored under. InvalidateAll
+// bumps the generation, which turns every stored list into a miss without scanning keys.
+// Like RenderCache, Redis errors are treated as misses and every call is bounded by a
+// short timeout.
+type RelatedCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRelatedCache(client *redis.Client, ttl time.Duration) *RelatedCache {
+	return &RelatedCache{client: client, ttl: ttl}
+}
+
+const (
+	relatedCacheTimeout = 100 * time.Millisecond
+	relatedGenKey       = "threads:related:gen"
+)
+
+type relatedEntry struct {
+	Gen int64 `json:"g"`
+	IDs []int `json:"ids"`
+}
+
+func relatedKey(threadID int) string {
+	return "thread:" + strconv.Itoa(threadID) + ":related"
+}
+
+func (c *RelatedCache) Get(ctx context.Context, threadID int) ([]int, bool) {
+	ctx, cancel := context.WithTimeout(ctx, relatedCacheTimeout)
+	defer cancel()
+	var gen, data *redis.StringCmd
+	if _, err := c.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		gen = p.Get(ctx, relatedGenKey)
+		data = p.Get(ctx, relatedKey(threadID))
+		return nil
+	}); err != nil && err != redis.Nil {
+		return nil, false
+	}
+	b, err := data.Bytes()
+	if err != nil {
+		return nil, false
+	}
+	var e relatedEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, false
+	}
+	// a missing generation reads as 0, the generation lists are stored under before any bump
+	g, _ := gen.Int64()
+	if e.Gen != g {
+		return nil, false
+	}
+	return e.IDs, true
+}
+
+func (c *RelatedCache) Set(ctx context.Context, threadID int, ids []int) {
+	ctx, cancel := context.WithTimeout(ctx, relatedCacheTimeout)
+	defer cancel()
+	g, err := c.client.Get(ctx, relatedGenKey).Int64()
+	if err != nil && err != redis.Nil {
+		return
+	}
+	data, err := json.Marshal(relatedEntry{Gen: g, IDs: ids})
+	if err != nil {
+		return
+	}
+	c.client.Set(ctx, relatedKey(threadID), data, c.ttl)
+}
+
+func (c *RelatedCache) InvalidateAll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, relatedCacheTimeout)
+	defer cancel()
+	c.client.Incr(ctx, relatedGenKey)
+}
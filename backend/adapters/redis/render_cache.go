@@ -0,0 +1,38 @@
+package redisadapters
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RenderCache stores rendered post HTML under render:<key>. Redis errors are treated as
+// cache misses so rendering keeps working when Redis is down; each call is bounded by a
+// short timeout so an unreachable Redis does not stall list endpoints.
+type RenderCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRenderCache(client *redis.Client, ttl time.Duration) *RenderCache {
+	return &RenderCache{client: client, ttl: ttl}
+}
+
+const renderCacheTimeout = 100 * time.Millisecond
+
+func (c *RenderCache) Get(ctx context.Context, key string) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, renderCacheTimeout)
+	defer cancel()
+	out, err := c.client.Get(ctx, "render:"+key).Result()
+	if err != nil {
+		return "", false
+	}
+	return out, true
+}
+
+func (c *RenderCache) Set(ctx context.Context, key string, html string) {
+	ctx, cancel := context.WithTimeout(ctx, renderCacheTimeout)
+	defer cancel()
+	c.client.Set(ctx, "render:"+key, html, c.ttl)
+}
@@ -0,0 +1,79 @@
+package redisadapters
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// TrendingStore keeps the trending ranking in the threads:trending sorted set. A new ranking
+// is built under a temporary key and renamed into place, so readers never see a partial one.
+// threads:trending:gen holds the ranking's generation and marks that one is stored, since an
+// empty sorted set cannot exist.
+type TrendingStore struct {
+	client *redis.Client
+}
+
+func NewTrendingStore(client *redis.Client) *TrendingStore {
+	return &TrendingStore{client: client}
+}
+
+const (
+	trendingKey         = "threads:trending"
+	trendingBuildKey    = "threads:trending:build"
+	trendingGenKey      = "threads:trending:gen"
+	trendingReadTimeout = 100 * time.Millisecond
+)
+
+func (s *TrendingStore) Replace(ctx context.Context, scores map[int]float64, ttl time.Duration) error {
+	members := make([]*redis.Z, 0, len(scores))
+	for id, score := range scores {
+		members = append(members, &redis.Z{Score: score, Member: id})
+	}
+	// the build time is unique enough to tell rankings apart
+	gen := time.Now().UnixNano()
+	_, err := s.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		if len(members) == 0 {
+			p.Del(ctx, trendingKey)
+		} else {
+			p.Del(ctx, trendingBuildKey)
+			p.ZAdd(ctx, trendingBuildKey, members...)
+			p.Rename(ctx, trendingBuildKey, trendingKey)
+			p.PExpire(ctx, trendingKey, ttl)
+		}
+		p.Set(ctx, trendingGenKey, gen, ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *TrendingStore) Range(ctx context.Context, offset, limit int) ([]int, int64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, trendingReadTimeout)
+	defer cancel()
+	var gen *redis.StringCmd
+	var members *redis.StringSliceCmd
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		gen = p.Get(ctx, trendingGenKey)
+		members = p.ZRevRange(ctx, trendingKey, int64(offset), int64(offset+limit-1))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, 0, false, err
+	}
+	g, err := gen.Int64()
+	if err == redis.Nil {
+		return nil, 0, false, nil
+	}
+	if err != nil {
+		return nil, 0, false, err
+	}
+	ids := make([]int, 0, len(members.Val()))
+	for _, m := range members.Val() {
+		if id, err := strconv.Atoi(m); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, g, true, nil
+}
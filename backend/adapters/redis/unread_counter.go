@@ -0,0 +1,65 @@
+package redisadapters
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// UnreadCounter caches unread notification counts under notifications:unread:<user>. Like
+// RenderCache, Redis errors are treated as misses and every call is bounded by a short
+// timeout; Postgres stays the source of truth.
+type UnreadCounter struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewUnreadCounter(client *redis.Client, ttl time.Duration) *UnreadCounter {
+	return &UnreadCounter{client: client, ttl: ttl}
+}
+
+const unreadCounterTimeout = 100 * time.Millisecond
+
+// incrIfExists only adjusts counts that are already cached, so a count is never started
+// from a partial value; the TTL is refreshed on each change.
+var incrIfExists = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	local n = redis.call("INCRBY", KEYS[1], ARGV[1])
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return n
+end
+return nil`)
+
+func unreadKey(userID int) string {
+	return "notifications:unread:" + strconv.Itoa(userID)
+}
+
+func (c *UnreadCounter) Get(ctx context.Context, userID int) (int, bool) {
+	ctx, cancel := context.WithTimeout(ctx, unreadCounterTimeout)
+	defer cancel()
+	n, err := c.client.Get(ctx, unreadKey(userID)).Int()
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func (c *UnreadCounter) Set(ctx context.Context, userID, n int) {
+	ctx, cancel := context.WithTimeout(ctx, unreadCounterTimeout)
+	defer cancel()
+	c.client.Set(ctx, unreadKey(userID), n, c.ttl)
+}
+
+func (c *UnreadCounter) Add(ctx context.Context, userID, delta int) {
+	ctx, cancel := context.WithTimeout(ctx, unreadCounterTimeout)
+	defer cancel()
+	incrIfExists.Run(ctx, c.client, []string{unreadKey(userID)}, delta, c.ttl.Milliseconds())
+}
+
+func (c *UnreadCounter) Invalidate(ctx context.Context, userID int) {
+	ctx, cancel := context.WithTimeout(ctx, unreadCounterTimeout)
+	defer cancel()
+	c.client.Del(ctx, unreadKey(userID))
+}
@@ -0,0 +1,84 @@
+package redisadapters
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ViewCounter keeps one HyperLogLog per thread under views:thread:<id>, so unique viewers are
+// estimated in a few KB whatever the traffic. Threads viewed since the last flush are kept in
+// the views:dirty set. The HyperLogLogs have no TTL: they hold the lifetime estimate that the
+// flush job copies into Postgres.
+type ViewCounter struct {
+	client *redis.Client
+}
+
+func NewViewCounter(client *redis.Client) *ViewCounter {
+	return &ViewCounter{client: client}
+}
+
+// viewRecordTimeout bounds Record, which sits on the thread read path.
+const viewRecordTimeout = 100 * time.Millisecond
+
+const viewsDirtyKey = "views:dirty"
+
+func viewsKey(threadID int) string {
+	return "views:thread:" + strconv.Itoa(threadID)
+}
+
+// Record errors are ignored; a lost view only makes the estimate slightly low.
+func (c *ViewCounter) Record(ctx context.Context, threadID int, visitor string) {
+	ctx, cancel := context.WithTimeout(ctx, viewRecordTimeout)
+	defer cancel()
+	_, _ = c.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.PFAdd(ctx, viewsKey(threadID), visitor)
+		p.SAdd(ctx, viewsDirtyKey, threadID)
+		return nil
+	})
+}
+
+func (c *ViewCounter) PopDirty(ctx context.Context, max int) ([]int, error) {
+	members, err := c.client.SPopN(ctx, viewsDirtyKey, int64(max)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(members))
+	for _, m := range members {
+		if id, err := strconv.Atoi(m); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (c *ViewCounter) Counts(ctx context.Context, threadIDs []int) (map[int]int, error) {
+	cmds := make([]*redis.IntCmd, len(threadIDs))
+	_, err := c.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, id := range threadIDs {
+			cmds[i] = p.PFCount(ctx, viewsKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int]int, len(threadIDs))
+	for i, id := range threadIDs {
+		counts[id] = int(cmds[i].Val())
+	}
+	return counts, nil
+}
+
+func (c *ViewCounter) MarkDirty(ctx context.Context, threadIDs []int) error {
+	if len(threadIDs) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(threadIDs))
+	for i, id := range threadIDs {
+		members[i] = id
+	}
+	return c.client.SAdd(ctx, viewsDirtyKey, members...).Err()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runEvery calls fn every interval until ctx is cancelled. Failures are logged and the
+// job keeps its schedule; a run never overlaps the previous one.
+func runEvery(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("%s job failed: %v", name, err)
+			}
+		}
+	}
+}
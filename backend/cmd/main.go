@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/nocson47/beaconofknowledge/adapters/email"
 	"github.com/nocson47/beaconofknowledge/adapters/http"
+	"github.com/nocson47/beaconofknowledge/adapters/markdown"
 	mongoadapters "github.com/nocson47/beaconofknowledge/adapters/mongo"
 	postgressql "github.com/nocson47/beaconofknowledge/adapters/postgreSQL"
 	redisadapters "github.com/nocson47/beaconofknowledge/adapters/redis"
+	"github.com/nocson47/beaconofknowledge/adapters/storage"
 	"github.com/nocson47/beaconofknowledge/config"
 	"github.com/nocson47/beaconofknowledge/internal/repositories"
 	"github.com/nocson47/beaconofknowledge/internal/usecases"
@@ -38,8 +42,6 @@ func main() {
 	userService := usecases.NewUserUseCase(userRepo)
 	userHandler := http.NewUserHandler(userService)
 
-	threadRepo := postgressql.NewThreadPostgres(postgresConn)
-	threadService := usecases.NewThreadService(threadRepo) // returns usecases.ThreadService (interface)
 	// connect to redis and wire cache to handlers
 	redisClient := redisadapters.ConnectRedis(&cfg)
 	if err := redisadapters.PingRedis(redisClient); err != nil {
@@ -47,18 +49,121 @@ func main() {
 	} else {
 		log.Println("Connected to Redis")
 	}
-	threadHandler := http.NewThreadHandler(threadService, redisClient)
 
-	// Votes
+	// Markdown rendering for post bodies (rendered HTML cached in Redis by content hash)
+	content := usecases.NewContentPipeline(markdown.NewGoldmark(), markdown.NewSanitizer(), redisadapters.NewRenderCache(redisClient, 24*time.Hour))
+
+	categoryRepo := postgressql.NewCategoryPostgres(postgresConn)
 	voteRepo := postgressql.NewVotePostgres(postgresConn)
-	voteService := usecases.NewVoteService(voteRepo)
-	voteHandler := http.NewVoteHandler(voteService, redisClient)
+	subscriptionRepo := postgressql.NewSubscriptionPostgres(postgresConn)
+	mentionRepo := postgressql.NewMentionPostgres(postgresConn)
+	bookmarkRepo := postgressql.NewBookmarkPostgres(postgresConn)
+	reactionRepo := postgressql.NewReactionPostgres(postgresConn)
+	attachmentRepo := postgressql.NewAttachmentPostgres(postgresConn)
+
+	// Notifications are generated by the reply, vote and report services; unread counts are cached in Redis
+	notificationRepo := postgressql.NewNotificationPostgres(postgresConn)
+	notificationService := usecases.NewNotificationService(notificationRepo, redisadapters.NewUnreadCounter(redisClient, 24*time.Hour))
+	notificationHandler := http.NewNotificationHandler(notificationService)
+
+	threadRepo := postgressql.NewThreadPostgres(postgresConn)
+	relatedCache := redisadapters.NewRelatedCache(redisClient, 10*time.Minute)
+	threadService := usecases.NewThreadService(usecases.ThreadServiceDeps{
+		Repo:          threadRepo,
+		Categories:    categoryRepo,
+		Users:         userRepo,
+		Votes:         voteRepo,
+		Subscriptions: subscriptionRepo,
+		Mentions:      mentionRepo,
+		Bookmarks:     bookmarkRepo,
+		Reactions:     reactionRepo,
+		Attachments:   attachmentRepo,
+		Notifier:      notificationService,
+		Trending:      redisadapters.NewTrendingStore(redisClient),
+		Related:       relatedCache,
+		Content:       content,
+	})
+	viewService := usecases.NewViewService(redisadapters.NewViewCounter(redisClient), threadRepo)
+	threadHandler := http.NewThreadHandler(threadService, redisClient, viewService)
 
 	// Replies
 	replyRepo := postgressql.NewReplyPostgres(postgresConn)
-	replyService := usecases.NewReplyService(replyRepo)
+	replyService := usecases.NewReplyService(usecases.ReplyServiceDeps{
+		Repo:          replyRepo,
+		Threads:       threadRepo,
+		Categories:    categoryRepo,
+		Users:         userRepo,
+		Votes:         voteRepo,
+		Subscriptions: subscriptionRepo,
+		Mentions:      mentionRepo,
+		Reactions:     reactionRepo,
+		Attachments:   attachmentRepo,
+		Notifier:      notificationService,
+		Content:       content,
+	})
 	replyHandler := http.NewReplyHandler(replyService, userService, redisClient)
 
+	// Votes
+	voteService := usecases.NewVoteService(voteRepo, threadRepo, replyRepo, notificationService)
+	voteHandler := http.NewVoteHandler(voteService, redisClient)
+
+	// Reactions (emoji set from REACTION_EMOJIS; thread and reply reads include the counts)
+	reactionService := usecases.NewReactionService(reactionRepo, threadRepo, replyRepo, strings.Split(cfg.ReactionEmojis, ","))
+	reactionHandler := http.NewReactionHandler(reactionService)
+
+	// Attachments (files on local disk under ATTACHMENT_DIR; posts link them by ID)
+	attachmentDir := cfg.AttachmentDir
+	if attachmentDir == "" {
+		attachmentDir = "data/attachments"
+	}
+	blobStore, err := storage.NewLocalBlobStore(attachmentDir)
+	if err != nil {
+		log.Fatalf("Failed to open attachment storage: %v", err)
+	}
+	attachmentLimits := usecases.AttachmentLimits{MaxFileSize: cfg.AttachmentMaxBytes, UserQuota: cfg.AttachmentUserQuotaBytes}
+	if cfg.AttachmentTypes != "" {
+		attachmentLimits.AllowedTypes = strings.Split(cfg.AttachmentTypes, ",")
+	}
+	attachmentService := usecases.NewAttachmentService(attachmentRepo, threadRepo, replyRepo, userRepo, blobStore, attachmentLimits)
+	attachmentHandler := http.NewAttachmentHandler(attachmentService)
+
+	// Revisions (edit history is written by the thread and reply repositories)
+	revisionRepo := postgressql.NewRevisionPostgres(postgresConn)
+	revisionService := usecases.NewRevisionService(revisionRepo, threadRepo, replyRepo, threadService, replyService)
+	revisionHandler := http.NewRevisionHandler(revisionService, redisClient)
+
+	// Search
+	searchRepo := postgressql.NewSearchPostgres(postgresConn)
+	searchService := usecases.NewSearchService(searchRepo)
+	searchHandler := http.NewSearchHandler(searchService)
+
+	// Tags
+	tagRepo := postgressql.NewTagPostgres(postgresConn)
+	tagService := usecases.NewTagService(tagRepo, relatedCache)
+	tagHandler := http.NewTagHandler(tagService, threadService)
+
+	// Categories
+	categoryService := usecases.NewCategoryService(categoryRepo)
+	categoryHandler := http.NewCategoryHandler(categoryService, threadService)
+
+	// Polls
+	pollRepo := postgressql.NewPollPostgres(postgresConn)
+	pollService := usecases.NewPollService(pollRepo, threadRepo, userRepo)
+	pollHandler := http.NewPollHandler(pollService)
+
+	// Drafts
+	draftRepo := postgressql.NewDraftPostgres(postgresConn)
+	draftService := usecases.NewDraftService(draftRepo, threadRepo, replyRepo, time.Duration(cfg.DraftRetentionDays)*24*time.Hour)
+	draftHandler := http.NewDraftHandler(draftService)
+
+	// Subscriptions (participants are auto-subscribed by the thread and reply services)
+	subscriptionService := usecases.NewSubscriptionService(subscriptionRepo, threadRepo, userRepo)
+	subscriptionHandler := http.NewSubscriptionHandler(subscriptionService)
+
+	// Bookmarks (the thread service reads them for the bookmarked flag)
+	bookmarkService := usecases.NewBookmarkService(bookmarkRepo, threadRepo, replyRepo, userRepo)
+	bookmarkHandler := http.NewBookmarkHandler(bookmarkService)
+
 	// Reports: prefer Mongo if available, otherwise Postgres
 	var reportRepoUse repositories.ReportRepository
 	var reportService usecases.ReportService
@@ -79,7 +184,7 @@ func main() {
 		reportRepoUse = postgressql.NewReportPostgres(postgresConn)
 		logCol = nil
 	}
-	reportService = usecases.NewReportService(reportRepoUse)
+	reportService = usecases.NewReportService(reportRepoUse, threadRepo, replyRepo, notificationService)
 	reportHandler = http.NewReportHandler(reportService, logCol)
 
 	// Password reset: wire Postgres password reset repo and usecase. Use SMTP if configured, otherwise default to console sender (dev)
@@ -96,10 +201,55 @@ func main() {
 	prUsecase := usecases.NewPasswordResetUsecase(userRepo, prRepo, emailSender, time.Hour*24)
 	authHandler := http.NewAuthHandler(prUsecase)
 
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	// expired pins already stop applying on read; this clears them and drops cached copies
+	go runEvery(jobsCtx, "pin expiry", time.Minute, func(ctx context.Context) error {
+		ids, err := threadService.ClearExpiredPins(ctx)
+		for _, id := range ids {
+			redisClient.Del(ctx, fmt.Sprintf("thread:%d", id))
+		}
+		return err
+	})
+	// scheduled threads go live here; cached copies are dropped so readers see the change
+	go runEvery(jobsCtx, "scheduled publishing", time.Minute, func(ctx context.Context) error {
+		ids, err := threadService.PublishDueThreads(ctx)
+		for _, id := range ids {
+			redisClient.Del(ctx, fmt.Sprintf("thread:%d", id))
+		}
+		return err
+	})
+	// unique views are counted in Redis and copied to threads.view_count for sorting
+	go runEvery(jobsCtx, "view flush", time.Minute, func(ctx context.Context) error {
+		_, err := viewService.FlushViewCounts(ctx)
+		return err
+	})
+	go runEvery(jobsCtx, "trending", 5*time.Minute, func(ctx context.Context) error {
+		_, err := threadService.RefreshTrending(ctx)
+		return err
+	})
+	go runEvery(jobsCtx, "draft purge", time.Hour, func(ctx context.Context) error {
+		_, err := draftService.PurgeExpiredDrafts(ctx)
+		return err
+	})
+	// uploads never linked to a post are removed once they are a day old
+	go runEvery(jobsCtx, "attachment gc", time.Hour, func(ctx context.Context) error {
+		_, err := attachmentService.CollectGarbage(ctx)
+		return err
+	})
+
 	// Initialize Fiber app
-	app := fiber.New()
+	// the server limit leaves room for the largest attachment plus the multipart envelope;
+	// BodyLimit holds every other route to fiber's default
+	maxUpload := cfg.AttachmentMaxBytes
+	if maxUpload <= 0 {
+		maxUpload = usecases.DefaultAttachmentMaxFileSize
+	}
+	app := fiber.New(fiber.Config{BodyLimit: int(maxUpload) + 1<<20})
 	// Apply CORS before rate limiter so preflight (OPTIONS) get CORS headers
 	app.Use(http.Cors())
+	app.Use(http.BodyLimit(fiber.DefaultBodyLimit))
 	// Apply rate limiter globally (you can scope it per-route as needed)
 	app.Use(http.RateLimiter())
 
@@ -110,7 +260,7 @@ func main() {
 	})
 
 	// Set up routes (router config will use auth middleware where needed)
-	http.SetupRouter(app, userHandler, userService, threadHandler, threadService, voteHandler, replyHandler, reportHandler, authHandler)
+	http.SetupRouter(app, userHandler, userService, threadHandler, threadService, voteHandler, replyHandler, reportHandler, authHandler, searchHandler, tagHandler, revisionHandler, categoryHandler, pollHandler, draftHandler, subscriptionHandler, notificationHandler, bookmarkHandler, reactionHandler, attachmentHandler)
 
 	// Start the server
 	log.Fatal(app.Listen(":3000"))
@@ -29,6 +29,18 @@ type Configuration struct {
 	SMTPUser     string `mapstructure:"SMTP_USER"`
 	SMTPPassword string `mapstructure:"SMTP_PASS"`
 	SMTPFrom     string `mapstructure:"SMTP_FROM"`
+	// DraftRetentionDays is how long a draft is kept after its last autosave (default 30)
+	DraftRetentionDays int `mapstructure:"DRAFT_RETENTION_DAYS"`
+	// ReactionEmojis is the comma-separated reaction set (default 👍,❤️,🎉,😄,😕,👀)
+	ReactionEmojis string `mapstructure:"REACTION_EMOJIS"`
+	// AttachmentDir is where uploaded files are stored (default data/attachments)
+	AttachmentDir string `mapstructure:"ATTACHMENT_DIR"`
+	// AttachmentMaxBytes caps a single upload (default 5 MiB)
+	AttachmentMaxBytes int64 `mapstructure:"ATTACHMENT_MAX_BYTES"`
+	// AttachmentUserQuotaBytes caps the total size of a user's uploads (default 100 MiB)
+	AttachmentUserQuotaBytes int64 `mapstructure:"ATTACHMENT_USER_QUOTA_BYTES"`
+	// AttachmentTypes is the comma-separated MIME allowlist, matched against sniffed content
+	AttachmentTypes string `mapstructure:"ATTACHMENT_TYPES"`
 }
 
 // LoadConfig loads the configuration from a .env file or environment variables.
@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// MaxAttachmentsPerPost caps how many uploads a thread or reply may link.
+const MaxAttachmentsPerPost = 10
+
+// Attachment is an uploaded file. It starts unlinked and is attached to at most one thread
+// or reply by its uploader; uploads that are never linked are garbage-collected.
+type Attachment struct {
+	ID       int    `json:"id"`
+	UserID   int    `json:"user_id"`
+	ThreadID *int   `json:"thread_id,omitempty"`
+	ReplyID  *int   `json:"reply_id,omitempty"`
+	Filename string `json:"filename"`
+	// ContentType is sniffed from the file content; the client's claim is ignored
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// StorageKey locates the content in the blob store
+	StorageKey string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Linked reports whether the attachment belongs to a post.
+func (a *Attachment) Linked() bool {
+	return a.ThreadID != nil || a.ReplyID != nil
+}
@@ -0,0 +1,32 @@
+package entities
+
+import "time"
+
+// MaxBookmarkFolderLength bounds folder names, in bytes.
+const MaxBookmarkFolderLength = 64
+
+// Bookmark is a user's saved thread or reply; exactly one of ThreadID and ReplyID is set.
+// Folder is an optional user-chosen name; empty means unfiled.
+type Bookmark struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"-"`
+	ThreadID  *int      `json:"thread_id,omitempty"`
+	ReplyID   *int      `json:"reply_id,omitempty"`
+	Folder    string    `json:"folder,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Thread is the bookmarked thread, or the thread a bookmarked reply belongs to, with a
+	// preview body; Reply is the bookmarked reply. Both are filled in on list reads.
+	Thread *Thread `json:"thread,omitempty"`
+	Reply  *Reply  `json:"reply,omitempty"`
+}
+
+// BookmarkCursor is the decoded keyset position of the last bookmark on a page.
+type BookmarkCursor struct {
+	ID int `json:"id"`
+}
+
+// BookmarkPage is one page of bookmarks, most recently saved first. NextCursor is empty on the last page.
+type BookmarkPage struct {
+	Bookmarks  []*Bookmark `json:"bookmarks"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxCategorySlugLength bounds category slugs, which appear in URLs.
+const MaxCategorySlugLength = 64
+
+// Category groups threads into a hierarchy of subforums. Every thread belongs to exactly one.
+type Category struct {
+	ID          int    `json:"id"`
+	ParentID    *int   `json:"parent_id,omitempty"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Position orders siblings, lowest first
+	Position    int              `json:"position"`
+	Settings    CategorySettings `json:"settings"`
+	ThreadCount int              `json:"thread_count"`
+	// Children is only populated when categories are returned as a tree
+	Children  []*Category `json:"children,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// CategorySettings control who may post in a category. Admins bypass ReadOnly and MinPostRole.
+type CategorySettings struct {
+	ReadOnly bool `json:"read_only"`
+	// MinPostRole is the lowest role allowed to start threads or reply; empty means any user
+	MinPostRole string `json:"min_post_role,omitempty"`
+	// DefaultTags are added to every new thread in the category
+	DefaultTags []string `json:"default_tags,omitempty"`
+}
+
+// User roles, lowest privilege first.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+var roleRank = map[string]int{RoleUser: 1, RoleAdmin: 2}
+
+// ValidRole reports whether role is one of the known user roles.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAtLeast reports whether role grants at least the privileges of min.
+// Unknown roles rank below every known role.
+func RoleAtLeast(role, min string) bool {
+	return roleRank[role] >= roleRank[min]
+}
+
+// NormalizeSlug lowercases s and joins runs of anything other than letters and digits
+// with a single hyphen, e.g. "Help & Support" becomes "help-support".
+func NormalizeSlug(s string) (string, error) {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	slug := b.String()
+	if slug == "" {
+		return "", errors.New("slug must contain a letter or digit")
+	}
+	if len([]rune(slug)) > MaxCategorySlugLength {
+		return "", errors.New("slug is too long")
+	}
+	return slug, nil
+}
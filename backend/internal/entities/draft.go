@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxDraftBodyLength bounds the size of an autosaved body, in bytes.
+const MaxDraftBodyLength = 100000
+
+// Draft is unpublished text autosaved by a user. Each user has at most one draft per key.
+type Draft struct {
+	UserID    int       `json:"-"`
+	Key       string    `json:"key"`
+	Title     string    `json:"title,omitempty"`
+	Body      string    `json:"body"`
+	Tags      []string  `json:"tags,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ExpiresAt is pushed back by every save; expired drafts are no longer returned
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DraftKind says what a draft will become once posted.
+type DraftKind string
+
+const (
+	DraftNewThread  DraftKind = "thread"
+	DraftReply      DraftKind = "reply"
+	DraftEditThread DraftKind = "edit-thread"
+	DraftEditReply  DraftKind = "edit-reply"
+)
+
+// DraftKey identifies the post a draft belongs to. The string forms are
+//
+//	thread                      a new thread
+//	reply-<thread>              a top-level reply to a thread
+//	reply-<thread>-<parent>     a reply to a reply
+//	edit-thread-<id>            an edit of a thread
+//	edit-reply-<id>             an edit of a reply
+type DraftKey struct {
+	Kind DraftKind
+	// TargetID is the thread replied to, or the thread or reply being edited
+	TargetID int
+	// ParentID is the reply being answered; only set for nested replies
+	ParentID int
+}
+
+var ErrInvalidDraftKey = errors.New("invalid draft key")
+
+// ParseDraftKey parses the string form of a draft key.
+func ParseDraftKey(s string) (DraftKey, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(s)), "-")
+	ids := func(raw []string) ([]int, error) {
+		out := make([]int, len(raw))
+		for i, r := range raw {
+			n, err := strconv.Atoi(r)
+			if err != nil || n <= 0 {
+				return nil, ErrInvalidDraftKey
+			}
+			out[i] = n
+		}
+		return out, nil
+	}
+	switch {
+	case len(parts) == 1 && parts[0] == string(DraftNewThread):
+		return DraftKey{Kind: DraftNewThread}, nil
+	case (len(parts) == 2 || len(parts) == 3) && parts[0] == "reply":
+		n, err := ids(parts[1:])
+		if err != nil {
+			return DraftKey{}, err
+		}
+		k := DraftKey{Kind: DraftReply, TargetID: n[0]}
+		if len(n) == 2 {
+			k.ParentID = n[1]
+		}
+		return k, nil
+	case len(parts) == 3 && parts[0] == "edit" && (parts[1] == "thread" || parts[1] == "reply"):
+		n, err := ids(parts[2:])
+		if err != nil {
+			return DraftKey{}, err
+		}
+		return DraftKey{Kind: DraftKind("edit-" + parts[1]), TargetID: n[0]}, nil
+	}
+	return DraftKey{}, ErrInvalidDraftKey
+}
+
+// String returns the canonical form of k, as accepted by ParseDraftKey.
+func (k DraftKey) String() string {
+	switch k.Kind {
+	case DraftNewThread:
+		return string(DraftNewThread)
+	case DraftReply:
+		if k.ParentID != 0 {
+			return fmt.Sprintf("reply-%d-%d", k.TargetID, k.ParentID)
+		}
+		return fmt.Sprintf("reply-%d", k.TargetID)
+	default:
+		return fmt.Sprintf("%s-%d", k.Kind, k.TargetID)
+	}
+}
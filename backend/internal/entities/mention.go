@@ -0,0 +1,12 @@
+package entities
+
+// Mention is a resolved @username in a post body. Start and End are UTF-8 byte offsets of
+// the whole "@username" token in the Markdown body, End exclusive. They are not string
+// indexes in JavaScript, which counts UTF-16 code units: clients must encode the body to
+// UTF-8 (e.g. with TextEncoder) before slicing by them once it contains non-ASCII text.
+type Mention struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
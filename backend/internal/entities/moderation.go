@@ -0,0 +1,20 @@
+package entities
+
+// ModAction names a moderator operation recorded in the moderation log.
+type ModAction string
+
+const (
+	ModActionMerge ModAction = "merge"
+	ModActionSplit ModAction = "split"
+)
+
+// ThreadSplit moves the reply subtree rooted at ReplyID out of ThreadID into a new thread
+// titled Title, owned by the author of ReplyID. ActorID is the moderator recorded in the
+// log. Body opens the new thread; when empty it points back to the source.
+type ThreadSplit struct {
+	ThreadID int
+	ReplyID  int
+	ActorID  int
+	Title    string
+	Body     string
+}
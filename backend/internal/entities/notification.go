@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+// NotificationKind says what happened. Clients use it together with the actor and thread
+// fields to phrase the notification.
+type NotificationKind string
+
+const (
+	NotifyReplyToThread  NotificationKind = "reply_to_thread"
+	NotifyReplyToReply   NotificationKind = "reply_to_reply"
+	NotifyMention        NotificationKind = "mention"
+	NotifyUpvote         NotificationKind = "upvote"
+	NotifyModAction      NotificationKind = "mod_action"
+	NotifyReportResolved NotificationKind = "report_resolved"
+)
+
+// Notification is one in-app notification for UserID.
+type Notification struct {
+	ID     int              `json:"id"`
+	UserID int              `json:"-"`
+	Kind   NotificationKind `json:"kind"`
+	// ActorID is the user who caused the notification; Actor is their username
+	ActorID *int   `json:"actor_id,omitempty"`
+	Actor   string `json:"actor,omitempty"`
+	// ThreadID and ReplyID point at the post involved; ThreadTitle is filled in on reads
+	ThreadID    *int   `json:"thread_id,omitempty"`
+	ThreadTitle string `json:"thread_title,omitempty"`
+	ReplyID     *int   `json:"reply_id,omitempty"`
+	ReportID    string `json:"report_id,omitempty"`
+	// Message is optional free text, used for moderator actions
+	Message string `json:"message,omitempty"`
+	// DedupeKey, when set, makes the notification one-off: a second one with the same key
+	// for the same user is dropped
+	DedupeKey string    `json:"-"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationCursor is the decoded keyset position of the last notification on a page.
+type NotificationCursor struct {
+	ID int `json:"id"`
+}
+
+// NotificationPage is one page of notifications, newest first. NextCursor is empty on the last page.
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+// Poll limits.
+const (
+	MinPollOptions        = 2
+	MaxPollOptions        = 20
+	MaxPollQuestionLength = 300
+	MaxPollOptionLength   = 200
+)
+
+// Poll is an optional question attached to a thread; a thread has at most one.
+// Ballots are frozen once the poll is closed or its thread is locked.
+type Poll struct {
+	ID       int          `json:"id"`
+	ThreadID int          `json:"thread_id"`
+	Question string       `json:"question"`
+	Options  []PollOption `json:"options"`
+	// MultipleChoice allows a ballot to pick more than one option
+	MultipleChoice bool `json:"multiple_choice"`
+	// ResultsBeforeVote shows tallies to users who have not voted yet while the poll is open
+	ResultsBeforeVote bool       `json:"results_before_vote"`
+	ClosesAt          *time.Time `json:"closes_at,omitempty"`
+	// ClosedAt is set when the poll is closed by hand
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+	// Closed is true once the poll no longer takes ballots, for any reason
+	Closed bool `json:"closed"`
+	// Voters is the number of ballots cast
+	Voters int `json:"voters"`
+	// ResultsHidden is set when tallies were withheld from the caller; Votes and Voters are zero
+	ResultsHidden bool `json:"results_hidden,omitempty"`
+	// MyChoices are the option IDs on the caller's ballot; only set on authenticated reads
+	MyChoices []int     `json:"my_choices,omitempty"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PollOption is one answer of a poll. Votes counts the ballots choosing it.
+type PollOption struct {
+	ID       int    `json:"id"`
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+	Votes    int    `json:"votes"`
+}
+
+// IsClosed reports whether the poll stopped taking ballots at now, by hand or by its close time.
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
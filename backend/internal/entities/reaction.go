@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// DefaultReactionEmojis is the reaction set used when none is configured.
+var DefaultReactionEmojis = []string{"👍", "❤️", "🎉", "😄", "😕", "👀"}
+
+// Reaction is one user's emoji response to a thread or reply; exactly one of ThreadID and
+// ReplyID is set. Unlike a Vote it has no effect on the post's vote counters.
+type Reaction struct {
+	UserID    int       `json:"user_id"`
+	ThreadID  *int      `json:"thread_id,omitempty"`
+	ReplyID   *int      `json:"reply_id,omitempty"`
+	Emoji     string    `json:"emoji"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionCount aggregates one emoji on a post. Reacted says whether the caller added it
+// and is only meaningful on authenticated reads.
+type ReactionCount struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
@@ -7,10 +7,63 @@ type Reply struct {
 	ThreadID int `json:"thread_id"`
 	UserID   int `json:"user_id"`
 	// Author username (denormalized for read responses)
-	Author    string     `json:"author,omitempty"`
-	ParentID  *int       `json:"parent_id,omitempty"`
-	Body      string     `json:"body"`
-	IsDeleted bool       `json:"is_deleted"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt *time.Time `json:"updated_at"`
+	Author   string `json:"author,omitempty"`
+	ParentID *int   `json:"parent_id,omitempty"`
+	Body     string `json:"body"`
+	// BodyHTML is Body rendered from Markdown and sanitized; it is never stored
+	BodyHTML string `json:"body_html,omitempty"`
+	// Mentions are the resolved @usernames in Body, filled in on reads
+	Mentions []Mention `json:"mentions,omitempty"`
+	// AttachmentIDs are uploads to link when the reply is created; reads return Attachments
+	AttachmentIDs []int        `json:"-"`
+	Attachments   []Attachment `json:"attachments,omitempty"`
+	IsDeleted     bool         `json:"is_deleted"`
+	// Upvotes and Downvotes are denormalized counters kept in sync by the vote repository
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+	// IsAccepted marks the thread's accepted answer
+	IsAccepted bool `json:"is_accepted"`
+	// MyVote is the caller's own vote (-1, 0 or 1); it is only set on authenticated reads
+	MyVote *int `json:"my_vote,omitempty"`
+	// Reactions are the emoji reaction counts, most used first; they never affect the vote counters
+	Reactions []ReactionCount `json:"reactions,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt *time.Time      `json:"updated_at"`
+}
+
+// ReplyNode is a reply in a tree view. ChildCount counts all visible direct children, of which
+// Children holds the first page; MoreChildren is the cursor for the rest (empty when all are shown).
+// A deleted reply that still has replies under it is kept as a tombstone with an empty body.
+type ReplyNode struct {
+	Reply
+	ChildCount   int          `json:"child_count"`
+	Children     []*ReplyNode `json:"children,omitempty"`
+	MoreChildren string       `json:"more_children_cursor,omitempty"`
+}
+
+// ReplyTreeOptions bound the size of a reply tree response.
+type ReplyTreeOptions struct {
+	// MaxDepth is the number of reply levels returned below the starting point
+	MaxDepth int
+	// Limit is the page size for the top level; ChildLimit for each node's children
+	Limit      int
+	ChildLimit int
+}
+
+// ReplyCursor is the decoded position within one parent's children. Parent 0 is the thread's
+// top level; a nil At starts from that parent's first child.
+type ReplyCursor struct {
+	Parent int        `json:"p"`
+	ID     int        `json:"id,omitempty"`
+	At     *time.Time `json:"at,omitempty"`
+}
+
+// ReplyTree is one page of a thread's reply tree. NextCursor continues the top level.
+type ReplyTree struct {
+	ThreadID int  `json:"thread_id"`
+	ParentID *int `json:"parent_id,omitempty"`
+	// Accepted repeats the thread's accepted answer ahead of the tree on the first top-level page
+	Accepted   *ReplyNode   `json:"accepted,omitempty"`
+	Replies    []*ReplyNode `json:"replies"`
+	NextCursor string       `json:"next_cursor,omitempty"`
 }
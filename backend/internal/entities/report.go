@@ -5,7 +5,7 @@ import "time"
 type Report struct {
 	ID         string     `json:"id"`
 	ReporterID *int       `json:"reporter_id,omitempty"`
-	Kind       string     `json:"kind"` // 'thread' or 'user'
+	Kind       string     `json:"kind"` // 'thread', 'reply' or 'user'
 	TargetID   int        `json:"target_id"`
 	Reason     string     `json:"reason,omitempty"`
 	Status     string     `json:"status"` // 'open','resolved','dismissed'
@@ -0,0 +1,57 @@
+package entities
+
+import "time"
+
+// RevisionKind says whether a revision belongs to a thread or a reply.
+type RevisionKind string
+
+const (
+	RevisionKindThread RevisionKind = "thread"
+	RevisionKindReply  RevisionKind = "reply"
+)
+
+// EditInfo describes who is editing a post and why; it is recorded on the revision
+// that snapshots the post's previous content.
+type EditInfo struct {
+	EditorID int
+	Reason   string
+}
+
+// Revision is a snapshot of a thread or reply as it was before an edit.
+// Title and Tags are empty for replies.
+type Revision struct {
+	ID         int          `json:"id"`
+	Kind       RevisionKind `json:"kind"`
+	TargetID   int          `json:"target_id"`
+	EditorID   int          `json:"editor_id"`
+	EditorName string       `json:"editor,omitempty"`
+	Reason     string       `json:"reason,omitempty"`
+	Title      string       `json:"title,omitempty"`
+	Body       string       `json:"body"`
+	Tags       []string     `json:"tags,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// DiffOp is the kind of change on a diffed line.
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff compares two versions of a post. From/To are revision ids; 0 means the current version.
+type RevisionDiff struct {
+	From        int        `json:"from"`
+	To          int        `json:"to"`
+	Title       []DiffLine `json:"title,omitempty"`
+	Body        []DiffLine `json:"body"`
+	TagsAdded   []string   `json:"tags_added,omitempty"`
+	TagsRemoved []string   `json:"tags_removed,omitempty"`
+}
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+// SearchKind restricts a search to threads, replies or both.
+type SearchKind string
+
+const (
+	SearchKindAll    SearchKind = "all"
+	SearchKindThread SearchKind = "thread"
+	SearchKindReply  SearchKind = "reply"
+)
+
+// SearchQuery holds full-text search parameters. Tags may name aliases, which match their
+// canonical tag. Offset is set by the service from the cursor.
+type SearchQuery struct {
+	Text   string
+	Kind   SearchKind
+	Tags   []string
+	Author string
+	Limit  int
+	Offset int
+}
+
+// SearchResult is a ranked hit. Reply hits carry ReplyID and link back to their thread via ThreadID.
+type SearchResult struct {
+	Kind      SearchKind `json:"kind"`
+	ThreadID  int        `json:"thread_id"`
+	ReplyID   *int       `json:"reply_id,omitempty"`
+	UserID    int        `json:"user_id"`
+	Author    string     `json:"author,omitempty"`
+	Title     string     `json:"title"`
+	Snippet   string     `json:"snippet"` // HTML-escaped, matches wrapped in <mark>
+	Tags      []string   `json:"tags,omitempty"`
+	Rank      float32    `json:"rank"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+// WatchLevel controls which thread activity a subscriber hears about.
+type WatchLevel string
+
+const (
+	// WatchAll follows every new reply in the thread
+	WatchAll WatchLevel = "all"
+	// WatchRepliesToMe follows only replies to the subscriber's own posts
+	WatchRepliesToMe WatchLevel = "replies-to-me"
+	// WatchMuted silences the thread, and keeps auto-watch from subscribing again
+	WatchMuted WatchLevel = "muted"
+)
+
+// ParseWatchLevel validates a level; an empty string selects WatchAll.
+func ParseWatchLevel(s string) (WatchLevel, bool) {
+	switch WatchLevel(s) {
+	case "", WatchAll:
+		return WatchAll, true
+	case WatchRepliesToMe, WatchMuted:
+		return WatchLevel(s), true
+	default:
+		return "", false
+	}
+}
+
+// ThreadSubscription is one user's watch on one thread.
+type ThreadSubscription struct {
+	UserID   int        `json:"user_id"`
+	ThreadID int        `json:"thread_id"`
+	Level    WatchLevel `json:"level"`
+	// ThreadTitle is filled in when listing a user's subscriptions
+	ThreadTitle string    `json:"thread_title,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WatchCursor is the decoded keyset position of the last subscription on a page.
+type WatchCursor struct {
+	At       time.Time `json:"at"`
+	ThreadID int       `json:"id"`
+}
+
+// WatchingPage is one page of a user's subscriptions, newest first. NextCursor is empty on the last page.
+type WatchingPage struct {
+	Subscriptions []*ThreadSubscription `json:"subscriptions"`
+	NextCursor    string                `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTagLength is the maximum length, in runes, of a normalized tag name.
+	MaxTagLength = 32
+	// MaxTagsPerThread caps how many tags a single thread may carry.
+	MaxTagsPerThread = 8
+)
+
+type Tag struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	ThreadCount int      `json:"thread_count"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+// NormalizeTag turns user input into a canonical tag slug: lowercased, surrounding space trimmed,
+// runs of spaces/underscores/hyphens collapsed to a single hyphen, and anything other than
+// letters, digits and the symbols + # . dropped (so "C++", "C#" and ".NET" survive).
+// Examples: " Go " -> "go", "Machine Learning" -> "machine-learning".
+func NormalizeTag(s string) (string, error) {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.':
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			pendingHyphen = true
+		}
+	}
+	name := b.String()
+	if name == "" {
+		return "", fmt.Errorf("invalid tag: %q", s)
+	}
+	if n := len([]rune(name)); n > MaxTagLength {
+		return "", fmt.Errorf("tag %q is longer than %d characters", name, MaxTagLength)
+	}
+	return name, nil
+}
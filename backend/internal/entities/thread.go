@@ -3,17 +3,157 @@ package entities
 import "time"
 
 type Thread struct {
-	ID     int `json:"id"`
-	UserID int `json:"user_id"`
+	ID         int `json:"id"`
+	UserID     int `json:"user_id"`
+	CategoryID int `json:"category_id"`
 	// Author username (denormalized for read responses)
-	Author    string    `json:"author,omitempty"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	Tags      []string  `json:"tags,omitempty"`
-	IsLocked  bool      `json:"is_locked"`
-	IsDeleted bool      `json:"is_deleted"`
-	Upvotes   int       `json:"upvotes"`
-	Downvotes int       `json:"downvotes"`
+	Author string `json:"author,omitempty"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	// BodyHTML is Body rendered from Markdown and sanitized; it is never stored, and list
+	// pages, whose Body is a truncated preview, leave it out
+	BodyHTML string   `json:"body_html,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Mentions are the resolved @usernames in Body, filled in on reads; list pages keep only
+	// those inside the preview
+	Mentions []Mention `json:"mentions,omitempty"`
+	// AttachmentIDs are uploads to link when the thread is created; reads return Attachments
+	AttachmentIDs []int        `json:"-"`
+	Attachments   []Attachment `json:"attachments,omitempty"`
+	IsLocked      bool         `json:"is_locked"`
+	// Lock metadata, set by the moderator lock endpoint and cleared on unlock
+	LockedBy   *int       `json:"locked_by,omitempty"`
+	LockedAt   *time.Time `json:"locked_at,omitempty"`
+	LockReason string     `json:"lock_reason,omitempty"`
+	IsDeleted  bool       `json:"is_deleted"`
+	// MergedInto is set on a redirect stub left behind when a moderator merged this thread
+	// into another; the stub is locked and keeps no replies
+	MergedInto *int `json:"merged_into,omitempty"`
+	// Pin is set while the thread is pinned; expired pins are never returned
+	Pin       *ThreadPin `json:"pin,omitempty"`
+	Upvotes   int        `json:"upvotes"`
+	Downvotes int        `json:"downvotes"`
+	// MyVote is the caller's own vote (-1, 0 or 1); it is only set on authenticated reads
+	MyVote *int `json:"my_vote,omitempty"`
+	// Bookmarked says whether the caller bookmarked the thread; it is only set on authenticated reads
+	Bookmarked *bool `json:"bookmarked,omitempty"`
+	// Reactions are the emoji reaction counts, most used first; they never affect the vote counters
+	Reactions []ReactionCount `json:"reactions,omitempty"`
+	// AcceptedReplyID is the reply the author (or an admin) marked as the answer; Solved
+	// mirrors whether one is set
+	AcceptedReplyID *int `json:"accepted_reply_id,omitempty"`
+	Solved          bool `json:"solved"`
+	// Status is draft, scheduled or published. Only published threads are listed; unpublished
+	// ones are visible to their author and admins only.
+	Status ThreadStatus `json:"status"`
+	// PublishAt is when a scheduled thread goes live, or when a published one went live
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	// ReplyCount and LastActivityAt are denormalized counters kept in sync by the reply repository
+	ReplyCount     int       `json:"reply_count"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	// Views is the approximate number of unique viewers, flushed periodically from Redis
+	Views     int       `json:"views"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// ThreadStatus is the publishing state of a thread.
+type ThreadStatus string
+
+const (
+	ThreadStatusDraft     ThreadStatus = "draft"
+	ThreadStatusScheduled ThreadStatus = "scheduled"
+	ThreadStatusPublished ThreadStatus = "published"
+)
+
+// ParseThreadStatus validates a status; an empty string selects ThreadStatusPublished.
+func ParseThreadStatus(s string) (ThreadStatus, bool) {
+	switch ThreadStatus(s) {
+	case "", ThreadStatusPublished:
+		return ThreadStatusPublished, true
+	case ThreadStatusDraft, ThreadStatusScheduled:
+		return ThreadStatus(s), true
+	default:
+		return "", false
+	}
+}
+
+// Unpublished reports whether t is still a draft or waiting for its publish time.
+func (t *Thread) Unpublished() bool {
+	return t.Status == ThreadStatusDraft || t.Status == ThreadStatusScheduled
+}
+
+// PinScope says where a pinned thread is kept on top.
+type PinScope string
+
+const (
+	// PinScopeGlobal pins a thread above every listing, including tag listings
+	PinScopeGlobal PinScope = "global"
+	// PinScopeTag pins a thread only in the listing for Tag
+	PinScopeTag PinScope = "tag"
+)
+
+// ThreadPin describes an active pin. ExpiresAt nil means the pin lasts until removed.
+type ThreadPin struct {
+	Scope     PinScope   `json:"scope"`
+	Tag       string     `json:"tag,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	PinnedBy  *int       `json:"pinned_by,omitempty"`
+	PinnedAt  time.Time  `json:"pinned_at"`
+}
+
+// ThreadSort selects the ordering used when listing threads.
+type ThreadSort string
+
+const (
+	ThreadSortNew          ThreadSort = "new"
+	ThreadSortTop          ThreadSort = "top"
+	ThreadSortDiscussed    ThreadSort = "most-discussed"
+	ThreadSortLastActivity ThreadSort = "last-activity"
+	ThreadSortViews        ThreadSort = "most-viewed"
+)
+
+// ParseThreadSort validates a sort mode; an empty string selects ThreadSortNew.
+func ParseThreadSort(s string) (ThreadSort, bool) {
+	switch ThreadSort(s) {
+	case "", ThreadSortNew:
+		return ThreadSortNew, true
+	case ThreadSortTop, ThreadSortDiscussed, ThreadSortLastActivity, ThreadSortViews:
+		return ThreadSort(s), true
+	default:
+		return "", false
+	}
+}
+
+// ThreadCursor is the decoded keyset position of the last thread on a page.
+// Score is used by count-based sorts, At by time-based sorts.
+type ThreadCursor struct {
+	Sort  ThreadSort `json:"s"`
+	ID    int        `json:"id"`
+	Score int        `json:"sc,omitempty"`
+	At    *time.Time `json:"at,omitempty"`
+}
+
+// ThreadListOptions holds the listing parameters passed to the repository.
+type ThreadListOptions struct {
+	Sort  ThreadSort
+	Limit int
+	After *ThreadCursor
+	// Tag restricts the listing to threads carrying this tag; aliases resolve to their tag
+	Tag string
+	// Category is a category slug; the service resolves it to CategoryID, which restricts
+	// the listing to that category and its subcategories
+	Category   string
+	CategoryID int
+	// Solved, when set, keeps only solved (true) or unsolved (false) threads
+	Solved *bool
+	// AuthorID, when set, keeps only threads started by that user
+	AuthorID int
+}
+
+// ThreadPage is one page of a thread listing. NextCursor is empty on the last page.
+// Pinned threads lead the first page and do not count toward the page limit.
+type ThreadPage struct {
+	Threads    []*Thread `json:"threads"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// ThreadActivity holds the inputs of a thread's trending score.
+type ThreadActivity struct {
+	ThreadID  int
+	Upvotes   int
+	Downvotes int
+	// RecentReplies counts live replies posted inside the velocity window
+	RecentReplies int
+	CreatedAt     time.Time
+}
+
+// TrendingQuery asks the database to score and rank threads itself, for when no stored
+// ranking is available. The score matches the one computed from ThreadActivity.
+type TrendingQuery struct {
+	ActiveSince  time.Time
+	RepliesSince time.Time
+	ReplyWeight  float64
+	Gravity      float64
+	// Candidates caps how many of the most recently active threads are ranked
+	Candidates int
+	Offset     int
+	Limit      int
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// ErrAttachmentsUnavailable is returned by CreateThread and CreateReply when an upload in
+// AttachmentIDs was linked elsewhere or collected since it was checked; nothing is saved.
+var ErrAttachmentsUnavailable = errors.New("attachments are no longer available")
+
+type AttachmentRepository interface {
+	// CreateAttachment stores a new unlinked upload unless it would take the uploader's total
+	// over quota bytes, in which case it returns false
+	CreateAttachment(ctx context.Context, a *entities.Attachment, quota int64) (bool, error)
+	// GetAttachment returns nil when the attachment does not exist
+	GetAttachment(ctx context.Context, id int) (*entities.Attachment, error)
+	GetAttachments(ctx context.Context, ids []int) ([]*entities.Attachment, error)
+	GetThreadAttachments(ctx context.Context, threadIDs []int) (map[int][]entities.Attachment, error)
+	GetReplyAttachments(ctx context.Context, replyIDs []int) (map[int][]entities.Attachment, error)
+	// ListUnlinkedAttachments returns up to limit uploads created before cutoff that no post links
+	ListUnlinkedAttachments(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Attachment, error)
+	// DeleteUnlinkedAttachments deletes the uploads among ids that are still unlinked and
+	// returns their storage keys
+	DeleteUnlinkedAttachments(ctx context.Context, ids []int) ([]string, error)
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type BookmarkRepository interface {
+	// SaveBookmark bookmarks b's thread or reply for b.UserID, or moves an existing bookmark
+	// to b.Folder. It sets b.ID and b.CreatedAt.
+	SaveBookmark(ctx context.Context, b *entities.Bookmark) error
+	// DeleteBookmark removes userID's bookmark on the thread or reply; false means there was none
+	DeleteBookmark(ctx context.Context, userID int, threadID, replyID *int) (bool, error)
+	// ListBookmarks returns up to limit of userID's bookmarks with IDs below beforeID (0 starts
+	// at the newest), newest first, with Thread and Reply filled in. folder filters when non-nil.
+	// Bookmarks on deleted posts, on replies in deleted threads and on other users'
+	// unpublished threads are skipped.
+	ListBookmarks(ctx context.Context, userID int, folder *string, beforeID, limit int) ([]*entities.Bookmark, error)
+	// GetBookmarkedThreads returns the subset of threadIDs that userID bookmarked
+	GetBookmarkedThreads(ctx context.Context, userID int, threadIDs []int) (map[int]bool, error)
+}
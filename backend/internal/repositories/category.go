@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type CategoryRepository interface {
+	// ListCategories returns every category flat, ordered by position then name
+	ListCategories(ctx context.Context) ([]*entities.Category, error)
+	// GetCategoryByID and GetCategoryBySlug return nil, nil when the category does not exist
+	GetCategoryByID(ctx context.Context, id int) (*entities.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*entities.Category, error)
+	CreateCategory(ctx context.Context, c *entities.Category) (int, error)
+	UpdateCategory(ctx context.Context, c *entities.Category) error
+	DeleteCategory(ctx context.Context, id int) error
+}
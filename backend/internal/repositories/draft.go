@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type DraftRepository interface {
+	// SaveDraft inserts or replaces the user's draft under d.Key and sets d.UpdatedAt
+	SaveDraft(ctx context.Context, d *entities.Draft) error
+	// ListDrafts returns the user's unexpired drafts, most recently saved first
+	ListDrafts(ctx context.Context, userID int) ([]*entities.Draft, error)
+	// DeleteDraft removes one draft; false means the user had no draft under key
+	DeleteDraft(ctx context.Context, userID int, key string) (bool, error)
+	// DeleteExpiredDrafts removes drafts past their expiry and returns how many were removed
+	DeleteExpiredDrafts(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type MentionRepository interface {
+	// SetMentions replaces the mentions stored for one post, the thread body itself when
+	// replyID is nil
+	SetMentions(ctx context.Context, threadID int, replyID *int, mentions []entities.Mention) error
+	// MarkMentionsNotified records that userIDs were told about their mention in the post and
+	// returns those who had not been told before, so edits never notify anyone twice
+	MarkMentionsNotified(ctx context.Context, threadID int, replyID *int, userIDs []int) ([]int, error)
+	// GetThreadMentions and GetReplyMentions map post ID to its mentions in body order;
+	// posts without mentions are absent
+	GetThreadMentions(ctx context.Context, threadIDs []int) (map[int][]entities.Mention, error)
+	GetReplyMentions(ctx context.Context, replyIDs []int) (map[int][]entities.Mention, error)
+}
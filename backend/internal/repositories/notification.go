@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type NotificationRepository interface {
+	// CreateNotifications stores ns in one statement and sets their IDs and creation times.
+	// Notifications whose DedupeKey the user already has are skipped and keep ID 0.
+	CreateNotifications(ctx context.Context, ns []*entities.Notification) error
+	// ListNotifications returns up to limit of userID's notifications with IDs below beforeID
+	// (0 starts at the newest), newest first
+	ListNotifications(ctx context.Context, userID, beforeID, limit int, unreadOnly bool) ([]*entities.Notification, error)
+	// MarkRead marks the given notifications of userID as read and returns how many were unread
+	MarkRead(ctx context.Context, userID int, ids []int) (int64, error)
+	// MarkAllRead marks every notification of userID as read and returns how many were unread
+	MarkAllRead(ctx context.Context, userID int) (int64, error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+}
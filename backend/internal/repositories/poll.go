@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type PollRepository interface {
+	// CreatePoll stores the poll with its options in order and sets the option IDs. It returns
+	// 0, nil when the thread already has a poll.
+	CreatePoll(ctx context.Context, p *entities.Poll) (int, error)
+	// GetPollByThread returns the poll with per-option tallies, or nil, nil when the thread has none
+	GetPollByThread(ctx context.Context, threadID int) (*entities.Poll, error)
+	// GetBallot returns the option IDs userID chose; empty when the user has not voted
+	GetBallot(ctx context.Context, pollID, userID int) ([]int, error)
+	// CastBallot replaces userID's ballot with optionIDs. It returns false without writing when
+	// the poll is closed or its thread is locked at the time of the write.
+	CastBallot(ctx context.Context, pollID, userID int, optionIDs []int) (bool, error)
+	// ClosePoll closes the poll now; closing an already closed poll is a no-op
+	ClosePoll(ctx context.Context, pollID int) error
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type ReactionRepository interface {
+	// AddReaction stores r; false means the user already reacted to the post with r.Emoji
+	AddReaction(ctx context.Context, r *entities.Reaction) (bool, error)
+	// RemoveReaction deletes userID's emoji reaction on the thread or reply; false means there was none
+	RemoveReaction(ctx context.Context, userID int, threadID, replyID *int, emoji string) (bool, error)
+	// GetThreadReactions and GetReplyReactions map post ID to its reaction counts, most used
+	// first; Reacted is set for viewerID's own reactions (0 for none). Posts without
+	// reactions are absent.
+	GetThreadReactions(ctx context.Context, viewerID int, threadIDs []int) (map[int][]entities.ReactionCount, error)
+	GetReplyReactions(ctx context.Context, viewerID int, replyIDs []int) (map[int][]entities.ReactionCount, error)
+}
@@ -7,9 +7,15 @@ import (
 )
 
 type ReplyRepository interface {
+	// CreateReply stores the reply and links r.AttachmentIDs in the same transaction (see
+	// ErrAttachmentsUnavailable)
 	CreateReply(ctx context.Context, r *entities.Reply) (int, error)
 	GetRepliesByThread(ctx context.Context, threadID int) ([]entities.Reply, error)
 	GetReplyByID(ctx context.Context, id int) (*entities.Reply, error)
+	// ListReplyChildren returns, for each parent in parentIDs (0 is the thread's top level), up to
+	// limit children in creation order with their child counts. after applies to every parent.
+	ListReplyChildren(ctx context.Context, threadID int, parentIDs []int, after *entities.ReplyCursor, limit int) ([]*entities.ReplyNode, error)
 	DeleteReply(ctx context.Context, id int) error
-	UpdateReply(ctx context.Context, r *entities.Reply) error
+	// UpdateReply overwrites a reply body, saving the previous body as a revision when it changes
+	UpdateReply(ctx context.Context, r *entities.Reply, edit entities.EditInfo) error
 }
@@ -9,5 +9,7 @@ import (
 type ReportRepository interface {
 	CreateReport(ctx context.Context, r *entities.Report) (string, error)
 	GetReports(ctx context.Context, kind *string) ([]*entities.Report, error)
+	// GetReportByID returns nil, nil when no report has the id
+	GetReportByID(ctx context.Context, id string) (*entities.Report, error)
 	UpdateReportStatus(ctx context.Context, id string, status string, resolvedBy *int) error
 }
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// RevisionRepository reads edit history. Revisions are written by the thread and reply
+// repositories inside the same transaction as the edit they record.
+type RevisionRepository interface {
+	// ListRevisions returns a post's revisions, newest first
+	ListRevisions(ctx context.Context, kind entities.RevisionKind, targetID int) ([]entities.Revision, error)
+	// GetRevision returns nil, nil when the revision does not exist
+	GetRevision(ctx context.Context, id int) (*entities.Revision, error)
+}
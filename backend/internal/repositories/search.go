@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type SearchRepository interface {
+	// Search returns up to q.Limit hits ordered by rank, skipping q.Offset hits
+	Search(ctx context.Context, q entities.SearchQuery) ([]entities.SearchResult, error)
+}
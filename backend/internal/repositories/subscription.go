@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type SubscriptionRepository interface {
+	// Watch subscribes userID to the thread at level, changing the level of an existing subscription
+	Watch(ctx context.Context, userID, threadID int, level entities.WatchLevel) error
+	// WatchIfAbsent subscribes userID at level unless any subscription, muted included, exists
+	WatchIfAbsent(ctx context.Context, userID, threadID int, level entities.WatchLevel) error
+	// Unwatch removes the subscription; false means there was none
+	Unwatch(ctx context.Context, userID, threadID int) (bool, error)
+	// GetSubscription returns nil, nil when userID does not watch the thread
+	GetSubscription(ctx context.Context, userID, threadID int) (*entities.ThreadSubscription, error)
+	// ListWatching returns up to limit of userID's subscriptions to non-deleted threads after
+	// the cursor, newest first, with thread titles
+	ListWatching(ctx context.Context, userID int, after *entities.WatchCursor, limit int) ([]*entities.ThreadSubscription, error)
+	// ListWatchers returns the thread's subscriptions that are not muted
+	ListWatchers(ctx context.Context, threadID int) ([]*entities.ThreadSubscription, error)
+}
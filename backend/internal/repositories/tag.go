@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+type TagRepository interface {
+	// ListTags returns tags ordered by number of non-deleted threads, most used first
+	ListTags(ctx context.Context, limit int) ([]entities.Tag, error)
+	// GetTagByName looks up a tag by name or alias; returns nil, nil when neither exists
+	GetTagByName(ctx context.Context, name string) (*entities.Tag, error)
+	RenameTag(ctx context.Context, id int, newName string) error
+	// MergeTags moves every thread link from fromID to intoID, turns the old name into an alias
+	// of intoID and deletes fromID, all in one transaction
+	MergeTags(ctx context.Context, fromID, intoID int) error
+	AddAlias(ctx context.Context, tagID int, alias string) error
+	DeleteAlias(ctx context.Context, alias string) error
+	DeleteTag(ctx context.Context, id int) error
+}
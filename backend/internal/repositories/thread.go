@@ -2,14 +2,63 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/nocson47/beaconofknowledge/internal/entities"
 )
 
 type ThreadRepository interface {
+	// CreateThread stores the thread with its tags and links thread.AttachmentIDs in the same
+	// transaction (see ErrAttachmentsUnavailable)
 	CreateThread(ctx context.Context, thread *entities.Thread) (int, error)
 	GetThreadByID(ctx context.Context, id int) (*entities.Thread, error)
-	GetAllThreads(ctx context.Context) ([]*entities.Thread, error)
-	UpdateThread(ctx context.Context, thread *entities.Thread) error
+	// ListThreads returns up to opts.Limit published, non-deleted threads after opts.After in opts.Sort order.
+	// Threads pinned in the listing (see ListPinnedThreads) are left out.
+	ListThreads(ctx context.Context, opts entities.ThreadListOptions) ([]*entities.Thread, error)
+	// UpdateThread overwrites a thread; when title, body or tags change, the previous version
+	// is saved as a revision attributed to edit in the same transaction
+	UpdateThread(ctx context.Context, thread *entities.Thread, edit entities.EditInfo) error
 	DeleteThread(ctx context.Context, id int) error
+	// SetThreadLock locks or unlocks a thread, recording the acting moderator and reason
+	SetThreadLock(ctx context.Context, id int, locked bool, actorID int, reason string) error
+	// ListPinnedThreads returns threads with an unexpired pin for the listing opts describes:
+	// global pins everywhere and tag pins only for their tag (aliases resolve to their tag),
+	// both subject to the category, author and solved filters of opts. Sort, Limit and After are ignored.
+	ListPinnedThreads(ctx context.Context, opts entities.ThreadListOptions) ([]*entities.Thread, error)
+	// SetThreadPin pins a thread, replacing any existing pin; a nil pin unpins it
+	SetThreadPin(ctx context.Context, id int, pin *entities.ThreadPin) error
+	// SetAcceptedReply marks replyID as the thread's answer, or clears it when nil. It returns
+	// false when replyID is not a live reply of the thread.
+	SetAcceptedReply(ctx context.Context, threadID int, replyID *int) (bool, error)
+	// SetThreadPublishing changes the status of an unpublished thread. Publishing makes the
+	// thread go live now. It returns false when the thread is already published.
+	SetThreadPublishing(ctx context.Context, id int, status entities.ThreadStatus, publishAt *time.Time) (bool, error)
+	// PublishDueThreads publishes scheduled threads whose publish time has passed and returns their IDs
+	PublishDueThreads(ctx context.Context) ([]int, error)
+	// ClearExpiredPins removes pins whose expiry has passed and returns the affected thread IDs
+	ClearExpiredPins(ctx context.Context) ([]int, error)
+	// SetViewCounts stores unique-view estimates keyed by thread ID. Counts never go down, so a
+	// stale or reset estimate leaves the stored value alone.
+	SetViewCounts(ctx context.Context, counts map[int]int) error
+	// GetThreadsByIDs returns the published, non-deleted threads among ids with list previews
+	// as bodies, in no particular order
+	GetThreadsByIDs(ctx context.Context, ids []int) ([]*entities.Thread, error)
+	// ListThreadActivity returns up to limit published, non-deleted threads active since
+	// activeSince, counting their replies posted since repliesSince
+	ListThreadActivity(ctx context.Context, activeSince, repliesSince time.Time, limit int) ([]entities.ThreadActivity, error)
+	// RankTrendingThreads scores the threads ListThreadActivity would return and returns one
+	// page of their IDs, best first and newer first on ties
+	RankTrendingThreads(ctx context.Context, q entities.TrendingQuery) ([]int, error)
+	// ListRelatedThreads returns the IDs of up to limit published, non-deleted threads ranked
+	// by shared tags and title similarity to threadID, leaving out threads locked after lockedSince
+	ListRelatedThreads(ctx context.Context, threadID int, lockedSince time.Time, limit int) ([]int, error)
+	// MergeThreads moves the replies and votes of sourceID into targetID, turns the source into
+	// a locked stub pointing at the target and logs the merge, all in one transaction. It
+	// returns false when either thread was merged away in the meantime.
+	MergeThreads(ctx context.Context, sourceID, targetID, actorID int) (bool, error)
+	// SplitThread moves the reply subtree of split into a new thread with the source's category
+	// and tags, owned by the root reply's author, and logs the split, all in one transaction. It
+	// returns the new thread's ID, 0 when the reply is deleted or not in the thread, and false
+	// when the thread was merged away in the meantime.
+	SplitThread(ctx context.Context, split entities.ThreadSplit) (int, bool, error)
 }
@@ -11,6 +11,14 @@ type VoteRepository interface {
 	GetVoteByID(ctx context.Context, id int) (*entities.Vote, error)
 	GetVotesForThread(ctx context.Context, threadID int) ([]entities.Vote, error)
 	GetVotesForReply(ctx context.Context, replyID int) ([]entities.Vote, error)
+	// DeleteVote and RetractVote remove votes and roll back the post counters in the same transaction
 	DeleteVote(ctx context.Context, id int) error
+	// RetractVote removes userID's vote on the thread or reply; false means there was none
+	RetractVote(ctx context.Context, userID int, threadID, replyID *int) (bool, error)
+	// GetUserThreadVotes and GetUserReplyVotes map target ID to userID's vote value;
+	// targets the user has not voted on are absent
+	GetUserThreadVotes(ctx context.Context, userID int, threadIDs []int) (map[int]int, error)
+	GetUserReplyVotes(ctx context.Context, userID int, replyIDs []int) (map[int]int, error)
 	GetVoteCountsForThread(ctx context.Context, threadID int) (up int, down int, err error)
+	GetVoteCountsForReply(ctx context.Context, replyID int) (up int, down int, err error)
 }
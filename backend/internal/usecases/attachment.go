@@ -0,0 +1,340 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+// BlobStore keeps attachment content under opaque keys chosen by the caller.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentLimits bounds uploads. Zero values select the defaults below.
+type AttachmentLimits struct {
+	// MaxFileSize is the largest accepted upload in bytes
+	MaxFileSize int64
+	// UserQuota is the total size of a user's uploads, linked or not
+	UserQuota int64
+	// AllowedTypes are the accepted sniffed MIME types, without parameters
+	AllowedTypes []string
+	// UnlinkedTTL is how long an upload may stay unlinked before it is collected
+	UnlinkedTTL time.Duration
+}
+
+var DefaultAttachmentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"}
+
+const (
+	DefaultAttachmentMaxFileSize = 5 << 20
+	DefaultAttachmentUserQuota   = 100 << 20
+	DefaultAttachmentUnlinkedTTL = 24 * time.Hour
+
+	maxAttachmentFilename = 255
+	attachmentGCBatch     = 200
+)
+
+type AttachmentService interface {
+	// Upload stores a file for userID after checking its sniffed type, its size and the
+	// user's quota. The returned attachment is unlinked until a post references it.
+	Upload(ctx context.Context, userID int, filename string, r io.Reader) (*entities.Attachment, error)
+	// Open returns an attachment and its content. Unlinked uploads are visible to their
+	// uploader only; linked ones follow the visibility of their thread.
+	Open(ctx context.Context, id int) (*entities.Attachment, io.ReadCloser, error)
+	// CollectGarbage deletes uploads left unlinked past the TTL and returns how many went
+	CollectGarbage(ctx context.Context) (int, error)
+}
+
+type attachmentService struct {
+	repo    repositories.AttachmentRepository
+	threads repositories.ThreadRepository
+	replies repositories.ReplyRepository
+	users   repositories.UserRepository
+	blobs   BlobStore
+	limits  AttachmentLimits
+	allowed map[string]bool
+}
+
+func NewAttachmentService(repo repositories.AttachmentRepository, threads repositories.ThreadRepository, replies repositories.ReplyRepository, users repositories.UserRepository, blobs BlobStore, limits AttachmentLimits) AttachmentService {
+	if limits.MaxFileSize <= 0 {
+		limits.MaxFileSize = DefaultAttachmentMaxFileSize
+	}
+	if limits.UserQuota <= 0 {
+		limits.UserQuota = DefaultAttachmentUserQuota
+	}
+	if limits.UnlinkedTTL <= 0 {
+		limits.UnlinkedTTL = DefaultAttachmentUnlinkedTTL
+	}
+	allowed := map[string]bool{}
+	for _, t := range limits.AllowedTypes {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			allowed[t] = true
+		}
+	}
+	if len(allowed) == 0 {
+		for _, t := range DefaultAttachmentTypes {
+			allowed[t] = true
+		}
+	}
+	return &attachmentService{repo: repo, threads: threads, replies: replies, users: users, blobs: blobs, limits: limits, allowed: allowed}
+}
+
+// sniffContentType detects the MIME type from the first bytes of a file, dropping parameters.
+func sniffContentType(head []byte) string {
+	mt, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mt
+}
+
+// cleanFilename keeps the base name of a client-supplied path, capped in length.
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if name == "." || name == "/" {
+		name = ""
+	}
+	for len(name) > maxAttachmentFilename {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
+
+func newStorageKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (s *attachmentService) Upload(ctx context.Context, userID int, filename string, r io.Reader) (*entities.Attachment, error) {
+	if userID == 0 {
+		return nil, forbiddenf("sign in to upload files")
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("read upload: %w", err)
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, invalidf("file is empty")
+	}
+	contentType := sniffContentType(head)
+	if !s.allowed[contentType] {
+		return nil, invalidf("file type %s is not allowed", contentType)
+	}
+
+	key, err := newStorageKey()
+	if err != nil {
+		return nil, fmt.Errorf("storage key: %w", err)
+	}
+	// read one byte past the limit so oversized files are detected whatever the client claimed
+	body := &countingReader{r: io.LimitReader(io.MultiReader(bytes.NewReader(head), r), s.limits.MaxFileSize+1)}
+	if err := s.blobs.Put(ctx, key, body); err != nil {
+		return nil, fmt.Errorf("store upload: %w", err)
+	}
+	discard := func() {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("attachments: delete blob %s: %v", key, err)
+		}
+	}
+	if body.n > s.limits.MaxFileSize {
+		discard()
+		return nil, invalidf("file exceeds %d bytes", s.limits.MaxFileSize)
+	}
+
+	a := &entities.Attachment{UserID: userID, Filename: cleanFilename(filename), ContentType: contentType, Size: body.n, StorageKey: key}
+	ok, err := s.repo.CreateAttachment(ctx, a, s.limits.UserQuota)
+	if err != nil {
+		discard()
+		return nil, fmt.Errorf("create attachment: %w", err)
+	}
+	if !ok {
+		discard()
+		return nil, forbiddenf("upload quota of %d bytes exceeded", s.limits.UserQuota)
+	}
+	return a, nil
+}
+
+func (s *attachmentService) Open(ctx context.Context, id int) (*entities.Attachment, io.ReadCloser, error) {
+	a, err := s.repo.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get attachment: %w", err)
+	}
+	if a == nil || !s.visible(ctx, a) {
+		return nil, nil, notFoundf("attachment not found")
+	}
+	rc, err := s.blobs.Open(ctx, a.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open attachment: %w", err)
+	}
+	return a, rc, nil
+}
+
+// visible applies the thread's visibility to a linked attachment; deleted replies hide theirs.
+func (s *attachmentService) visible(ctx context.Context, a *entities.Attachment) bool {
+	viewer := ViewerID(ctx)
+	if !a.Linked() {
+		return viewer != 0 && viewer == a.UserID
+	}
+	threadID := 0
+	if a.ThreadID != nil {
+		threadID = *a.ThreadID
+	} else {
+		rep, err := s.replies.GetReplyByID(ctx, *a.ReplyID)
+		if err != nil || rep == nil || rep.IsDeleted {
+			return false
+		}
+		threadID = rep.ThreadID
+	}
+	t, err := s.threads.GetThreadByID(ctx, threadID)
+	if err != nil || t == nil || t.IsDeleted {
+		return false
+	}
+	return !t.Unpublished() || canSeeUnpublished(ctx, s.users, t)
+}
+
+func (s *attachmentService) CollectGarbage(ctx context.Context) (int, error) {
+	// created_at is stored without a zone, in UTC
+	cutoff := time.Now().UTC().Add(-s.limits.UnlinkedTTL)
+	collected := 0
+	for {
+		stale, err := s.repo.ListUnlinkedAttachments(ctx, cutoff, attachmentGCBatch)
+		if err != nil {
+			return collected, err
+		}
+		if len(stale) == 0 {
+			return collected, nil
+		}
+		ids := make([]int, len(stale))
+		for i, a := range stale {
+			ids[i] = a.ID
+		}
+		// rows go first so an upload linked meanwhile keeps its blob
+		keys, err := s.repo.DeleteUnlinkedAttachments(ctx, ids)
+		if err != nil {
+			return collected, err
+		}
+		for _, key := range keys {
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				log.Printf("attachments: delete blob %s: %v", key, err)
+			}
+		}
+		collected += len(keys)
+		if len(stale) < attachmentGCBatch {
+			return collected, nil
+		}
+	}
+}
+
+// checkAttachments verifies that ids name at most MaxAttachmentsPerPost distinct unlinked
+// uploads of userID, and returns them deduplicated.
+func checkAttachments(ctx context.Context, repo repositories.AttachmentRepository, userID int, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if repo == nil {
+		return nil, invalidf("attachments are not supported")
+	}
+	seen := map[int]bool{}
+	var unique []int
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > entities.MaxAttachmentsPerPost {
+		return nil, invalidf("at most %d attachments per post", entities.MaxAttachmentsPerPost)
+	}
+	found, err := repo.GetAttachments(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("get attachments: %w", err)
+	}
+	usable := 0
+	for _, a := range found {
+		if a.UserID == userID && !a.Linked() {
+			usable++
+		}
+	}
+	if usable != len(unique) {
+		return nil, invalidf("attachments must be your own uploads that are not yet linked")
+	}
+	return unique, nil
+}
+
+// errAttachmentsTaken is returned when a new post's uploads were linked elsewhere or collected
+// between checkAttachments and the insert.
+var errAttachmentsTaken = conflictf("an attachment was linked elsewhere or expired; upload it again")
+
+// fillThreadAttachments sets Attachments on threads; lookups are best-effort.
+func fillThreadAttachments(ctx context.Context, repo repositories.AttachmentRepository, threads []*entities.Thread) {
+	if repo == nil || len(threads) == 0 {
+		return
+	}
+	ids := make([]int, len(threads))
+	for i, t := range threads {
+		ids[i] = t.ID
+	}
+	byThread, err := repo.GetThreadAttachments(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, t := range threads {
+		t.Attachments = byThread[t.ID]
+	}
+}
+
+// fillReplyAttachments is fillThreadAttachments for replies; deleted replies keep none.
+func fillReplyAttachments(ctx context.Context, repo repositories.AttachmentRepository, replies []*entities.Reply) {
+	if repo == nil || len(replies) == 0 {
+		return
+	}
+	ids := make([]int, len(replies))
+	for i, r := range replies {
+		ids[i] = r.ID
+	}
+	byReply, err := repo.GetReplyAttachments(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, r := range replies {
+		if !r.IsDeleted {
+			r.Attachments = byReply[r.ID]
+		}
+	}
+}
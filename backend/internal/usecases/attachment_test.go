@@ -0,0 +1,284 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+// --- fakes ---
+type fakeAttachmentRepo struct {
+	byID map[int]*entities.Attachment
+	next int
+}
+
+func newFakeAttachmentRepo() *fakeAttachmentRepo {
+	return &fakeAttachmentRepo{byID: map[int]*entities.Attachment{}}
+}
+
+func (f *fakeAttachmentRepo) CreateAttachment(ctx context.Context, a *entities.Attachment, quota int64) (bool, error) {
+	used := a.Size
+	for _, x := range f.byID {
+		if x.UserID == a.UserID {
+			used += x.Size
+		}
+	}
+	if used > quota {
+		return false, nil
+	}
+	f.next++
+	a.ID = f.next
+	a.CreatedAt = time.Now()
+	cp := *a
+	f.byID[a.ID] = &cp
+	return true, nil
+}
+func (f *fakeAttachmentRepo) GetAttachment(ctx context.Context, id int) (*entities.Attachment, error) {
+	return f.byID[id], nil
+}
+func (f *fakeAttachmentRepo) GetAttachments(ctx context.Context, ids []int) ([]*entities.Attachment, error) {
+	var out []*entities.Attachment
+	for _, id := range ids {
+		if a, ok := f.byID[id]; ok {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// link is what the thread and reply fakes do inside CreateThread and CreateReply: all of ids
+// are linked or none.
+func (f *fakeAttachmentRepo) link(userID int, ids []int, threadID, replyID *int) error {
+	for _, id := range ids {
+		if a, ok := f.byID[id]; !ok || a.UserID != userID || a.Linked() {
+			return repositories.ErrAttachmentsUnavailable
+		}
+	}
+	for _, id := range ids {
+		f.byID[id].ThreadID, f.byID[id].ReplyID = threadID, replyID
+	}
+	return nil
+}
+func (f *fakeAttachmentRepo) GetThreadAttachments(ctx context.Context, threadIDs []int) (map[int][]entities.Attachment, error) {
+	out := map[int][]entities.Attachment{}
+	for _, id := range threadIDs {
+		for i := 1; i <= f.next; i++ {
+			if a, ok := f.byID[i]; ok && a.ThreadID != nil && *a.ThreadID == id {
+				out[id] = append(out[id], *a)
+			}
+		}
+	}
+	return out, nil
+}
+func (f *fakeAttachmentRepo) GetReplyAttachments(ctx context.Context, replyIDs []int) (map[int][]entities.Attachment, error) {
+	return map[int][]entities.Attachment{}, nil
+}
+func (f *fakeAttachmentRepo) ListUnlinkedAttachments(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Attachment, error) {
+	var out []*entities.Attachment
+	for i := 1; i <= f.next && len(out) < limit; i++ {
+		if a, ok := f.byID[i]; ok && !a.Linked() && a.CreatedAt.Before(cutoff) {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+func (f *fakeAttachmentRepo) DeleteUnlinkedAttachments(ctx context.Context, ids []int) ([]string, error) {
+	var keys []string
+	for _, id := range ids {
+		if a, ok := f.byID[id]; ok && !a.Linked() {
+			keys = append(keys, a.StorageKey)
+			delete(f.byID, id)
+		}
+	}
+	return keys, nil
+}
+
+type fakeBlobStore struct {
+	blobs map[string][]byte
+}
+
+func newFakeBlobStore() *fakeBlobStore {
+	return &fakeBlobStore{blobs: map[string][]byte{}}
+}
+
+func (f *fakeBlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	f.blobs[key] = b
+	return nil
+}
+func (f *fakeBlobStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	b, ok := f.blobs[key]
+	if !ok {
+		return nil, errors.New("no such blob")
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+func (f *fakeBlobStore) Delete(ctx context.Context, key string) error {
+	delete(f.blobs, key)
+	return nil
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+// --- tests ---
+func TestAttachments_UploadChecksTypeSizeAndQuota(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeAttachmentRepo()
+	blobs := newFakeBlobStore()
+	svc := NewAttachmentService(repo, newFakeThreadRepo(), newFakeReplyRepo(), nil, blobs, AttachmentLimits{
+		MaxFileSize: 64, UserQuota: 100, AllowedTypes: []string{"image/png", " text/plain "},
+	})
+
+	a, err := svc.Upload(ctx, 1, "../../etc/shot.png", bytes.NewReader(append(pngHeader, make([]byte, 40)...)))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if a.ContentType != "image/png" || a.Size != 48 || a.Filename != "shot.png" || a.Linked() {
+		t.Fatalf("unexpected attachment %+v", a)
+	}
+	if len(blobs.blobs[a.StorageKey]) != 48 {
+		t.Fatalf("expected the content stored under %q", a.StorageKey)
+	}
+
+	// the sniffed type decides, not the file name
+	if _, err := svc.Upload(ctx, 1, "page.png", strings.NewReader("<html><script>alert(1)</script></html>")); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for html, got %v", err)
+	}
+	if _, err := svc.Upload(ctx, 1, "big.txt", strings.NewReader(strings.Repeat("a", 65))); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput over the file limit, got %v", err)
+	}
+	if _, err := svc.Upload(ctx, 1, "more.txt", strings.NewReader(strings.Repeat("a", 60))); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden over the user quota, got %v", err)
+	}
+	if _, err := svc.Upload(ctx, 2, "other.txt", strings.NewReader(strings.Repeat("a", 60))); err != nil {
+		t.Fatalf("quota is per user: %v", err)
+	}
+	if len(blobs.blobs) != 2 {
+		t.Fatalf("rejected uploads must not leave blobs behind, have %d", len(blobs.blobs))
+	}
+}
+
+func TestAttachments_LinkedFromThreadsAndCollected(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	cats := newFakeCategoryRepo()
+	catID, _ := cats.CreateCategory(ctx, &entities.Category{Slug: "general"})
+	users := newFakeUserRepo()
+	users.users[3] = &entities.User{ID: 3, Role: entities.RoleAdmin}
+	repo := newFakeAttachmentRepo()
+	threads.attachments = repo
+	blobs := newFakeBlobStore()
+	svc := NewAttachmentService(repo, threads, newFakeReplyRepo(), users, blobs, AttachmentLimits{})
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Categories: cats, Users: users, Attachments: repo})
+
+	mine, _ := svc.Upload(ctx, 1, "a.txt", strings.NewReader("hello"))
+	stale, _ := svc.Upload(ctx, 1, "b.txt", strings.NewReader("forgotten"))
+	theirs, _ := svc.Upload(ctx, 2, "c.txt", strings.NewReader("not yours"))
+
+	if _, err := threadSvc.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: catID, Title: "t", Body: "b", AttachmentIDs: []int{mine.ID, theirs.ID}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput linking another user's upload, got %v", err)
+	}
+	id, err := threadSvc.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: catID, Title: "t", Body: "b", AttachmentIDs: []int{mine.ID, mine.ID}})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	th, err := threadSvc.GetThreadByID(ctx, id)
+	if err != nil || len(th.Attachments) != 1 || th.Attachments[0].ID != mine.ID {
+		t.Fatalf("expected the upload on the thread, got %+v (%v)", th, err)
+	}
+	if _, err := threadSvc.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: catID, Title: "t", Body: "b", AttachmentIDs: []int{mine.ID}}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput reusing a linked upload, got %v", err)
+	}
+
+	// unlinked uploads are private; linked ones follow the thread
+	if _, _, err := svc.Open(WithViewer(ctx, 1), theirs.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for someone else's unlinked upload, got %v", err)
+	}
+	if _, rc, err := svc.Open(ctx, mine.ID); err != nil {
+		t.Fatalf("open linked upload anonymously: %v", err)
+	} else {
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(b) != "hello" {
+			t.Fatalf("unexpected content %q", b)
+		}
+	}
+	draft, _ := svc.Upload(ctx, 1, "d.txt", strings.NewReader("draft"))
+	if _, err := threadSvc.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: catID, Title: "t", Body: "b", Status: entities.ThreadStatusDraft, AttachmentIDs: []int{draft.ID}}); err != nil {
+		t.Fatalf("create draft: %v", err)
+	}
+	if _, _, err := svc.Open(WithViewer(ctx, 2), draft.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a draft's upload, got %v", err)
+	}
+	for _, viewer := range []int{1, 3} {
+		if _, rc, err := svc.Open(WithViewer(ctx, viewer), draft.ID); err != nil {
+			t.Fatalf("expected the author and admins to open a draft's upload, viewer %d: %v", viewer, err)
+		} else {
+			rc.Close()
+		}
+	}
+
+	if n, err := svc.CollectGarbage(ctx); err != nil || n != 0 {
+		t.Fatalf("fresh uploads must survive gc, collected %d (%v)", n, err)
+	}
+	for _, a := range repo.byID {
+		a.CreatedAt = a.CreatedAt.Add(-2 * DefaultAttachmentUnlinkedTTL)
+	}
+	if n, err := svc.CollectGarbage(ctx); err != nil || n != 2 {
+		t.Fatalf("expected the 2 unlinked uploads collected, got %d (%v)", n, err)
+	}
+	if repo.byID[stale.ID] != nil || blobs.blobs[stale.StorageKey] != nil {
+		t.Fatal("expected the stale upload and its blob gone")
+	}
+	if repo.byID[mine.ID] == nil || blobs.blobs[mine.StorageKey] == nil {
+		t.Fatal("linked uploads must be kept")
+	}
+}
+
+// staleAttachmentRepo reports every upload as unlinked, as a check racing another post would see it
+type staleAttachmentRepo struct{ *fakeAttachmentRepo }
+
+func (r staleAttachmentRepo) GetAttachments(ctx context.Context, ids []int) ([]*entities.Attachment, error) {
+	found, _ := r.fakeAttachmentRepo.GetAttachments(ctx, ids)
+	out := make([]*entities.Attachment, len(found))
+	for i, a := range found {
+		cp := *a
+		cp.ThreadID, cp.ReplyID = nil, nil
+		out[i] = &cp
+	}
+	return out, nil
+}
+
+func TestAttachments_LinkRaceFailsThePost(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b"})
+	replies := newFakeReplyRepo()
+	repo := newFakeAttachmentRepo()
+	replies.attachments = repo
+	svc := NewAttachmentService(repo, threads, replies, nil, newFakeBlobStore(), AttachmentLimits{})
+	replySvc := NewReplyService(ReplyServiceDeps{Repo: replies, Threads: threads, Categories: newFakeCategoryRepo(), Users: newFakeUserRepo(), Attachments: staleAttachmentRepo{repo}})
+
+	a, _ := svc.Upload(ctx, 1, "a.txt", strings.NewReader("hello"))
+	b, _ := svc.Upload(ctx, 1, "b.txt", strings.NewReader("world"))
+	if _, err := replySvc.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 1, Body: "first", AttachmentIDs: []int{a.ID}}); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	// a was taken by the first reply after the second one checked it
+	if _, err := replySvc.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 1, Body: "second", AttachmentIDs: []int{b.ID, a.ID}}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict when an upload is taken meanwhile, got %v", err)
+	}
+	if len(replies.replies) != 1 || repo.byID[b.ID].Linked() {
+		t.Fatalf("expected neither the reply nor its other upload to be saved, have %d replies", len(replies.replies))
+	}
+}
@@ -0,0 +1,142 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+const (
+	DefaultBookmarkPageSize = 20
+	MaxBookmarkPageSize     = 100
+)
+
+type BookmarkService interface {
+	// AddBookmark saves b.UserID's bookmark on b's thread or reply in b.Folder; bookmarking
+	// the same post again moves it to the new folder
+	AddBookmark(ctx context.Context, b *entities.Bookmark) error
+	// RemoveBookmark deletes b.UserID's bookmark on b's thread or reply; b.Folder is ignored
+	RemoveBookmark(ctx context.Context, b *entities.Bookmark) error
+	// ListBookmarks returns one page of userID's bookmarks, optionally limited to one folder
+	// ("" is the unfiled folder); bookmarks on deleted posts are skipped
+	ListBookmarks(ctx context.Context, userID int, folder *string, limit int, cursor string) (*entities.BookmarkPage, error)
+}
+
+type bookmarkService struct {
+	repo    repositories.BookmarkRepository
+	threads repositories.ThreadRepository
+	replies repositories.ReplyRepository
+	users   repositories.UserRepository
+}
+
+func NewBookmarkService(repo repositories.BookmarkRepository, threads repositories.ThreadRepository, replies repositories.ReplyRepository, users repositories.UserRepository) BookmarkService {
+	return &bookmarkService{repo: repo, threads: threads, replies: replies, users: users}
+}
+
+func checkBookmarkTarget(b *entities.Bookmark) error {
+	if b == nil {
+		return invalidf("bookmark is nil")
+	}
+	if (b.ThreadID == nil) == (b.ReplyID == nil) {
+		return invalidf("exactly one of thread_id or reply_id is required")
+	}
+	return nil
+}
+
+func (s *bookmarkService) AddBookmark(ctx context.Context, b *entities.Bookmark) error {
+	if err := checkBookmarkTarget(b); err != nil {
+		return err
+	}
+	b.Folder = strings.TrimSpace(b.Folder)
+	if len(b.Folder) > entities.MaxBookmarkFolderLength {
+		return invalidf("folder must be at most %d bytes", entities.MaxBookmarkFolderLength)
+	}
+	threadID := 0
+	if b.ThreadID != nil {
+		threadID = *b.ThreadID
+	} else {
+		rep, err := s.replies.GetReplyByID(ctx, *b.ReplyID)
+		if err != nil || rep == nil || rep.IsDeleted {
+			return notFoundf("reply not found")
+		}
+		threadID = rep.ThreadID
+	}
+	// anything b.UserID can read can be bookmarked, including drafts for admins
+	if _, err := visibleThread(WithViewer(ctx, b.UserID), s.threads, s.users, threadID); err != nil {
+		return err
+	}
+	if err := s.repo.SaveBookmark(ctx, b); err != nil {
+		return fmt.Errorf("save bookmark: %w", err)
+	}
+	return nil
+}
+
+func (s *bookmarkService) RemoveBookmark(ctx context.Context, b *entities.Bookmark) error {
+	if err := checkBookmarkTarget(b); err != nil {
+		return err
+	}
+	ok, err := s.repo.DeleteBookmark(ctx, b.UserID, b.ThreadID, b.ReplyID)
+	if err != nil {
+		return fmt.Errorf("delete bookmark: %w", err)
+	}
+	if !ok {
+		return notFoundf("bookmark not found")
+	}
+	return nil
+}
+
+func (s *bookmarkService) ListBookmarks(ctx context.Context, userID int, folder *string, limit int, cursor string) (*entities.BookmarkPage, error) {
+	limit = clampInt(limit, DefaultBookmarkPageSize, MaxBookmarkPageSize)
+	var before entities.BookmarkCursor
+	if cursor != "" {
+		if err := decodeCursor(cursor, &before); err != nil {
+			return nil, err
+		}
+		if before.ID <= 0 {
+			return nil, ErrInvalidCursor
+		}
+	}
+	if folder != nil {
+		f := strings.TrimSpace(*folder)
+		folder = &f
+	}
+	bms, err := s.repo.ListBookmarks(ctx, userID, folder, before.ID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list bookmarks: %w", err)
+	}
+	page := &entities.BookmarkPage{Bookmarks: bms}
+	if len(bms) > limit {
+		page.Bookmarks = bms[:limit]
+		if page.NextCursor, err = encodeCursor(entities.BookmarkCursor{ID: bms[limit-1].ID}); err != nil {
+			return nil, fmt.Errorf("encode cursor: %w", err)
+		}
+	}
+	if page.Bookmarks == nil {
+		page.Bookmarks = []*entities.Bookmark{}
+	}
+	return page, nil
+}
+
+// fillThreadBookmarks sets Bookmarked on threads for the request's viewer. Like
+// fillThreadVotes it is best-effort and leaves the flag nil on anonymous requests.
+func fillThreadBookmarks(ctx context.Context, bookmarks repositories.BookmarkRepository, threads []*entities.Thread) {
+	viewer := ViewerID(ctx)
+	if viewer == 0 || bookmarks == nil || len(threads) == 0 {
+		return
+	}
+	ids := make([]int, len(threads))
+	for i, t := range threads {
+		ids[i] = t.ID
+	}
+	saved, err := bookmarks.GetBookmarkedThreads(ctx, viewer, ids)
+	if err != nil {
+		return
+	}
+	for _, t := range threads {
+		b := saved[t.ID]
+		t.Bookmarked = &b
+	}
+}
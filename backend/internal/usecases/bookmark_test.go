@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+// fakeBookmarkRepo hides bookmarks on deleted posts like the Postgres query does.
+type fakeBookmarkRepo struct {
+	bookmarks []*entities.Bookmark
+	threads   *fakeThreadRepo
+	replies   *fakeReplyRepo
+}
+
+func sameTarget(b *entities.Bookmark, threadID, replyID *int) bool {
+	if threadID != nil {
+		return b.ThreadID != nil && *b.ThreadID == *threadID
+	}
+	return b.ReplyID != nil && *b.ReplyID == *replyID
+}
+
+func (f *fakeBookmarkRepo) SaveBookmark(ctx context.Context, b *entities.Bookmark) error {
+	for _, x := range f.bookmarks {
+		if x.UserID == b.UserID && sameTarget(x, b.ThreadID, b.ReplyID) {
+			x.Folder = b.Folder
+			b.ID = x.ID
+			return nil
+		}
+	}
+	b.ID = len(f.bookmarks) + 1
+	f.bookmarks = append(f.bookmarks, b)
+	return nil
+}
+func (f *fakeBookmarkRepo) DeleteBookmark(ctx context.Context, userID int, threadID, replyID *int) (bool, error) {
+	for i, x := range f.bookmarks {
+		if x.UserID == userID && sameTarget(x, threadID, replyID) {
+			f.bookmarks = append(f.bookmarks[:i], f.bookmarks[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (f *fakeBookmarkRepo) ListBookmarks(ctx context.Context, userID int, folder *string, beforeID, limit int) ([]*entities.Bookmark, error) {
+	var out []*entities.Bookmark
+	for _, x := range f.bookmarks {
+		if x.UserID != userID || (folder != nil && x.Folder != *folder) || (beforeID > 0 && x.ID >= beforeID) {
+			continue
+		}
+		threadID := 0
+		if x.ThreadID != nil {
+			threadID = *x.ThreadID
+		} else {
+			rep := f.replies.replies[*x.ReplyID]
+			if rep.IsDeleted {
+				continue
+			}
+			threadID = rep.ThreadID
+		}
+		if f.threads.threads[threadID].IsDeleted {
+			continue
+		}
+		out = append(out, x)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+func (f *fakeBookmarkRepo) GetBookmarkedThreads(ctx context.Context, userID int, threadIDs []int) (map[int]bool, error) {
+	out := map[int]bool{}
+	for _, x := range f.bookmarks {
+		if x.UserID == userID && x.ThreadID != nil {
+			out[*x.ThreadID] = true
+		}
+	}
+	return out, nil
+}
+
+// --- tests ---
+func TestBookmarks_FoldersFlagAndDeletedPosts(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	replies := newFakeReplyRepo()
+	repo := &fakeBookmarkRepo{threads: threads, replies: replies}
+	svc := NewBookmarkService(repo, threads, replies, newFakeUserRepo())
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Bookmarks: repo})
+
+	first, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "a", Body: "b"})
+	second, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "c", Body: "d"})
+	replyID, _ := replies.CreateReply(ctx, &entities.Reply{ThreadID: first, UserID: 2, Body: "r"})
+
+	for _, b := range []*entities.Bookmark{
+		{UserID: 7, ThreadID: &first, Folder: "later"},
+		{UserID: 7, ThreadID: &second},
+		{UserID: 7, ReplyID: &replyID, Folder: " later "},
+		{UserID: 7, ThreadID: &second, Folder: "later"}, // moves the existing bookmark
+	} {
+		if err := svc.AddBookmark(ctx, b); err != nil {
+			t.Fatalf("add bookmark: %v", err)
+		}
+	}
+	if err := svc.AddBookmark(ctx, &entities.Bookmark{UserID: 7, ThreadID: &first, ReplyID: &replyID}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for two targets, got %v", err)
+	}
+
+	later := "later"
+	page, err := svc.ListBookmarks(ctx, 7, &later, 2, "")
+	if err != nil || len(page.Bookmarks) != 2 || page.NextCursor == "" {
+		t.Fatalf("expected a first page of 2 with a cursor, got %+v (%v)", page, err)
+	}
+	if page.Bookmarks[0].ReplyID == nil || *page.Bookmarks[0].ReplyID != replyID {
+		t.Fatalf("expected the reply bookmark first, got %+v", page.Bookmarks[0])
+	}
+
+	th := threads.threads[first]
+	threadSvc.ApplyViewerState(WithViewer(ctx, 7), th)
+	if th.Bookmarked == nil || !*th.Bookmarked {
+		t.Fatalf("expected bookmarked to be set for the viewer, got %v", th.Bookmarked)
+	}
+	threadSvc.ApplyViewerState(WithViewer(ctx, 8), th)
+	if th.Bookmarked == nil || *th.Bookmarked {
+		t.Fatalf("expected another user's bookmark not to count, got %v", th.Bookmarked)
+	}
+
+	threads.threads[first].IsDeleted = true
+	page, err = svc.ListBookmarks(ctx, 7, nil, 10, "")
+	if err != nil || len(page.Bookmarks) != 1 || *page.Bookmarks[0].ThreadID != second {
+		t.Fatalf("expected bookmarks in the deleted thread to be skipped, got %+v (%v)", page, err)
+	}
+
+	if err := svc.RemoveBookmark(ctx, &entities.Bookmark{UserID: 7, ThreadID: &second}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := svc.RemoveBookmark(ctx, &entities.Bookmark{UserID: 7, ThreadID: &second}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound removing twice, got %v", err)
+	}
+}
+
+func TestBookmarks_DraftsFollowReadRule(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	replies := newFakeReplyRepo()
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleUser}
+	users.users[3] = &entities.User{ID: 3, Role: entities.RoleAdmin}
+	svc := NewBookmarkService(&fakeBookmarkRepo{threads: threads, replies: replies}, threads, replies, users)
+	draft, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b", Status: entities.ThreadStatusDraft})
+
+	for _, uid := range []int{1, 3} {
+		if err := svc.AddBookmark(ctx, &entities.Bookmark{UserID: uid, ThreadID: &draft}); err != nil {
+			t.Fatalf("user %d bookmarking a draft they can read: %v", uid, err)
+		}
+	}
+	if err := svc.AddBookmark(ctx, &entities.Bookmark{UserID: 2, ThreadID: &draft}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another user's draft, got %v", err)
+	}
+}
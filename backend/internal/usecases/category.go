@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+// CategoryService manages the category hierarchy. Mutations are admin-only at the router.
+type CategoryService interface {
+	// ListCategories returns the root categories with their subcategories nested under Children
+	ListCategories(ctx context.Context) ([]*entities.Category, error)
+	GetCategory(ctx context.Context, slug string) (*entities.Category, error)
+	CreateCategory(ctx context.Context, c *entities.Category) (int, error)
+	UpdateCategory(ctx context.Context, c *entities.Category) error
+	// DeleteCategory refuses categories that still hold threads or subcategories
+	DeleteCategory(ctx context.Context, id int) error
+}
+
+type categoryService struct {
+	repo repositories.CategoryRepository
+}
+
+func NewCategoryService(repo repositories.CategoryRepository) CategoryService {
+	return &categoryService{repo: repo}
+}
+
+func (s *categoryService) ListCategories(ctx context.Context) ([]*entities.Category, error) {
+	cats, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list categories: %w", err)
+	}
+	// cats is already in sibling order, so appending keeps children ordered too
+	byID := make(map[int]*entities.Category, len(cats))
+	for _, c := range cats {
+		byID[c.ID] = c
+	}
+	roots := []*entities.Category{}
+	for _, c := range cats {
+		if c.ParentID != nil {
+			if parent, ok := byID[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+	return roots, nil
+}
+
+func (s *categoryService) GetCategory(ctx context.Context, slug string) (*entities.Category, error) {
+	c, err := s.repo.GetCategoryBySlug(ctx, strings.ToLower(strings.TrimSpace(slug)))
+	if err != nil {
+		return nil, fmt.Errorf("get category: %w", err)
+	}
+	if c == nil {
+		return nil, notFoundf("category %q not found", slug)
+	}
+	return c, nil
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, c *entities.Category) (int, error) {
+	if c == nil {
+		return 0, invalidf("category is nil")
+	}
+	if err := s.validate(ctx, c); err != nil {
+		return 0, err
+	}
+	id, err := s.repo.CreateCategory(ctx, c)
+	if err != nil {
+		return 0, fmt.Errorf("create category: %w", err)
+	}
+	return id, nil
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, c *entities.Category) error {
+	if c == nil {
+		return invalidf("category is nil")
+	}
+	existing, err := s.repo.GetCategoryByID(ctx, c.ID)
+	if err != nil {
+		return fmt.Errorf("get category: %w", err)
+	}
+	if existing == nil {
+		return notFoundf("category not found")
+	}
+	if err := s.validate(ctx, c); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateCategory(ctx, c); err != nil {
+		return fmt.Errorf("update category: %w", err)
+	}
+	return nil
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, id int) error {
+	c, err := s.repo.GetCategoryByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get category: %w", err)
+	}
+	if c == nil {
+		return notFoundf("category not found")
+	}
+	if c.ThreadCount > 0 {
+		return conflictf("category %q still has %d threads", c.Slug, c.ThreadCount)
+	}
+	cats, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("list categories: %w", err)
+	}
+	for _, other := range cats {
+		if other.ParentID != nil && *other.ParentID == id {
+			return conflictf("category %q still has subcategories", c.Slug)
+		}
+	}
+	if err := s.repo.DeleteCategory(ctx, id); err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+	return nil
+}
+
+// validate normalizes c in place and checks slug uniqueness, the parent link and settings.
+// c.ID is zero for new categories.
+func (s *categoryService) validate(ctx context.Context, c *entities.Category) error {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+	if c.Name == "" {
+		return invalidf("name is required")
+	}
+	raw := c.Slug
+	if strings.TrimSpace(raw) == "" {
+		raw = c.Name
+	}
+	slug, err := entities.NormalizeSlug(raw)
+	if err != nil {
+		return invalidf("%s", err.Error())
+	}
+	c.Slug = slug
+	if other, err := s.repo.GetCategoryBySlug(ctx, slug); err != nil {
+		return fmt.Errorf("get category: %w", err)
+	} else if other != nil && other.ID != c.ID {
+		return conflictf("category %q already exists", slug)
+	}
+
+	if c.ParentID != nil {
+		if err := s.checkParent(ctx, c.ID, *c.ParentID); err != nil {
+			return err
+		}
+	}
+
+	if c.Settings.MinPostRole != "" && !entities.ValidRole(c.Settings.MinPostRole) {
+		return invalidf("unknown role %q", c.Settings.MinPostRole)
+	}
+	tags, err := normalizeTags(c.Settings.DefaultTags)
+	if err != nil {
+		return err
+	}
+	c.Settings.DefaultTags = tags
+	return nil
+}
+
+// checkParent rejects a parent that does not exist or that would put id inside its own subtree.
+func (s *categoryService) checkParent(ctx context.Context, id, parentID int) error {
+	cats, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("list categories: %w", err)
+	}
+	byID := make(map[int]*entities.Category, len(cats))
+	for _, c := range cats {
+		byID[c.ID] = c
+	}
+	if byID[parentID] == nil {
+		return invalidf("parent category not found")
+	}
+	// walk up from the new parent; reaching id means a cycle. The hop limit guards
+	// against a cycle that is already stored.
+	for cur, hops := byID[parentID], 0; cur != nil && hops <= len(cats); hops++ {
+		if id != 0 && cur.ID == id {
+			return invalidf("a category cannot be nested under itself")
+		}
+		if cur.ParentID == nil {
+			break
+		}
+		cur = byID[*cur.ParentID]
+	}
+	return nil
+}
+
+// checkCanPost enforces a category's read-only and minimum-role settings for userID.
+// Admins may always post.
+func checkCanPost(ctx context.Context, users repositories.UserRepository, cat *entities.Category, userID int) error {
+	if !cat.Settings.ReadOnly && cat.Settings.MinPostRole == "" {
+		return nil
+	}
+	u, err := users.GetUserByID(ctx, userID)
+	if err != nil || u == nil {
+		return forbiddenf("unknown user")
+	}
+	if u.Role == entities.RoleAdmin {
+		return nil
+	}
+	if cat.Settings.ReadOnly {
+		return forbiddenf("category %q is read-only", cat.Slug)
+	}
+	if !entities.RoleAtLeast(u.Role, cat.Settings.MinPostRole) {
+		return forbiddenf("posting in %q requires the %s role", cat.Slug, cat.Settings.MinPostRole)
+	}
+	return nil
+}
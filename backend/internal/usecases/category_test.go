@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeCategoryRepo struct {
+	cats map[int]*entities.Category
+	next int
+}
+
+func newFakeCategoryRepo() *fakeCategoryRepo {
+	return &fakeCategoryRepo{cats: map[int]*entities.Category{}}
+}
+
+func (f *fakeCategoryRepo) ListCategories(ctx context.Context) ([]*entities.Category, error) {
+	var out []*entities.Category
+	for id := 1; id <= f.next; id++ {
+		if c, ok := f.cats[id]; ok {
+			cp := *c
+			cp.Children = nil
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+func (f *fakeCategoryRepo) GetCategoryByID(ctx context.Context, id int) (*entities.Category, error) {
+	return f.cats[id], nil
+}
+func (f *fakeCategoryRepo) GetCategoryBySlug(ctx context.Context, slug string) (*entities.Category, error) {
+	for _, c := range f.cats {
+		if c.Slug == slug {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+func (f *fakeCategoryRepo) CreateCategory(ctx context.Context, c *entities.Category) (int, error) {
+	f.next++
+	c.ID = f.next
+	cp := *c
+	f.cats[c.ID] = &cp
+	return c.ID, nil
+}
+func (f *fakeCategoryRepo) UpdateCategory(ctx context.Context, c *entities.Category) error {
+	cp := *c
+	f.cats[c.ID] = &cp
+	return nil
+}
+func (f *fakeCategoryRepo) DeleteCategory(ctx context.Context, id int) error {
+	delete(f.cats, id)
+	return nil
+}
+
+// --- tests ---
+func TestCategoryService_TreeAndCycles(t *testing.T) {
+	ctx := context.Background()
+	svc := NewCategoryService(newFakeCategoryRepo())
+
+	root, err := svc.CreateCategory(ctx, &entities.Category{Name: "Help & Support"})
+	if err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	child, err := svc.CreateCategory(ctx, &entities.Category{Name: "Go", ParentID: &root})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if _, err := svc.CreateCategory(ctx, &entities.Category{Name: "help support"}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict for duplicate slug, got %v", err)
+	}
+
+	tree, err := svc.ListCategories(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(tree) != 1 || tree[0].Slug != "help-support" || len(tree[0].Children) != 1 || tree[0].Children[0].ID != child {
+		t.Fatalf("unexpected tree: %+v", tree)
+	}
+
+	// moving the root under its own child would create a cycle
+	err = svc.UpdateCategory(ctx, &entities.Category{ID: root, Name: "Help & Support", ParentID: &child})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a cycle, got %v", err)
+	}
+	if err := svc.DeleteCategory(ctx, root); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict deleting a parent, got %v", err)
+	}
+	if err := svc.DeleteCategory(ctx, child); err != nil {
+		t.Fatalf("delete leaf: %v", err)
+	}
+}
+
+func TestCheckCanPost(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleAdmin}
+
+	readOnly := &entities.Category{Slug: "news", Settings: entities.CategorySettings{ReadOnly: true}}
+	staff := &entities.Category{Slug: "staff", Settings: entities.CategorySettings{MinPostRole: entities.RoleAdmin}}
+	open := &entities.Category{Slug: "general"}
+
+	for _, tc := range []struct {
+		cat     *entities.Category
+		userID  int
+		allowed bool
+	}{
+		{open, 1, true},
+		{readOnly, 1, false},
+		{readOnly, 2, true},
+		{staff, 1, false},
+		{staff, 2, true},
+	} {
+		err := checkCanPost(ctx, users, tc.cat, tc.userID)
+		if tc.allowed && err != nil {
+			t.Fatalf("user %d in %s: unexpected %v", tc.userID, tc.cat.Slug, err)
+		}
+		if !tc.allowed && !errors.Is(err, ErrForbidden) {
+			t.Fatalf("user %d in %s: expected ErrForbidden, got %v", tc.userID, tc.cat.Slug, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"log"
+	"strings"
+)
+
+// MarkdownParser converts a post body (CommonMark + GFM) into HTML. Output is untrusted
+// until it has passed through an HTMLSanitizer.
+type MarkdownParser interface {
+	ToHTML(markdown string) (string, error)
+}
+
+// HTMLSanitizer strips everything outside an allowlist of elements and attributes.
+type HTMLSanitizer interface {
+	Sanitize(html string) string
+}
+
+// RenderCache stores rendered HTML by content key. Implementations must treat failures as misses.
+type RenderCache interface {
+	Get(ctx context.Context, key string) (string, bool)
+	Set(ctx context.Context, key string, html string)
+}
+
+// renderVersion is part of the cache key; bump it when the parser options or allowlist change
+// so stale HTML is not served.
+const renderVersion = "v1"
+
+// ContentPipeline renders post bodies to sanitized HTML: parse, sanitize, then cache by a
+// hash of the source so identical bodies are rendered once. A nil pipeline renders nothing,
+// which keeps services usable in tests without wiring adapters.
+type ContentPipeline struct {
+	parser    MarkdownParser
+	sanitizer HTMLSanitizer
+	cache     RenderCache // optional
+}
+
+func NewContentPipeline(parser MarkdownParser, sanitizer HTMLSanitizer, cache RenderCache) *ContentPipeline {
+	return &ContentPipeline{parser: parser, sanitizer: sanitizer, cache: cache}
+}
+
+// Render returns sanitized HTML for body. It never fails: if parsing errors, the body is
+// returned escaped inside a paragraph.
+func (p *ContentPipeline) Render(ctx context.Context, body string) string {
+	if p == nil || body == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(body))
+	key := renderVersion + ":" + hex.EncodeToString(sum[:])
+	if p.cache != nil {
+		if out, ok := p.cache.Get(ctx, key); ok {
+			return out
+		}
+	}
+	raw, err := p.parser.ToHTML(body)
+	if err != nil {
+		log.Printf("ContentPipeline: markdown render failed: %v", err)
+		return "<p>" + strings.ReplaceAll(html.EscapeString(body), "\n", "<br>") + "</p>"
+	}
+	out := p.sanitizer.Sanitize(raw)
+	if p.cache != nil {
+		p.cache.Set(ctx, key, out)
+	}
+	return out
+}
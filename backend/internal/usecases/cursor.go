@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// ErrInvalidCursor is returned when a client supplies a cursor that cannot be decoded
+// or that was issued for a different listing. It matches ErrInvalidInput.
+var ErrInvalidCursor = invalidf("invalid cursor")
+
+// encodeCursor serializes a keyset position into an opaque, URL-safe token.
+func encodeCursor(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor is the inverse of encodeCursor.
+func decodeCursor(token string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
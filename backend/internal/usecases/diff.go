@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// maxDiffLines bounds the LCS table (lines x lines) so a huge post cannot exhaust memory;
+// beyond it the diff degrades to "delete everything, insert everything".
+const maxDiffLines = 2000
+
+// diffLines returns a line-based diff turning a into b, computed from the longest common subsequence.
+func diffLines(a, b string) []entities.DiffLine {
+	if a == b {
+		if a == "" {
+			return []entities.DiffLine{}
+		}
+		out := []entities.DiffLine{}
+		for _, l := range strings.Split(a, "\n") {
+			out = append(out, entities.DiffLine{Op: entities.DiffEqual, Text: l})
+		}
+		return out
+	}
+	al, bl := splitLines(a), splitLines(b)
+	if len(al) > maxDiffLines || len(bl) > maxDiffLines {
+		out := make([]entities.DiffLine, 0, len(al)+len(bl))
+		for _, l := range al {
+			out = append(out, entities.DiffLine{Op: entities.DiffDelete, Text: l})
+		}
+		for _, l := range bl {
+			out = append(out, entities.DiffLine{Op: entities.DiffInsert, Text: l})
+		}
+		return out
+	}
+
+	// lcs[i][j] is the LCS length of al[i:] and bl[j:]
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	out := make([]entities.DiffLine, 0, len(al)+len(bl))
+	i, j := 0, 0
+	for i < len(al) && j < len(bl) {
+		switch {
+		case al[i] == bl[j]:
+			out = append(out, entities.DiffLine{Op: entities.DiffEqual, Text: al[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, entities.DiffLine{Op: entities.DiffDelete, Text: al[i]})
+			i++
+		default:
+			out = append(out, entities.DiffLine{Op: entities.DiffInsert, Text: bl[j]})
+			j++
+		}
+	}
+	for ; i < len(al); i++ {
+		out = append(out, entities.DiffLine{Op: entities.DiffDelete, Text: al[i]})
+	}
+	for ; j < len(bl); j++ {
+		out = append(out, entities.DiffLine{Op: entities.DiffInsert, Text: bl[j]})
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffTags reports which tags appear only in b (added) and only in a (removed).
+func diffTags(a, b []string) (added, removed []string) {
+	inA := make(map[string]bool, len(a))
+	for _, t := range a {
+		inA[t] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, t := range b {
+		inB[t] = true
+		if !inA[t] {
+			added = append(added, t)
+		}
+	}
+	for _, t := range a {
+		if !inB[t] {
+			removed = append(removed, t)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,29 @@
+package usecases
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc", "a\nx\nc\nd")
+	want := []entities.DiffLine{
+		{Op: entities.DiffEqual, Text: "a"},
+		{Op: entities.DiffDelete, Text: "b"},
+		{Op: entities.DiffInsert, Text: "x"},
+		{Op: entities.DiffEqual, Text: "c"},
+		{Op: entities.DiffInsert, Text: "d"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffLines = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffTags(t *testing.T) {
+	added, removed := diffTags([]string{"go", "sql"}, []string{"go", "redis"})
+	if !reflect.DeepEqual(added, []string{"redis"}) || !reflect.DeepEqual(removed, []string{"sql"}) {
+		t.Fatalf("diffTags = +%v -%v", added, removed)
+	}
+}
@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+// DefaultDraftRetention is used when no retention period is configured.
+const DefaultDraftRetention = 30 * 24 * time.Hour
+
+type DraftService interface {
+	// SaveDraft stores d under d.Key for userID, replacing any earlier draft with that key.
+	// The key is canonicalized and its target thread or reply must exist.
+	SaveDraft(ctx context.Context, userID int, d *entities.Draft) error
+	ListDrafts(ctx context.Context, userID int) ([]*entities.Draft, error)
+	DeleteDraft(ctx context.Context, userID int, key string) error
+	// PurgeExpiredDrafts deletes drafts past their retention period
+	PurgeExpiredDrafts(ctx context.Context) (int64, error)
+}
+
+type draftService struct {
+	repo      repositories.DraftRepository
+	threads   repositories.ThreadRepository
+	replies   repositories.ReplyRepository
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewDraftService keeps each draft for retention after its last save; zero selects DefaultDraftRetention.
+func NewDraftService(repo repositories.DraftRepository, threads repositories.ThreadRepository, replies repositories.ReplyRepository, retention time.Duration) DraftService {
+	if retention <= 0 {
+		retention = DefaultDraftRetention
+	}
+	return &draftService{repo: repo, threads: threads, replies: replies, retention: retention, now: time.Now}
+}
+
+func (s *draftService) SaveDraft(ctx context.Context, userID int, d *entities.Draft) error {
+	if d == nil {
+		return invalidf("draft is nil")
+	}
+	key, err := entities.ParseDraftKey(d.Key)
+	if err != nil {
+		return invalidf("invalid draft key %q", d.Key)
+	}
+	if err := s.checkTarget(ctx, key); err != nil {
+		return err
+	}
+	if len(d.Body) > entities.MaxDraftBodyLength {
+		return invalidf("draft is longer than %d bytes", entities.MaxDraftBodyLength)
+	}
+	var tags []string
+	for _, t := range d.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > entities.MaxTagsPerThread {
+		return invalidf("at most %d tags are allowed", entities.MaxTagsPerThread)
+	}
+	d.UserID = userID
+	d.Key = key.String()
+	d.Tags = tags
+	// timestamps are stored without a zone, in UTC
+	d.ExpiresAt = s.now().Add(s.retention).UTC()
+	if err := s.repo.SaveDraft(ctx, d); err != nil {
+		return fmt.Errorf("save draft: %w", err)
+	}
+	return nil
+}
+
+// checkTarget verifies that the thread or reply a draft key points at still exists.
+func (s *draftService) checkTarget(ctx context.Context, key entities.DraftKey) error {
+	switch key.Kind {
+	case entities.DraftReply, entities.DraftEditThread:
+		t, err := s.threads.GetThreadByID(ctx, key.TargetID)
+		if err != nil || t == nil || t.IsDeleted {
+			return notFoundf("thread %d not found", key.TargetID)
+		}
+		if key.ParentID != 0 {
+			parent, err := s.replies.GetReplyByID(ctx, key.ParentID)
+			if err != nil || parent == nil || parent.ThreadID != key.TargetID {
+				return notFoundf("reply %d not found in thread %d", key.ParentID, key.TargetID)
+			}
+		}
+	case entities.DraftEditReply:
+		r, err := s.replies.GetReplyByID(ctx, key.TargetID)
+		if err != nil || r == nil || r.IsDeleted {
+			return notFoundf("reply %d not found", key.TargetID)
+		}
+	}
+	return nil
+}
+
+func (s *draftService) ListDrafts(ctx context.Context, userID int) ([]*entities.Draft, error) {
+	drafts, err := s.repo.ListDrafts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+func (s *draftService) DeleteDraft(ctx context.Context, userID int, key string) error {
+	k, err := entities.ParseDraftKey(key)
+	if err != nil {
+		return invalidf("invalid draft key %q", key)
+	}
+	ok, err := s.repo.DeleteDraft(ctx, userID, k.String())
+	if err != nil {
+		return fmt.Errorf("delete draft: %w", err)
+	}
+	if !ok {
+		return notFoundf("draft %q not found", k.String())
+	}
+	return nil
+}
+
+func (s *draftService) PurgeExpiredDrafts(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpiredDrafts(ctx)
+}
@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeDraftRepo struct {
+	drafts map[int]map[string]*entities.Draft
+}
+
+func newFakeDraftRepo() *fakeDraftRepo {
+	return &fakeDraftRepo{drafts: map[int]map[string]*entities.Draft{}}
+}
+
+func (f *fakeDraftRepo) SaveDraft(ctx context.Context, d *entities.Draft) error {
+	if f.drafts[d.UserID] == nil {
+		f.drafts[d.UserID] = map[string]*entities.Draft{}
+	}
+	cp := *d
+	f.drafts[d.UserID][d.Key] = &cp
+	return nil
+}
+func (f *fakeDraftRepo) ListDrafts(ctx context.Context, userID int) ([]*entities.Draft, error) {
+	var out []*entities.Draft
+	for _, d := range f.drafts[userID] {
+		out = append(out, d)
+	}
+	return out, nil
+}
+func (f *fakeDraftRepo) DeleteDraft(ctx context.Context, userID int, key string) (bool, error) {
+	_, ok := f.drafts[userID][key]
+	delete(f.drafts[userID], key)
+	return ok, nil
+}
+func (f *fakeDraftRepo) DeleteExpiredDrafts(ctx context.Context) (int64, error) { return 0, nil }
+
+// --- tests ---
+func TestParseDraftKey(t *testing.T) {
+	for in, want := range map[string]string{
+		"thread":        "thread",
+		"reply-4":       "reply-4",
+		"Reply-4-9":     "reply-4-9",
+		"edit-thread-3": "edit-thread-3",
+		"edit-reply-7":  "edit-reply-7",
+	} {
+		k, err := entities.ParseDraftKey(in)
+		if err != nil || k.String() != want {
+			t.Fatalf("ParseDraftKey(%q) = %q, %v; want %q", in, k.String(), err, want)
+		}
+	}
+	for _, in := range []string{"", "thread-1", "reply", "reply-0", "reply-1-2-3", "edit-user-1", "edit-reply-x"} {
+		if _, err := entities.ParseDraftKey(in); err == nil {
+			t.Fatalf("ParseDraftKey(%q) should fail", in)
+		}
+	}
+}
+
+func TestDraftService_SaveAndDelete(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{Title: "t", Body: "b"})
+	repo := newFakeDraftRepo()
+	svc := NewDraftService(repo, threads, newFakeReplyRepo(), 48*time.Hour).(*draftService)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	if err := svc.SaveDraft(ctx, 1, &entities.Draft{Key: "reply-999", Body: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing thread, got %v", err)
+	}
+	d := &entities.Draft{Key: fmt.Sprintf("REPLY-%d", threadID), Body: "half-written"}
+	if err := svc.SaveDraft(ctx, 1, d); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	key := fmt.Sprintf("reply-%d", threadID)
+	if d.Key != key || !d.ExpiresAt.Equal(now.Add(48*time.Hour)) {
+		t.Fatalf("expected canonical key and 48h expiry, got %q %v", d.Key, d.ExpiresAt)
+	}
+	// drafts are scoped to their owner
+	if err := svc.DeleteDraft(ctx, 2, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting another user's draft, got %v", err)
+	}
+	if err := svc.DeleteDraft(ctx, 1, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
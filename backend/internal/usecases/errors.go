@@ -0,0 +1,39 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds returned by services so adapters can map failures without matching on message text.
+// Match with errors.Is; the message of the concrete error is safe to show to clients.
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+)
+
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+func invalidf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrInvalidInput, msg: fmt.Sprintf(format, args...)}
+}
+
+func notFoundf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+func forbiddenf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrForbidden, msg: fmt.Sprintf(format, args...)}
+}
+
+func conflictf(format string, args ...interface{}) error {
+	return &kindError{kind: ErrConflict, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,230 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+const (
+	// MaxMentionsPerPost caps the distinct @names looked up in one post; later names stay plain text
+	MaxMentionsPerPost = 10
+	maxMentionLength   = 32
+)
+
+// mentionCandidate is an unresolved @name and the byte range of its token in the body.
+type mentionCandidate struct {
+	name       string
+	start, end int
+}
+
+func isMentionChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// parseMentions finds @name tokens in a Markdown body. An @ only starts a mention at the
+// beginning of the body or after a character that cannot be part of a name, so email
+// addresses are skipped; so is anything inside code spans and fenced code blocks.
+// Trailing dots and dashes are treated as punctuation.
+func parseMentions(body string) []mentionCandidate {
+	code := codeRanges(body)
+	var out []mentionCandidate
+	for i := 0; i < len(body); i++ {
+		if body[i] != '@' || (i > 0 && (isMentionChar(body[i-1]) || body[i-1] == '@' || body[i-1] == '`')) {
+			continue
+		}
+		j := i + 1
+		for j < len(body) && isMentionChar(body[j]) {
+			j++
+		}
+		end := j
+		for end > i+1 && (body[end-1] == '.' || body[end-1] == '-') {
+			end--
+		}
+		name := body[i+1 : end]
+		if name != "" && len(name) <= maxMentionLength && !inRanges(code, i) {
+			out = append(out, mentionCandidate{name: name, start: i, end: end})
+		}
+		i = j - 1
+	}
+	return out
+}
+
+// codeRanges returns the byte ranges of fenced code blocks and inline code spans.
+func codeRanges(body string) [][2]int {
+	var out [][2]int
+	fence := -1
+	for pos := 0; pos < len(body); {
+		lineEnd := strings.IndexByte(body[pos:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(body)
+		} else {
+			lineEnd += pos + 1
+		}
+		line := strings.TrimSpace(body[pos:lineEnd])
+		switch {
+		case strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~"):
+			if fence < 0 {
+				fence = pos
+			} else {
+				out = append(out, [2]int{fence, lineEnd})
+				fence = -1
+			}
+		case fence < 0:
+			// inline spans: pair up backticks within the line
+			open := -1
+			for k := pos; k < lineEnd; k++ {
+				if body[k] != '`' {
+					continue
+				}
+				if open < 0 {
+					open = k
+				} else {
+					out = append(out, [2]int{open, k + 1})
+					open = -1
+				}
+			}
+		}
+		pos = lineEnd
+	}
+	if fence >= 0 {
+		// an unclosed fence runs to the end of the body, as in CommonMark
+		out = append(out, [2]int{fence, len(body)})
+	}
+	return out
+}
+
+func inRanges(ranges [][2]int, i int) bool {
+	for _, r := range ranges {
+		if r[0] <= i && i < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveMentions looks up the first MaxMentionsPerPost distinct names in body and returns
+// a Mention for every occurrence of a name that belongs to a user.
+func resolveMentions(ctx context.Context, users repositories.UserRepository, body string) []entities.Mention {
+	resolved := map[string]*entities.User{}
+	var out []entities.Mention
+	for _, c := range parseMentions(body) {
+		u, seen := resolved[c.name]
+		if !seen {
+			if len(resolved) >= MaxMentionsPerPost {
+				continue
+			}
+			var err error
+			if u, err = users.GetUserByUsername(ctx, c.name); err != nil {
+				log.Printf("resolve mention @%s: %v", c.name, err)
+				u = nil
+			}
+			resolved[c.name] = u
+		}
+		if u != nil {
+			out = append(out, entities.Mention{UserID: u.ID, Username: u.Username, Start: c.start, End: c.end})
+		}
+	}
+	return out
+}
+
+// recordMentions stores the mentions in a post's body, replacing earlier ones, and, when
+// notifyNew is set, notifies mentioned users who were never notified about this post.
+// actorID is the post's author, whoever made the edit. A nil replyID means the thread body.
+// It is best-effort like autoWatch: failures are logged and never fail the post.
+func recordMentions(ctx context.Context, users repositories.UserRepository, mentions repositories.MentionRepository, notifier Notifier,
+	actorID, threadID int, replyID *int, body string, notifyNew bool) {
+	if users == nil || mentions == nil {
+		return
+	}
+	resolved := resolveMentions(ctx, users, body)
+	if err := mentions.SetMentions(ctx, threadID, replyID, resolved); err != nil {
+		log.Printf("record mentions in thread %d: %v", threadID, err)
+		return
+	}
+	if notifyNew {
+		notifyMentioned(ctx, mentions, notifier, actorID, threadID, replyID, resolved)
+	}
+}
+
+// notifyMentioned notifies the users in ms who have not yet been notified about the post.
+func notifyMentioned(ctx context.Context, mentions repositories.MentionRepository, notifier Notifier, actorID, threadID int, replyID *int, ms []entities.Mention) {
+	if notifier == nil {
+		return
+	}
+	seen := map[int]bool{}
+	var userIDs []int
+	for _, m := range ms {
+		if !seen[m.UserID] && m.UserID != actorID {
+			seen[m.UserID] = true
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+	fresh, err := mentions.MarkMentionsNotified(ctx, threadID, replyID, userIDs)
+	if err != nil {
+		log.Printf("mark mentions notified in thread %d: %v", threadID, err)
+		return
+	}
+	notify(ctx, notifier, mentionNotifications(actorID, threadID, replyID, fresh)...)
+}
+
+func mentionNotifications(actorID, threadID int, replyID *int, userIDs []int) []*entities.Notification {
+	out := make([]*entities.Notification, 0, len(userIDs))
+	for _, uid := range userIDs {
+		out = append(out, &entities.Notification{UserID: uid, Kind: entities.NotifyMention, ActorID: &actorID, ThreadID: &threadID, ReplyID: replyID})
+	}
+	return out
+}
+
+// fillThreadMentions sets Mentions on threads. It is best-effort: on lookup failure the
+// threads are returned without them.
+func fillThreadMentions(ctx context.Context, mentions repositories.MentionRepository, threads []*entities.Thread) {
+	if mentions == nil || len(threads) == 0 {
+		return
+	}
+	ids := make([]int, len(threads))
+	for i, t := range threads {
+		ids[i] = t.ID
+	}
+	byThread, err := mentions.GetThreadMentions(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, t := range threads {
+		t.Mentions = byThread[t.ID]
+	}
+}
+
+// mentionsWithin keeps the mentions that end within the first n bytes of their body. The
+// mentions are in body order, so the result is a prefix.
+func mentionsWithin(ms []entities.Mention, n int) []entities.Mention {
+	for i, m := range ms {
+		if m.End > n {
+			return ms[:i]
+		}
+	}
+	return ms
+}
+
+// fillReplyMentions is fillThreadMentions for replies; deleted replies keep none.
+func fillReplyMentions(ctx context.Context, mentions repositories.MentionRepository, replies []*entities.Reply) {
+	if mentions == nil || len(replies) == 0 {
+		return
+	}
+	ids := make([]int, len(replies))
+	for i, r := range replies {
+		ids[i] = r.ID
+	}
+	byReply, err := mentions.GetReplyMentions(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, r := range replies {
+		if !r.IsDeleted {
+			r.Mentions = byReply[r.ID]
+		}
+	}
+}
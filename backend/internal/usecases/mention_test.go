@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeMentionRepo struct {
+	posts    map[string][]entities.Mention // "t<thread>" or "r<reply>"
+	notified map[string]map[int]bool
+}
+
+func newFakeMentionRepo() *fakeMentionRepo {
+	return &fakeMentionRepo{posts: map[string][]entities.Mention{}, notified: map[string]map[int]bool{}}
+}
+
+func mentionKey(threadID int, replyID *int) string {
+	if replyID == nil {
+		return fmt.Sprintf("t%d", threadID)
+	}
+	return fmt.Sprintf("r%d", *replyID)
+}
+
+func (f *fakeMentionRepo) SetMentions(ctx context.Context, threadID int, replyID *int, mentions []entities.Mention) error {
+	f.posts[mentionKey(threadID, replyID)] = mentions
+	return nil
+}
+func (f *fakeMentionRepo) MarkMentionsNotified(ctx context.Context, threadID int, replyID *int, userIDs []int) ([]int, error) {
+	key := mentionKey(threadID, replyID)
+	if f.notified[key] == nil {
+		f.notified[key] = map[int]bool{}
+	}
+	var fresh []int
+	for _, uid := range userIDs {
+		if !f.notified[key][uid] {
+			f.notified[key][uid] = true
+			fresh = append(fresh, uid)
+		}
+	}
+	return fresh, nil
+}
+func (f *fakeMentionRepo) GetThreadMentions(ctx context.Context, threadIDs []int) (map[int][]entities.Mention, error) {
+	out := map[int][]entities.Mention{}
+	for _, id := range threadIDs {
+		if ms := f.posts[mentionKey(id, nil)]; len(ms) > 0 {
+			out[id] = ms
+		}
+	}
+	return out, nil
+}
+func (f *fakeMentionRepo) GetReplyMentions(ctx context.Context, replyIDs []int) (map[int][]entities.Mention, error) {
+	out := map[int][]entities.Mention{}
+	for _, id := range replyIDs {
+		id := id
+		if ms := f.posts[mentionKey(0, &id)]; len(ms) > 0 {
+			out[id] = ms
+		}
+	}
+	return out, nil
+}
+
+// --- tests ---
+func TestParseMentions(t *testing.T) {
+	body := "hey @bob, mail alice@example.com or ask @carol.\n`@dave` is code\n```\n@erin\n```\n@@frank @g-"
+	var got []string
+	for _, c := range parseMentions(body) {
+		if body[c.start:c.end] != "@"+c.name {
+			t.Fatalf("offsets %d-%d do not cover @%s", c.start, c.end, c.name)
+		}
+		got = append(got, c.name)
+	}
+	if strings.Join(got, ",") != "bob,carol,g" {
+		t.Fatalf("expected bob,carol,g, got %v", got)
+	}
+}
+
+func TestMentions_NotifyOnlyNewlyAdded(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	cats := newFakeCategoryRepo()
+	catID, _ := cats.CreateCategory(ctx, &entities.Category{Slug: "general"})
+	users := newFakeUserRepo()
+	for id, name := range map[int]string{1: "alice", 2: "bob", 3: "carol"} {
+		users.users[id] = &entities.User{ID: id, Username: name}
+	}
+	replies := newFakeReplyRepo()
+	mentions := newFakeMentionRepo()
+	notes := &fakeNotificationRepo{}
+	notifier := NewNotificationService(notes, nil)
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Categories: cats, Users: users, Mentions: mentions, Notifier: notifier})
+	replySvc := NewReplyService(ReplyServiceDeps{Repo: replies, Threads: threads, Categories: cats, Users: users, Mentions: mentions, Notifier: notifier})
+
+	threadID, err := threadSvc.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: catID, Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	replyID, err := replySvc.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 1, Body: "cc @bob and @nobody"})
+	if err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if len(notes.ns) != 1 || notes.ns[0].UserID != 2 || notes.ns[0].Kind != entities.NotifyMention {
+		t.Fatalf("expected one mention notification for bob, got %+v", notes.ns)
+	}
+
+	edited := &entities.Reply{ID: replyID, Body: "cc @bob, @carol and @alice"}
+	if err := replySvc.UpdateReply(ctx, edited, entities.EditInfo{EditorID: 1}, false); err != nil {
+		t.Fatalf("update reply: %v", err)
+	}
+	if len(notes.ns) != 2 || notes.ns[1].UserID != 3 {
+		t.Fatalf("expected only carol to be notified on edit, got %d notifications", len(notes.ns))
+	}
+
+	// dropping a mention and adding it back does not notify again, and an admin's edit is
+	// still attributed to the author
+	users.users[4] = &entities.User{ID: 4, Username: "admin", Role: entities.RoleAdmin}
+	users.users[5] = &entities.User{ID: 5, Username: "dave"}
+	for _, body := range []string{"cc @bob", "cc @bob and @carol", "cc @carol and @dave"} {
+		if err := replySvc.UpdateReply(ctx, &entities.Reply{ID: replyID, Body: body}, entities.EditInfo{EditorID: 4}, true); err != nil {
+			t.Fatalf("update reply: %v", err)
+		}
+	}
+	if len(notes.ns) != 3 || notes.ns[2].UserID != 5 || *notes.ns[2].ActorID != 1 {
+		t.Fatalf("expected only dave notified, by the author, got %d notifications", len(notes.ns))
+	}
+	if err := replySvc.UpdateReply(ctx, &entities.Reply{ID: replyID, Body: "cc @bob, @carol and @alice"}, entities.EditInfo{EditorID: 1}, false); err != nil {
+		t.Fatalf("update reply: %v", err)
+	}
+
+	rep, err := replySvc.GetReplyByID(ctx, replyID)
+	if err != nil {
+		t.Fatalf("get reply: %v", err)
+	}
+	if len(rep.Mentions) != 3 || rep.Mentions[1].Username != "carol" || rep.Mentions[1].UserID != 3 {
+		t.Fatalf("expected three structured mentions, got %+v", rep.Mentions)
+	}
+
+	// only the first MaxMentionsPerPost distinct names are looked up
+	var b strings.Builder
+	for i := 0; i < MaxMentionsPerPost; i++ {
+		fmt.Fprintf(&b, "@ghost%d ", i)
+	}
+	b.WriteString("@bob @carol")
+	if got := resolveMentions(ctx, users, b.String()); len(got) != 0 {
+		t.Fatalf("expected names past the cap to be ignored, got %+v", got)
+	}
+}
+
+func TestMentions_ClippedToListPreviews(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	mentions := newFakeMentionRepo()
+	// the stored body stands in for the preview Postgres cuts off after listBodyPreview
+	id, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "hi @bob"})
+	mentions.SetMentions(ctx, id, nil, []entities.Mention{{UserID: 2, Username: "bob", Start: 3, End: 7}, {UserID: 3, Username: "carol", Start: 300, End: 306}})
+	svc := NewThreadService(ThreadServiceDeps{Repo: threads, Mentions: mentions})
+
+	page, err := svc.ListThreads(ctx, entities.ThreadListOptions{}, "")
+	if err != nil || len(page.Threads) != 1 || len(page.Threads[0].Mentions) != 1 || page.Threads[0].Mentions[0].Username != "bob" {
+		t.Fatalf("expected only the mention inside the preview, got %+v (%v)", page, err)
+	}
+	th, err := svc.GetThreadByID(ctx, id)
+	if err != nil || len(th.Mentions) != 2 {
+		t.Fatalf("expected every mention on the full thread, got %+v (%v)", th, err)
+	}
+}
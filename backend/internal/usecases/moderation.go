@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// liveThread loads a published, non-deleted thread that has not been merged away.
+func (s *threadService) liveThread(ctx context.Context, id int) (*entities.Thread, error) {
+	t, err := s.repo.GetThreadByID(ctx, id)
+	if err != nil || t == nil || t.IsDeleted || t.Unpublished() {
+		return nil, notFoundf("thread %d not found", id)
+	}
+	if t.MergedInto != nil {
+		return nil, conflictf("thread %d was merged into thread %d", id, *t.MergedInto)
+	}
+	return t, nil
+}
+
+func (s *threadService) MergeThread(ctx context.Context, sourceID, targetID, actorID int) error {
+	if sourceID == targetID {
+		return invalidf("a thread cannot be merged into itself")
+	}
+	source, err := s.liveThread(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.liveThread(ctx, targetID); err != nil {
+		return err
+	}
+	ok, err := s.repo.MergeThreads(ctx, sourceID, targetID, actorID)
+	if err != nil {
+		return fmt.Errorf("merge threads: %w", err)
+	}
+	if !ok {
+		return conflictf("thread was merged concurrently")
+	}
+	// the merged thread must drop out of every cached list
+	if s.related != nil {
+		s.related.InvalidateAll(ctx)
+	}
+	if source.UserID != actorID {
+		notify(ctx, s.notifier, &entities.Notification{
+			UserID: source.UserID, Kind: entities.NotifyModAction, ActorID: &actorID, ThreadID: &targetID,
+			Message: fmt.Sprintf("a moderator merged your thread %q into another discussion", source.Title),
+		})
+	}
+	return nil
+}
+
+func (s *threadService) SplitThread(ctx context.Context, split entities.ThreadSplit) (int, error) {
+	split.Title = strings.TrimSpace(split.Title)
+	if split.Title == "" {
+		return 0, invalidf("title is required")
+	}
+	if _, err := s.liveThread(ctx, split.ThreadID); err != nil {
+		return 0, err
+	}
+	split.Body = strings.TrimSpace(split.Body)
+	if split.Body == "" {
+		split.Body = fmt.Sprintf("Split from thread #%d.", split.ThreadID)
+	}
+	id, ok, err := s.repo.SplitThread(ctx, split)
+	if err != nil {
+		return 0, fmt.Errorf("split thread: %w", err)
+	}
+	if !ok {
+		return 0, conflictf("thread was merged concurrently")
+	}
+	if id == 0 {
+		return 0, notFoundf("reply %d not found in thread %d", split.ReplyID, split.ThreadID)
+	}
+	return id, nil
+}
@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+func TestMergeThread_MovesRepliesAndLeavesStub(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threads.replies = newFakeReplyRepo()
+	notes := &fakeNotificationRepo{}
+	svc := NewThreadService(ThreadServiceDeps{Repo: threads, Notifier: NewNotificationService(notes, nil)})
+
+	dup, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 2, Title: "dup", Body: "b", Upvotes: 3})
+	orig, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "orig", Body: "b", Upvotes: 1})
+	other, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "other", Body: "b"})
+	replyID, _ := threads.replies.CreateReply(ctx, &entities.Reply{ThreadID: dup, UserID: 3, Body: "r"})
+
+	if err := svc.MergeThread(ctx, dup, dup, 9); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput merging into itself, got %v", err)
+	}
+	if err := svc.MergeThread(ctx, dup, orig, 9); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	stub := threads.threads[dup]
+	if stub.MergedInto == nil || *stub.MergedInto != orig || !stub.IsLocked {
+		t.Fatalf("expected a locked stub pointing at %d, got %+v", orig, stub)
+	}
+	if threads.replies.replies[replyID].ThreadID != orig || threads.threads[orig].Upvotes != 4 {
+		t.Fatalf("expected replies and votes to move to the target")
+	}
+	if len(threads.modLog) != 1 || threads.modLog[0] != entities.ModActionMerge {
+		t.Fatalf("expected one merge audit entry, got %v", threads.modLog)
+	}
+	if len(notes.ns) != 1 || notes.ns[0].UserID != 2 || notes.ns[0].Kind != entities.NotifyModAction {
+		t.Fatalf("expected the stub's author to be told, got %+v", notes.ns)
+	}
+
+	// stubs can neither be merged again nor receive a merge
+	if err := svc.MergeThread(ctx, dup, other, 9); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict merging a stub, got %v", err)
+	}
+	if err := svc.MergeThread(ctx, other, dup, 9); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict merging into a stub, got %v", err)
+	}
+}
+
+func TestSplitThread_MovesSubtreeKeepingParents(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threads.replies = newFakeReplyRepo()
+	svc := NewThreadService(ThreadServiceDeps{Repo: threads})
+
+	src, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: 4, Title: "t", Body: "b", Tags: []string{"go"}})
+	top, _ := threads.replies.CreateReply(ctx, &entities.Reply{ThreadID: src, UserID: 2, Body: "on topic"})
+	offTopic, _ := threads.replies.CreateReply(ctx, &entities.Reply{ThreadID: src, UserID: 2, ParentID: &top, Body: "tangent"})
+	child, _ := threads.replies.CreateReply(ctx, &entities.Reply{ThreadID: src, UserID: 3, ParentID: &offTopic, Body: "more"})
+	grandchild, _ := threads.replies.CreateReply(ctx, &entities.Reply{ThreadID: src, UserID: 2, ParentID: &child, Body: "even more"})
+
+	if _, err := svc.SplitThread(ctx, entities.ThreadSplit{ThreadID: src, ReplyID: offTopic, ActorID: 9}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput without a title, got %v", err)
+	}
+	id, err := svc.SplitThread(ctx, entities.ThreadSplit{ThreadID: src, ReplyID: offTopic, ActorID: 9, Title: " Tangent "})
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	created := threads.threads[id]
+	if created.UserID != 2 || created.Title != "Tangent" || created.Body == "" || created.CategoryID != 4 || len(created.Tags) != 1 {
+		t.Fatalf("unexpected new thread %+v", created)
+	}
+	rs := threads.replies.replies
+	if rs[top].ThreadID != src || rs[offTopic].ThreadID != id || rs[child].ThreadID != id || rs[grandchild].ThreadID != id {
+		t.Fatalf("expected only the subtree to move")
+	}
+	if rs[offTopic].ParentID != nil || *rs[child].ParentID != offTopic || *rs[grandchild].ParentID != child {
+		t.Fatalf("expected the root to lose its parent and the rest to keep theirs")
+	}
+
+	if _, err := svc.SplitThread(ctx, entities.ThreadSplit{ThreadID: src, ReplyID: child, ActorID: 9, Title: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a reply in another thread, got %v", err)
+	}
+	gone, _ := threads.replies.CreateReply(ctx, &entities.Reply{ThreadID: src, UserID: 3, ParentID: &top, Body: "removed"})
+	threads.replies.replies[gone].IsDeleted = true
+	if _, err := svc.SplitThread(ctx, entities.ThreadSplit{ThreadID: src, ReplyID: gone, ActorID: 9, Title: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound splitting off a deleted reply, got %v", err)
+	}
+}
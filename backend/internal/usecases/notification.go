@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+const (
+	DefaultNotificationPageSize = 20
+	MaxNotificationPageSize     = 100
+	// MaxMarkReadIDs bounds one POST /notifications/read call
+	MaxMarkReadIDs = 200
+)
+
+// Notifier records notifications on behalf of other usecases. It is best-effort: failures
+// are logged and never fail the action that caused them, and notifications a user would
+// get for their own action are dropped.
+type Notifier interface {
+	Notify(ctx context.Context, ns ...*entities.Notification)
+}
+
+// UnreadCounter caches per-user unread notification counts. Implementations must treat
+// failures as misses; the store stays the source of truth.
+type UnreadCounter interface {
+	Get(ctx context.Context, userID int) (int, bool)
+	Set(ctx context.Context, userID, n int)
+	// Add adjusts a cached count by delta and does nothing when no count is cached
+	Add(ctx context.Context, userID, delta int)
+	Invalidate(ctx context.Context, userID int)
+}
+
+type NotificationService interface {
+	Notifier
+	// ListNotifications returns one page of userID's notifications, newest first
+	ListNotifications(ctx context.Context, userID, limit int, cursor string, unreadOnly bool) (*entities.NotificationPage, error)
+	// MarkRead marks the given notifications of userID as read; IDs of other users are ignored
+	MarkRead(ctx context.Context, userID int, ids []int) error
+	MarkAllRead(ctx context.Context, userID int) error
+	UnreadCount(ctx context.Context, userID int) (int, error)
+}
+
+type notificationService struct {
+	repo    repositories.NotificationRepository
+	counter UnreadCounter // optional
+}
+
+func NewNotificationService(repo repositories.NotificationRepository, counter UnreadCounter) NotificationService {
+	return &notificationService{repo: repo, counter: counter}
+}
+
+func (s *notificationService) Notify(ctx context.Context, ns ...*entities.Notification) {
+	keep := make([]*entities.Notification, 0, len(ns))
+	for _, n := range ns {
+		if n == nil || n.UserID == 0 || (n.ActorID != nil && *n.ActorID == n.UserID) {
+			continue
+		}
+		keep = append(keep, n)
+	}
+	if len(keep) == 0 {
+		return
+	}
+	if err := s.repo.CreateNotifications(ctx, keep); err != nil {
+		log.Printf("create %d notifications: %v", len(keep), err)
+		return
+	}
+	if s.counter != nil {
+		for _, n := range keep {
+			// deduplicated notifications were not stored and keep ID 0
+			if n.ID != 0 {
+				s.counter.Add(ctx, n.UserID, 1)
+			}
+		}
+	}
+}
+
+func (s *notificationService) ListNotifications(ctx context.Context, userID, limit int, cursor string, unreadOnly bool) (*entities.NotificationPage, error) {
+	limit = clampInt(limit, DefaultNotificationPageSize, MaxNotificationPageSize)
+	var before entities.NotificationCursor
+	if cursor != "" {
+		if err := decodeCursor(cursor, &before); err != nil {
+			return nil, err
+		}
+		if before.ID <= 0 {
+			return nil, ErrInvalidCursor
+		}
+	}
+	ns, err := s.repo.ListNotifications(ctx, userID, before.ID, limit+1, unreadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	page := &entities.NotificationPage{Notifications: ns}
+	if len(ns) > limit {
+		page.Notifications = ns[:limit]
+		if page.NextCursor, err = encodeCursor(entities.NotificationCursor{ID: ns[limit-1].ID}); err != nil {
+			return nil, fmt.Errorf("encode cursor: %w", err)
+		}
+	}
+	if page.Notifications == nil {
+		page.Notifications = []*entities.Notification{}
+	}
+	return page, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID int, ids []int) error {
+	if len(ids) == 0 {
+		return invalidf("ids is empty")
+	}
+	if len(ids) > MaxMarkReadIDs {
+		return invalidf("at most %d ids per request", MaxMarkReadIDs)
+	}
+	n, err := s.repo.MarkRead(ctx, userID, ids)
+	if err != nil {
+		return fmt.Errorf("mark notifications read: %w", err)
+	}
+	if n > 0 && s.counter != nil {
+		s.counter.Invalidate(ctx, userID)
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int) error {
+	if _, err := s.repo.MarkAllRead(ctx, userID); err != nil {
+		return fmt.Errorf("mark all notifications read: %w", err)
+	}
+	if s.counter != nil {
+		s.counter.Set(ctx, userID, 0)
+	}
+	return nil
+}
+
+func (s *notificationService) UnreadCount(ctx context.Context, userID int) (int, error) {
+	if s.counter != nil {
+		if n, ok := s.counter.Get(ctx, userID); ok {
+			return n, nil
+		}
+	}
+	n, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	if s.counter != nil {
+		s.counter.Set(ctx, userID, n)
+	}
+	return n, nil
+}
+
+// notify forwards to n when it is set, so usecases can take an optional Notifier.
+func notify(ctx context.Context, n Notifier, ns ...*entities.Notification) {
+	if n != nil && len(ns) > 0 {
+		n.Notify(ctx, ns...)
+	}
+}
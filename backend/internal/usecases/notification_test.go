@@ -0,0 +1,186 @@
+package usecases
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeNotificationRepo struct {
+	ns []*entities.Notification
+}
+
+func (f *fakeNotificationRepo) CreateNotifications(ctx context.Context, ns []*entities.Notification) error {
+	for _, n := range ns {
+		if f.has(n.UserID, n.DedupeKey) {
+			continue
+		}
+		n.ID = len(f.ns) + 1
+		f.ns = append(f.ns, n)
+	}
+	return nil
+}
+func (f *fakeNotificationRepo) has(userID int, key string) bool {
+	for _, x := range f.ns {
+		if key != "" && x.UserID == userID && x.DedupeKey == key {
+			return true
+		}
+	}
+	return false
+}
+func (f *fakeNotificationRepo) ListNotifications(ctx context.Context, userID, beforeID, limit int, unreadOnly bool) ([]*entities.Notification, error) {
+	var out []*entities.Notification
+	for _, n := range f.ns {
+		if n.UserID == userID && (beforeID == 0 || n.ID < beforeID) && (!unreadOnly || !n.Read) {
+			out = append(out, n)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+func (f *fakeNotificationRepo) MarkRead(ctx context.Context, userID int, ids []int) (int64, error) {
+	var n int64
+	for _, x := range f.ns {
+		for _, id := range ids {
+			if x.ID == id && x.UserID == userID && !x.Read {
+				x.Read = true
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+func (f *fakeNotificationRepo) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	var n int64
+	for _, x := range f.ns {
+		if x.UserID == userID && !x.Read {
+			x.Read = true
+			n++
+		}
+	}
+	return n, nil
+}
+func (f *fakeNotificationRepo) CountUnread(ctx context.Context, userID int) (int, error) {
+	n := 0
+	for _, x := range f.ns {
+		if x.UserID == userID && !x.Read {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// fakeUnreadCounter mimics the Redis counter: Add only touches cached counts.
+type fakeUnreadCounter struct {
+	counts map[int]int
+}
+
+func (f *fakeUnreadCounter) Get(ctx context.Context, userID int) (int, bool) {
+	n, ok := f.counts[userID]
+	return n, ok
+}
+func (f *fakeUnreadCounter) Set(ctx context.Context, userID, n int) { f.counts[userID] = n }
+func (f *fakeUnreadCounter) Add(ctx context.Context, userID, delta int) {
+	if _, ok := f.counts[userID]; ok {
+		f.counts[userID] += delta
+	}
+}
+func (f *fakeUnreadCounter) Invalidate(ctx context.Context, userID int) { delete(f.counts, userID) }
+
+func kindsFor(ns []*entities.Notification, userID int) []entities.NotificationKind {
+	var out []entities.NotificationKind
+	for _, n := range ns {
+		if n.UserID == userID {
+			out = append(out, n.Kind)
+		}
+	}
+	return out
+}
+
+// --- tests ---
+func TestNotifications_FromRepliesAndVotes(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	cats := newFakeCategoryRepo()
+	catID, _ := cats.CreateCategory(ctx, &entities.Category{Slug: "general"})
+	subs := newFakeSubscriptionRepo()
+	repo := &fakeNotificationRepo{}
+	counter := &fakeUnreadCounter{counts: map[int]int{}}
+	svc := NewNotificationService(repo, counter)
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Categories: cats, Users: newFakeUserRepo(), Subscriptions: subs})
+	replySvc := NewReplyService(ReplyServiceDeps{Repo: newFakeReplyRepo(), Threads: threads, Categories: cats, Users: newFakeUserRepo(), Subscriptions: subs, Notifier: svc})
+	voteSvc := NewVoteService(newFakeVoteRepo(threads), threads, nil, svc)
+
+	threadID, err := threadSvc.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: catID, Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	if n, _ := svc.UnreadCount(ctx, 1); n != 0 {
+		t.Fatalf("expected no unread notifications, got %d", n)
+	}
+	// 2 replies to the thread, 3 answers 2, the author answers themselves
+	first, err := replySvc.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 2, Body: "a"})
+	if err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if _, err := replySvc.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 3, Body: "b", ParentID: &first}); err != nil {
+		t.Fatalf("nested reply: %v", err)
+	}
+	if _, err := replySvc.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 1, Body: "c"}); err != nil {
+		t.Fatalf("own reply: %v", err)
+	}
+	if got := kindsFor(repo.ns, 1); len(got) != 2 || got[0] != entities.NotifyReplyToThread || got[1] != entities.NotifyReplyToThread {
+		t.Fatalf("expected the watching author to hear about both other replies, got %v", got)
+	}
+	if got := kindsFor(repo.ns, 2); len(got) != 1 || got[0] != entities.NotifyReplyToReply {
+		t.Fatalf("expected one reply_to_reply for user 2, got %v", got)
+	}
+	if n, _ := svc.UnreadCount(ctx, 1); n != 2 {
+		t.Fatalf("expected 2 unread, got %d", n)
+	}
+
+	// repeating an upvote, or retracting and casting it again, does not notify again
+	for i := 0; i < 3; i++ {
+		if _, err := voteSvc.CreateVote(ctx, &entities.Vote{UserID: 4, ThreadID: &threadID, Value: 1}); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+		if i == 1 {
+			if err := voteSvc.RetractVote(ctx, &entities.Vote{UserID: 4, ThreadID: &threadID}); err != nil {
+				t.Fatalf("retract: %v", err)
+			}
+		}
+	}
+	if n, _ := svc.UnreadCount(ctx, 1); n != 3 {
+		t.Fatalf("expected the cached count to follow the upvote to 3, got %d", n)
+	}
+
+	page, err := svc.ListNotifications(ctx, 1, 2, "", false)
+	if err != nil || len(page.Notifications) != 2 || page.NextCursor == "" {
+		t.Fatalf("expected a first page of 2 with a cursor, got %+v (%v)", page, err)
+	}
+	if page.Notifications[0].Kind != entities.NotifyUpvote {
+		t.Fatalf("expected newest first, got %q", page.Notifications[0].Kind)
+	}
+	if err := svc.MarkRead(ctx, 1, []int{page.Notifications[0].ID, repo.ns[1].ID}); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	if n, _ := svc.UnreadCount(ctx, 1); n != 2 {
+		t.Fatalf("marking another user's notification must not count; expected 2 unread, got %d", n)
+	}
+	rest, err := svc.ListNotifications(ctx, 1, 2, page.NextCursor, true)
+	if err != nil || len(rest.Notifications) != 1 || rest.NextCursor != "" {
+		t.Fatalf("expected one unread notification on the last page, got %+v (%v)", rest, err)
+	}
+	if err := svc.MarkAllRead(ctx, 1); err != nil {
+		t.Fatalf("mark all read: %v", err)
+	}
+	if n, _ := svc.UnreadCount(ctx, 1); n != 0 {
+		t.Fatalf("expected 0 unread after marking all, got %d", n)
+	}
+}
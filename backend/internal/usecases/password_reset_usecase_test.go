@@ -30,6 +30,11 @@ func (f *fakeUserRepo) GetUserByID(ctx context.Context, id int) (*entities.User,
 	return u, nil
 }
 func (f *fakeUserRepo) GetUserByUsername(ctx context.Context, username string) (*entities.User, error) {
+	for _, u := range f.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
 	return nil, nil
 }
 func (f *fakeUserRepo) GetUserByEmail(ctx context.Context, email string) (*entities.User, error) {
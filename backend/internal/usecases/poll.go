@@ -0,0 +1,231 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type PollService interface {
+	// CreatePoll attaches a poll to a thread; only the thread author or an admin may add one
+	CreatePoll(ctx context.Context, p *entities.Poll, actorID int) (int, error)
+	// GetPoll returns the thread's poll with tallies, hidden when the poll asks for votes first
+	GetPoll(ctx context.Context, threadID int) (*entities.Poll, error)
+	// CastBallot records userID's choices, replacing an earlier ballot, and returns the updated poll
+	CastBallot(ctx context.Context, threadID, userID int, optionIDs []int) (*entities.Poll, error)
+	// ClosePoll stops a poll from taking ballots; only the thread author or an admin may close it
+	ClosePoll(ctx context.Context, threadID, actorID int) error
+}
+
+type pollService struct {
+	repo    repositories.PollRepository
+	threads repositories.ThreadRepository
+	users   repositories.UserRepository
+	now     func() time.Time
+}
+
+func NewPollService(repo repositories.PollRepository, threads repositories.ThreadRepository, users repositories.UserRepository) PollService {
+	return &pollService{repo: repo, threads: threads, users: users, now: time.Now}
+}
+
+func (s *pollService) CreatePoll(ctx context.Context, p *entities.Poll, actorID int) (int, error) {
+	if p == nil {
+		return 0, invalidf("poll is nil")
+	}
+	// unlike ballots, a poll may be added before the thread is published so that it goes live
+	// with the thread
+	t, err := s.threads.GetThreadByID(ctx, p.ThreadID)
+	if err != nil || t == nil || t.IsDeleted {
+		return 0, notFoundf("thread not found")
+	}
+	if err := requireAuthorOrAdmin(ctx, s.users, t.UserID, actorID, "add a poll"); err != nil {
+		if t.Unpublished() {
+			return 0, notFoundf("thread not found")
+		}
+		return 0, err
+	}
+	if t.IsLocked {
+		return 0, forbiddenf("thread is locked")
+	}
+	if err := s.validate(p); err != nil {
+		return 0, err
+	}
+	existing, err := s.repo.GetPollByThread(ctx, p.ThreadID)
+	if err != nil {
+		return 0, fmt.Errorf("get poll: %w", err)
+	}
+	if existing != nil {
+		return 0, conflictf("thread already has a poll")
+	}
+	p.CreatedBy = actorID
+	id, err := s.repo.CreatePoll(ctx, p)
+	if err != nil {
+		return 0, fmt.Errorf("create poll: %w", err)
+	}
+	if id == 0 {
+		// another request added a poll since the check above
+		return 0, conflictf("thread already has a poll")
+	}
+	return id, nil
+}
+
+// validate trims the question and options and checks them against the poll limits.
+func (s *pollService) validate(p *entities.Poll) error {
+	p.Question = strings.TrimSpace(p.Question)
+	if p.Question == "" {
+		return invalidf("poll question is required")
+	}
+	if utf8.RuneCountInString(p.Question) > entities.MaxPollQuestionLength {
+		return invalidf("poll question is longer than %d characters", entities.MaxPollQuestionLength)
+	}
+	if len(p.Options) < entities.MinPollOptions || len(p.Options) > entities.MaxPollOptions {
+		return invalidf("a poll needs %d to %d options", entities.MinPollOptions, entities.MaxPollOptions)
+	}
+	seen := make(map[string]bool, len(p.Options))
+	for i := range p.Options {
+		text := strings.TrimSpace(p.Options[i].Text)
+		if text == "" {
+			return invalidf("poll options cannot be empty")
+		}
+		if utf8.RuneCountInString(text) > entities.MaxPollOptionLength {
+			return invalidf("poll option is longer than %d characters", entities.MaxPollOptionLength)
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			return invalidf("duplicate poll option %q", text)
+		}
+		seen[key] = true
+		p.Options[i] = entities.PollOption{Text: text}
+	}
+	if p.ClosesAt != nil {
+		if !p.ClosesAt.After(s.now()) {
+			return invalidf("poll close time must be in the future")
+		}
+		// poll timestamps are stored without a zone, in UTC
+		at := p.ClosesAt.UTC()
+		p.ClosesAt = &at
+	}
+	p.ClosedAt = nil
+	return nil
+}
+
+func (s *pollService) GetPoll(ctx context.Context, threadID int) (*entities.Poll, error) {
+	t, err := visibleThread(ctx, s.threads, s.users, threadID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.repo.GetPollByThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("get poll: %w", err)
+	}
+	if p == nil {
+		return nil, notFoundf("thread has no poll")
+	}
+	if err := s.applyViewer(ctx, p, t); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// applyViewer sets Closed and the caller's choices, and withholds tallies from callers who
+// have not voted on an open poll that only shows results after voting.
+func (s *pollService) applyViewer(ctx context.Context, p *entities.Poll, t *entities.Thread) error {
+	p.Closed = p.IsClosed(s.now()) || t.IsLocked
+	if uid := ViewerID(ctx); uid != 0 {
+		choices, err := s.repo.GetBallot(ctx, p.ID, uid)
+		if err != nil {
+			return fmt.Errorf("get ballot: %w", err)
+		}
+		p.MyChoices = choices
+	}
+	if !p.Closed && !p.ResultsBeforeVote && len(p.MyChoices) == 0 {
+		p.ResultsHidden = true
+		p.Voters = 0
+		for i := range p.Options {
+			p.Options[i].Votes = 0
+		}
+	}
+	return nil
+}
+
+func (s *pollService) CastBallot(ctx context.Context, threadID, userID int, optionIDs []int) (*entities.Poll, error) {
+	t, err := ensureThreadOpen(ctx, s.threads, threadID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.repo.GetPollByThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("get poll: %w", err)
+	}
+	if p == nil {
+		return nil, notFoundf("thread has no poll")
+	}
+	if p.IsClosed(s.now()) {
+		return nil, forbiddenf("poll is closed")
+	}
+	if len(optionIDs) == 0 {
+		return nil, invalidf("choose at least one option")
+	}
+	if len(optionIDs) > 1 && !p.MultipleChoice {
+		return nil, invalidf("this poll allows a single choice")
+	}
+	valid := make(map[int]bool, len(p.Options))
+	for _, o := range p.Options {
+		valid[o.ID] = true
+	}
+	picked := make(map[int]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] {
+			return nil, invalidf("option %d is not part of this poll", id)
+		}
+		if picked[id] {
+			return nil, invalidf("option %d chosen twice", id)
+		}
+		picked[id] = true
+	}
+	ok, err := s.repo.CastBallot(ctx, p.ID, userID, optionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("cast ballot: %w", err)
+	}
+	if !ok {
+		// closed or locked between the checks above and the write
+		return nil, forbiddenf("poll is closed")
+	}
+	updated, err := s.repo.GetPollByThread(ctx, threadID)
+	if err != nil || updated == nil {
+		return nil, fmt.Errorf("get poll: %w", err)
+	}
+	if err := s.applyViewer(WithViewer(ctx, userID), updated, t); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *pollService) ClosePoll(ctx context.Context, threadID, actorID int) error {
+	t, err := s.threads.GetThreadByID(ctx, threadID)
+	if err != nil || t == nil || t.IsDeleted {
+		return notFoundf("thread not found")
+	}
+	if err := requireAuthorOrAdmin(ctx, s.users, t.UserID, actorID, "close the poll"); err != nil {
+		return err
+	}
+	p, err := s.repo.GetPollByThread(ctx, threadID)
+	if err != nil {
+		return fmt.Errorf("get poll: %w", err)
+	}
+	if p == nil {
+		return notFoundf("thread has no poll")
+	}
+	if p.ClosedAt != nil {
+		return conflictf("poll is already closed")
+	}
+	if err := s.repo.ClosePoll(ctx, p.ID); err != nil {
+		return fmt.Errorf("close poll: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakePollRepo struct {
+	polls   map[int]*entities.Poll
+	ballots map[int]map[int][]int // poll ID -> user ID -> option IDs
+	nextOpt int
+}
+
+func newFakePollRepo() *fakePollRepo {
+	return &fakePollRepo{polls: map[int]*entities.Poll{}, ballots: map[int]map[int][]int{}}
+}
+
+func (f *fakePollRepo) CreatePoll(ctx context.Context, p *entities.Poll) (int, error) {
+	for _, existing := range f.polls {
+		if existing.ThreadID == p.ThreadID {
+			return 0, nil
+		}
+	}
+	p.ID = len(f.polls) + 1
+	for i := range p.Options {
+		f.nextOpt++
+		p.Options[i].ID, p.Options[i].Position = f.nextOpt, i
+	}
+	cp := *p
+	cp.Options = append([]entities.PollOption(nil), p.Options...)
+	f.polls[p.ID] = &cp
+	f.ballots[p.ID] = map[int][]int{}
+	return p.ID, nil
+}
+func (f *fakePollRepo) GetPollByThread(ctx context.Context, threadID int) (*entities.Poll, error) {
+	for _, p := range f.polls {
+		if p.ThreadID != threadID {
+			continue
+		}
+		cp := *p
+		cp.Options = append([]entities.PollOption(nil), p.Options...)
+		cp.Voters = len(f.ballots[p.ID])
+		for i := range cp.Options {
+			for _, choices := range f.ballots[p.ID] {
+				for _, id := range choices {
+					if id == cp.Options[i].ID {
+						cp.Options[i].Votes++
+					}
+				}
+			}
+		}
+		return &cp, nil
+	}
+	return nil, nil
+}
+func (f *fakePollRepo) GetBallot(ctx context.Context, pollID, userID int) ([]int, error) {
+	return f.ballots[pollID][userID], nil
+}
+func (f *fakePollRepo) CastBallot(ctx context.Context, pollID, userID int, optionIDs []int) (bool, error) {
+	f.ballots[pollID][userID] = optionIDs
+	return true, nil
+}
+func (f *fakePollRepo) ClosePoll(ctx context.Context, pollID int) error {
+	now := time.Now()
+	f.polls[pollID].ClosedAt = &now
+	return nil
+}
+
+// --- tests ---
+func TestPollService_BallotsAndFreezing(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b"})
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleUser}
+	repo := newFakePollRepo()
+	svc := NewPollService(repo, threads, users)
+
+	one := []entities.PollOption{{Text: "only"}}
+	if _, err := svc.CreatePoll(ctx, &entities.Poll{ThreadID: threadID, Question: "q?", Options: one}, 1); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a single option, got %v", err)
+	}
+	p := &entities.Poll{ThreadID: threadID, Question: "Tabs or spaces?", Options: []entities.PollOption{{Text: "Tabs"}, {Text: "Spaces"}}}
+	if _, err := svc.CreatePoll(ctx, p, 2); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a non-author, got %v", err)
+	}
+	if _, err := svc.CreatePoll(ctx, p, 1); err != nil {
+		t.Fatalf("create poll: %v", err)
+	}
+	tabs, spaces := p.Options[0].ID, p.Options[1].ID
+
+	// results are hidden until the caller votes
+	got, err := svc.GetPoll(WithViewer(ctx, 2), threadID)
+	if err != nil || !got.ResultsHidden {
+		t.Fatalf("expected hidden results before voting, got %+v (%v)", got, err)
+	}
+	if _, err := svc.CastBallot(ctx, threadID, 2, []int{tabs, spaces}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for two choices on a single-choice poll, got %v", err)
+	}
+	if _, err := svc.CastBallot(ctx, threadID, 2, []int{tabs}); err != nil {
+		t.Fatalf("cast: %v", err)
+	}
+	got, err = svc.CastBallot(ctx, threadID, 2, []int{spaces})
+	if err != nil {
+		t.Fatalf("change ballot: %v", err)
+	}
+	if got.ResultsHidden || got.Voters != 1 || got.Options[0].Votes != 0 || got.Options[1].Votes != 1 {
+		t.Fatalf("expected the changed ballot to replace the first, got %+v", got)
+	}
+
+	threads.threads[threadID].IsLocked = true
+	if _, err := svc.CastBallot(ctx, threadID, 1, []int{tabs}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden on a locked thread, got %v", err)
+	}
+	threads.threads[threadID].IsLocked = false
+	if err := svc.ClosePoll(ctx, threadID, 1); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := svc.CastBallot(ctx, threadID, 1, []int{tabs}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden on a closed poll, got %v", err)
+	}
+	// closed polls show results to everyone
+	if got, _ := svc.GetPoll(ctx, threadID); !got.Closed || got.ResultsHidden {
+		t.Fatalf("expected visible results on a closed poll, got %+v", got)
+	}
+}
+
+// racingPollRepo misses polls added after the service's existence check, as a concurrent
+// request would
+type racingPollRepo struct{ *fakePollRepo }
+
+func (r racingPollRepo) GetPollByThread(ctx context.Context, threadID int) (*entities.Poll, error) {
+	return nil, nil
+}
+
+func TestPollService_CreateOnDraftAndRace(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b", Status: entities.ThreadStatusDraft})
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleUser}
+	repo := newFakePollRepo()
+	svc := NewPollService(racingPollRepo{repo}, threads, users)
+
+	newPoll := func() *entities.Poll {
+		return &entities.Poll{ThreadID: threadID, Question: "q?", Options: []entities.PollOption{{Text: "a"}, {Text: "b"}}}
+	}
+	if _, err := svc.CreatePoll(ctx, newPoll(), 2); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for someone else's draft, got %v", err)
+	}
+	if _, err := svc.CreatePoll(ctx, newPoll(), 1); err != nil {
+		t.Fatalf("expected the author to add a poll to a draft, got %v", err)
+	}
+	if _, err := svc.CreatePoll(ctx, newPoll(), 1); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict when the insert loses the race, got %v", err)
+	}
+	if _, err := svc.CastBallot(ctx, threadID, 2, []int{1}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ballots to wait for publishing, got %v", err)
+	}
+}
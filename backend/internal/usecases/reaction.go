@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+type ReactionService interface {
+	// AddReaction adds r.UserID's r.Emoji reaction to r's thread or reply; each user can add
+	// a given emoji once per post
+	AddReaction(ctx context.Context, r *entities.Reaction) error
+	// RemoveReaction removes r.UserID's r.Emoji reaction from r's thread or reply
+	RemoveReaction(ctx context.Context, r *entities.Reaction) error
+	// Counts returns the reaction counts of one post, with Reacted set for the request's viewer
+	Counts(ctx context.Context, threadID, replyID *int) ([]entities.ReactionCount, error)
+	// Emojis returns the configured reaction set in display order
+	Emojis() []string
+}
+
+type reactionService struct {
+	repo    repositories.ReactionRepository
+	threads repositories.ThreadRepository
+	replies repositories.ReplyRepository
+	emojis  []string
+	allowed map[string]bool
+}
+
+// NewReactionService restricts reactions to emojis; an empty set selects entities.DefaultReactionEmojis.
+func NewReactionService(repo repositories.ReactionRepository, threads repositories.ThreadRepository, replies repositories.ReplyRepository, emojis []string) ReactionService {
+	s := &reactionService{repo: repo, threads: threads, replies: replies, allowed: map[string]bool{}}
+	for _, e := range emojis {
+		if e = strings.TrimSpace(e); e != "" && !s.allowed[e] {
+			s.allowed[e] = true
+			s.emojis = append(s.emojis, e)
+		}
+	}
+	if len(s.emojis) == 0 {
+		return NewReactionService(repo, threads, replies, entities.DefaultReactionEmojis)
+	}
+	return s
+}
+
+func (s *reactionService) Emojis() []string {
+	return append([]string(nil), s.emojis...)
+}
+
+func (s *reactionService) AddReaction(ctx context.Context, r *entities.Reaction) error {
+	if r == nil {
+		return invalidf("reaction is nil")
+	}
+	if !s.allowed[r.Emoji] {
+		return invalidf("emoji must be one of %s", strings.Join(s.emojis, " "))
+	}
+	if _, _, err := ensurePostOpen(ctx, s.threads, s.replies, r.ThreadID, r.ReplyID); err != nil {
+		return err
+	}
+	added, err := s.repo.AddReaction(ctx, r)
+	if err != nil {
+		return fmt.Errorf("add reaction: %w", err)
+	}
+	if !added {
+		return conflictf("already reacted with %s", r.Emoji)
+	}
+	return nil
+}
+
+func (s *reactionService) RemoveReaction(ctx context.Context, r *entities.Reaction) error {
+	if r == nil {
+		return invalidf("reaction is nil")
+	}
+	if (r.ThreadID == nil) == (r.ReplyID == nil) {
+		return invalidf("exactly one of thread_id or reply_id is required")
+	}
+	removed, err := s.repo.RemoveReaction(ctx, r.UserID, r.ThreadID, r.ReplyID, r.Emoji)
+	if err != nil {
+		return fmt.Errorf("remove reaction: %w", err)
+	}
+	if !removed {
+		return notFoundf("no %s reaction to remove", r.Emoji)
+	}
+	return nil
+}
+
+func (s *reactionService) Counts(ctx context.Context, threadID, replyID *int) ([]entities.ReactionCount, error) {
+	var counts map[int][]entities.ReactionCount
+	var err error
+	id := 0
+	if threadID != nil {
+		id = *threadID
+		counts, err = s.repo.GetThreadReactions(ctx, ViewerID(ctx), []int{id})
+	} else if replyID != nil {
+		id = *replyID
+		counts, err = s.repo.GetReplyReactions(ctx, ViewerID(ctx), []int{id})
+	} else {
+		return nil, invalidf("exactly one of thread_id or reply_id is required")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("count reactions: %w", err)
+	}
+	if counts[id] == nil {
+		return []entities.ReactionCount{}, nil
+	}
+	return counts[id], nil
+}
+
+// fillThreadReactions sets Reactions on threads, with Reacted for the request's viewer. It
+// runs with the viewer state rather than before caching so cached threads never carry
+// stale counts, and is best-effort like fillThreadVotes.
+func fillThreadReactions(ctx context.Context, reactions repositories.ReactionRepository, threads []*entities.Thread) {
+	if reactions == nil || len(threads) == 0 {
+		return
+	}
+	ids := make([]int, len(threads))
+	for i, t := range threads {
+		ids[i] = t.ID
+	}
+	counts, err := reactions.GetThreadReactions(ctx, ViewerID(ctx), ids)
+	if err != nil {
+		return
+	}
+	for _, t := range threads {
+		t.Reactions = counts[t.ID]
+	}
+}
+
+// fillReplyReactions is fillThreadReactions for replies.
+func fillReplyReactions(ctx context.Context, reactions repositories.ReactionRepository, replies []*entities.Reply) {
+	if reactions == nil || len(replies) == 0 {
+		return
+	}
+	ids := make([]int, len(replies))
+	for i, r := range replies {
+		ids[i] = r.ID
+	}
+	counts, err := reactions.GetReplyReactions(ctx, ViewerID(ctx), ids)
+	if err != nil {
+		return
+	}
+	for _, r := range replies {
+		r.Reactions = counts[r.ID]
+	}
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+// fakeReactionRepo models thread reactions only.
+type fakeReactionRepo struct {
+	reactions []entities.Reaction
+}
+
+func (f *fakeReactionRepo) AddReaction(ctx context.Context, r *entities.Reaction) (bool, error) {
+	for _, x := range f.reactions {
+		if x.UserID == r.UserID && *x.ThreadID == *r.ThreadID && x.Emoji == r.Emoji {
+			return false, nil
+		}
+	}
+	f.reactions = append(f.reactions, *r)
+	return true, nil
+}
+func (f *fakeReactionRepo) RemoveReaction(ctx context.Context, userID int, threadID, replyID *int, emoji string) (bool, error) {
+	for i, x := range f.reactions {
+		if x.UserID == userID && *x.ThreadID == *threadID && x.Emoji == emoji {
+			f.reactions = append(f.reactions[:i], f.reactions[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+func (f *fakeReactionRepo) GetThreadReactions(ctx context.Context, viewerID int, threadIDs []int) (map[int][]entities.ReactionCount, error) {
+	out := map[int][]entities.ReactionCount{}
+	for _, id := range threadIDs {
+		var counts []entities.ReactionCount
+		idx := map[string]int{}
+		for _, x := range f.reactions {
+			if *x.ThreadID != id {
+				continue
+			}
+			i, ok := idx[x.Emoji]
+			if !ok {
+				i = len(counts)
+				idx[x.Emoji] = i
+				counts = append(counts, entities.ReactionCount{Emoji: x.Emoji})
+			}
+			counts[i].Count++
+			counts[i].Reacted = counts[i].Reacted || x.UserID == viewerID
+		}
+		if counts != nil {
+			out[id] = counts
+		}
+	}
+	return out, nil
+}
+func (f *fakeReactionRepo) GetReplyReactions(ctx context.Context, viewerID int, replyIDs []int) (map[int][]entities.ReactionCount, error) {
+	return map[int][]entities.ReactionCount{}, nil
+}
+
+// --- tests ---
+func TestReactions_OncePerEmojiAndSeparateFromVotes(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	id, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b"})
+	repo := &fakeReactionRepo{}
+	svc := NewReactionService(repo, threads, nil, []string{"👍", " 🙏 ", "👍", ""})
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Reactions: repo})
+
+	if got := svc.Emojis(); len(got) != 2 || got[1] != "🙏" {
+		t.Fatalf("expected the configured set trimmed and deduplicated, got %v", got)
+	}
+	for _, r := range []entities.Reaction{{UserID: 7, Emoji: "👍"}, {UserID: 7, Emoji: "🙏"}, {UserID: 8, Emoji: "👍"}} {
+		r.ThreadID = &id
+		if err := svc.AddReaction(ctx, &r); err != nil {
+			t.Fatalf("add %s: %v", r.Emoji, err)
+		}
+	}
+	if err := svc.AddReaction(ctx, &entities.Reaction{UserID: 7, ThreadID: &id, Emoji: "👍"}); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict reacting twice, got %v", err)
+	}
+	if err := svc.AddReaction(ctx, &entities.Reaction{UserID: 7, ThreadID: &id, Emoji: "🎉"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an emoji outside the set, got %v", err)
+	}
+
+	th := threads.threads[id]
+	threadSvc.ApplyViewerState(WithViewer(ctx, 8), th)
+	if len(th.Reactions) != 2 || th.Reactions[0].Count != 2 || !th.Reactions[0].Reacted || th.Reactions[1].Reacted {
+		t.Fatalf("unexpected reaction counts for viewer 8: %+v", th.Reactions)
+	}
+	if th.Upvotes != 0 || th.Downvotes != 0 {
+		t.Fatalf("reactions must not touch vote counters, got %d/%d", th.Upvotes, th.Downvotes)
+	}
+
+	if err := svc.RemoveReaction(ctx, &entities.Reaction{UserID: 8, ThreadID: &id, Emoji: "👍"}); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := svc.RemoveReaction(ctx, &entities.Reaction{UserID: 8, ThreadID: &id, Emoji: "👍"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound removing twice, got %v", err)
+	}
+
+	th.IsLocked = true
+	if err := svc.AddReaction(ctx, &entities.Reaction{UserID: 9, ThreadID: &id, Emoji: "👍"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden on a locked thread, got %v", err)
+	}
+}
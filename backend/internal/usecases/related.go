@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+const (
+	DefaultRelatedThreads = 5
+	MaxRelatedThreads     = 10
+	// RelatedLockWindow keeps threads locked this recently out of related lists
+	RelatedLockWindow = 30 * 24 * time.Hour
+)
+
+// RelatedCache caches the ranked related-thread IDs of a thread. Implementations must treat
+// failures as misses.
+type RelatedCache interface {
+	Get(ctx context.Context, threadID int) ([]int, bool)
+	Set(ctx context.Context, threadID int, ids []int)
+	// InvalidateAll drops every cached list. A change to one thread's tags or title can move it
+	// into or out of any other thread's list, so lists are not invalidated one by one.
+	InvalidateAll(ctx context.Context)
+}
+
+// recentlyLocked reports whether t was locked inside RelatedLockWindow; a lock without a
+// recorded time counts as recent.
+func recentlyLocked(t *entities.Thread, since time.Time) bool {
+	return t.IsLocked && (t.LockedAt == nil || t.LockedAt.After(since))
+}
+
+func (s *threadService) GetRelatedThreads(ctx context.Context, id int, limit int) ([]*entities.Thread, error) {
+	limit = clampInt(limit, DefaultRelatedThreads, MaxRelatedThreads)
+	src, err := s.repo.GetThreadByID(ctx, id)
+	if err != nil || src == nil || src.IsDeleted || (src.Unpublished() && !s.canSeeUnpublished(ctx, src)) {
+		return nil, notFoundf("thread not found")
+	}
+	// locked_at is stored without a zone, in UTC
+	lockedSince := time.Now().UTC().Add(-RelatedLockWindow)
+	ids, ok := []int(nil), false
+	if s.related != nil {
+		ids, ok = s.related.Get(ctx, id)
+	}
+	if !ok {
+		var err error
+		// the full list is cached so every limit is served from one entry
+		if ids, err = s.repo.ListRelatedThreads(ctx, id, lockedSince, MaxRelatedThreads); err != nil {
+			return nil, fmt.Errorf("list related threads: %w", err)
+		}
+		if s.related != nil {
+			s.related.Set(ctx, id, ids)
+		}
+	}
+
+	threads, err := s.repo.GetThreadsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get related threads: %w", err)
+	}
+	byID := make(map[int]*entities.Thread, len(threads))
+	for _, t := range threads {
+		byID[t.ID] = t
+	}
+	// a cached list may name threads deleted or locked since it was stored
+	related := []*entities.Thread{}
+	for _, rid := range ids {
+		if t, ok := byID[rid]; ok && !recentlyLocked(t, lockedSince) && len(related) < limit {
+			related = append(related, t)
+		}
+	}
+	s.fillPreviews(ctx, related)
+	s.ApplyViewerState(ctx, related...)
+	return related, nil
+}
+
+// sameTags reports whether a and b hold the same tag names in any order.
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, t := range a {
+		set[t] = true
+	}
+	for _, t := range b {
+		if !set[t] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeRelatedCache struct {
+	lists map[int][]int
+}
+
+func (f *fakeRelatedCache) Get(ctx context.Context, threadID int) ([]int, bool) {
+	ids, ok := f.lists[threadID]
+	return ids, ok
+}
+func (f *fakeRelatedCache) Set(ctx context.Context, threadID int, ids []int) {
+	f.lists[threadID] = ids
+}
+func (f *fakeRelatedCache) InvalidateAll(ctx context.Context) {
+	f.lists = map[int][]int{}
+}
+
+// --- tests ---
+func TestRelatedThreads_CachedAndInvalidatedOnTagChange(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	cache := &fakeRelatedCache{lists: map[int][]int{}}
+	svc := NewThreadService(ThreadServiceDeps{Repo: threads, Related: cache})
+
+	longAgo := time.Now().Add(-2 * RelatedLockWindow)
+	src, _ := threads.CreateThread(ctx, &entities.Thread{Title: "go", Body: "b", Tags: []string{"go", "http"}})
+	both, _ := threads.CreateThread(ctx, &entities.Thread{Title: "a", Body: "b", Tags: []string{"go", "http"}})
+	one, _ := threads.CreateThread(ctx, &entities.Thread{Title: "b", Body: "b", Tags: []string{"http"}})
+	archived, _ := threads.CreateThread(ctx, &entities.Thread{Title: "c", Body: "b", Tags: []string{"go"}, IsLocked: true, LockedAt: &longAgo})
+	locked, _ := threads.CreateThread(ctx, &entities.Thread{Title: "d", Body: "b", Tags: []string{"go", "http"}, IsLocked: true})
+	deleted, _ := threads.CreateThread(ctx, &entities.Thread{Title: "e", Body: "b", Tags: []string{"go"}, IsDeleted: true})
+
+	related, err := svc.GetRelatedThreads(ctx, src, 0)
+	if err != nil {
+		t.Fatalf("related: %v", err)
+	}
+	var got []int
+	for _, r := range related {
+		got = append(got, r.ID)
+	}
+	if len(got) != 3 || got[0] != both || got[1] != archived || got[2] != one {
+		t.Fatalf("expected %v without threads %d and %d, got %v", []int{both, archived, one}, locked, deleted, got)
+	}
+
+	// a lock after the list was cached still hides the thread
+	threads.threads[both].IsLocked = true
+	related, _ = svc.GetRelatedThreads(ctx, src, 1)
+	if threads.relatedCalls != 1 || len(related) != 1 || related[0].ID != archived {
+		t.Fatalf("expected the cached list minus the locked thread, got %d queries and %+v", threads.relatedCalls, related)
+	}
+
+	// a body-only edit keeps the cache; a tag change drops every list, since the edited thread
+	// may now belong in others
+	if _, err := svc.GetRelatedThreads(ctx, one, 0); err != nil {
+		t.Fatalf("related: %v", err)
+	}
+	if err := svc.UpdateThread(ctx, &entities.Thread{ID: src, Title: "go", Body: "new", Tags: []string{"http", "go"}}, entities.EditInfo{}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, ok := cache.lists[src]; !ok {
+		t.Fatalf("expected the cached list to survive a body edit")
+	}
+	if err := svc.UpdateThread(ctx, &entities.Thread{ID: src, Title: "go", Body: "new", Tags: []string{"http"}}, entities.EditInfo{}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, ok := cache.lists[src]; ok {
+		t.Fatalf("expected the cached list to be dropped after a tag change")
+	}
+	if _, ok := cache.lists[one]; ok {
+		t.Fatalf("expected other threads' cached lists to be dropped after a tag change")
+	}
+
+	if _, err := svc.GetRelatedThreads(ctx, deleted, 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a deleted thread, got %v", err)
+	}
+}
@@ -2,7 +2,10 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 
 	"github.com/nocson47/beaconofknowledge/internal/entities"
 	"github.com/nocson47/beaconofknowledge/internal/repositories"
@@ -11,59 +14,341 @@ import (
 type ReplyService interface {
 	CreateReply(ctx context.Context, r *entities.Reply) (int, error)
 	GetRepliesByThread(ctx context.Context, threadID int) ([]entities.Reply, error)
+	// GetReplyTree returns the thread's replies nested by ParentID. cursor is a next_cursor or
+	// more_children_cursor from an earlier tree response; empty starts at the top level.
+	GetReplyTree(ctx context.Context, threadID int, opts entities.ReplyTreeOptions, cursor string) (*entities.ReplyTree, error)
 	GetReplyByID(ctx context.Context, id int) (*entities.Reply, error)
-	// DeleteReply enforces authorization: admins can delete any reply, users can delete their own
-	DeleteReply(ctx context.Context, id int, actorUserID int, isAdmin bool) error
-	UpdateReply(ctx context.Context, r *entities.Reply, actorUserID int, isAdmin bool) error
+	// DeleteReply enforces authorization: admins can delete any reply, users can delete their own.
+	// It returns the reply's thread ID so callers can drop cached copies of the thread.
+	DeleteReply(ctx context.Context, id int, actorUserID int, isAdmin bool) (int, error)
+	// UpdateReply enforces the same rule as DeleteReply; edit.EditorID is the acting user
+	UpdateReply(ctx context.Context, r *entities.Reply, edit entities.EditInfo, isAdmin bool) error
 }
 
 type replyService struct {
-	repo repositories.ReplyRepository
+	repo        repositories.ReplyRepository
+	threads     repositories.ThreadRepository
+	categories  repositories.CategoryRepository
+	users       repositories.UserRepository
+	votes       repositories.VoteRepository
+	subs        repositories.SubscriptionRepository
+	mentions    repositories.MentionRepository
+	reactions   repositories.ReactionRepository
+	attachments repositories.AttachmentRepository // optional
+	notifier    Notifier                          // optional
+	content     *ContentPipeline
 }
 
-func NewReplyService(repo repositories.ReplyRepository) ReplyService {
-	return &replyService{repo: repo}
+// ReplyServiceDeps holds what NewReplyService wires together. Repo and Threads are always
+// required and Categories is needed to create replies; the others may be nil, which skips
+// what they back.
+type ReplyServiceDeps struct {
+	Repo          repositories.ReplyRepository
+	Threads       repositories.ThreadRepository
+	Categories    repositories.CategoryRepository
+	Users         repositories.UserRepository
+	Votes         repositories.VoteRepository
+	Subscriptions repositories.SubscriptionRepository
+	Mentions      repositories.MentionRepository
+	Reactions     repositories.ReactionRepository
+	Attachments   repositories.AttachmentRepository
+	Notifier      Notifier
+	Content       *ContentPipeline
+}
+
+func NewReplyService(deps ReplyServiceDeps) ReplyService {
+	return &replyService{
+		repo:        deps.Repo,
+		threads:     deps.Threads,
+		categories:  deps.Categories,
+		users:       deps.Users,
+		votes:       deps.Votes,
+		subs:        deps.Subscriptions,
+		mentions:    deps.Mentions,
+		reactions:   deps.Reactions,
+		attachments: deps.Attachments,
+		notifier:    deps.Notifier,
+		content:     deps.Content,
+	}
 }
 
 func (s *replyService) CreateReply(ctx context.Context, r *entities.Reply) (int, error) {
 	if r == nil {
-		return 0, fmt.Errorf("reply is nil")
+		return 0, invalidf("reply is nil")
 	}
 	if r.Body == "" {
-		return 0, fmt.Errorf("body is empty")
+		return 0, invalidf("body is empty")
+	}
+	thread, err := ensureThreadOpen(ctx, s.threads, r.ThreadID)
+	if err != nil {
+		return 0, err
+	}
+	var parent *entities.Reply
+	if r.ParentID != nil {
+		parent, err = s.repo.GetReplyByID(ctx, *r.ParentID)
+		if err != nil || parent == nil {
+			return 0, invalidf("parent reply not found")
+		}
+		if parent.ThreadID != r.ThreadID {
+			return 0, invalidf("parent reply belongs to another thread")
+		}
+	}
+	cat, err := s.categories.GetCategoryByID(ctx, thread.CategoryID)
+	if err != nil {
+		return 0, fmt.Errorf("get category: %w", err)
 	}
-	return s.repo.CreateReply(ctx, r)
+	if cat != nil {
+		if err := checkCanPost(ctx, s.users, cat, r.UserID); err != nil {
+			return 0, err
+		}
+	}
+	if r.AttachmentIDs, err = checkAttachments(ctx, s.attachments, r.UserID, r.AttachmentIDs); err != nil {
+		return 0, err
+	}
+	id, err := s.repo.CreateReply(ctx, r)
+	if errors.Is(err, repositories.ErrAttachmentsUnavailable) {
+		return 0, errAttachmentsTaken
+	}
+	if err != nil {
+		return 0, err
+	}
+	// repliers follow responses to their own posts; the thread author already watches everything
+	autoWatch(ctx, s.subs, r.UserID, r.ThreadID, entities.WatchRepliesToMe)
+	notify(ctx, s.notifier, s.replyNotifications(ctx, thread, parent, r, id)...)
+	recordMentions(ctx, s.users, s.mentions, s.notifier, r.UserID, r.ThreadID, &id, r.Body, true)
+	return id, nil
+}
+
+// replyNotifications tells the author of the post being answered, unless they muted the
+// thread, and everyone watching all of the thread's activity. Each user gets at most one.
+func (s *replyService) replyNotifications(ctx context.Context, thread *entities.Thread, parent *entities.Reply, r *entities.Reply, id int) []*entities.Notification {
+	if s.notifier == nil {
+		return nil
+	}
+	actorID, threadID, replyID := r.UserID, thread.ID, id
+	build := func(userID int, kind entities.NotificationKind) *entities.Notification {
+		return &entities.Notification{UserID: userID, Kind: kind, ActorID: &actorID, ThreadID: &threadID, ReplyID: &replyID}
+	}
+	seen := map[int]bool{actorID: true}
+	var out []*entities.Notification
+
+	target, kind := thread.UserID, entities.NotifyReplyToThread
+	if parent != nil {
+		target, kind = parent.UserID, entities.NotifyReplyToReply
+	}
+	if !seen[target] {
+		seen[target] = true
+		muted := false
+		if s.subs != nil {
+			if sub, err := s.subs.GetSubscription(ctx, target, threadID); err == nil && sub != nil {
+				muted = sub.Level == entities.WatchMuted
+			}
+		}
+		if !muted {
+			out = append(out, build(target, kind))
+		}
+	}
+	if s.subs != nil {
+		watchers, err := s.subs.ListWatchers(ctx, threadID)
+		if err != nil {
+			log.Printf("list watchers of thread %d: %v", threadID, err)
+		}
+		for _, w := range watchers {
+			if w.Level == entities.WatchAll && !seen[w.UserID] {
+				seen[w.UserID] = true
+				out = append(out, build(w.UserID, entities.NotifyReplyToThread))
+			}
+		}
+	}
+	return out
 }
 
 func (s *replyService) GetRepliesByThread(ctx context.Context, threadID int) ([]entities.Reply, error) {
-	return s.repo.GetRepliesByThread(ctx, threadID)
+	if _, err := visibleThread(ctx, s.threads, s.users, threadID); err != nil {
+		return nil, err
+	}
+	reps, err := s.repo.GetRepliesByThread(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	ptrs := make([]*entities.Reply, len(reps))
+	for i := range reps {
+		reps[i].BodyHTML = s.content.Render(ctx, reps[i].Body)
+		ptrs[i] = &reps[i]
+	}
+	fillReplyMentions(ctx, s.mentions, ptrs)
+	fillReplyAttachments(ctx, s.attachments, ptrs)
+	fillReplyReactions(ctx, s.reactions, ptrs)
+	fillReplyVotes(ctx, s.votes, ptrs)
+	return reps, nil
+}
+
+const (
+	DefaultReplyTreeDepth = 3
+	MaxReplyTreeDepth     = 10
+	DefaultReplyPageSize  = 20
+	MaxReplyPageSize      = 100
+	DefaultReplyChildren  = 5
+	MaxReplyChildren      = 50
+)
+
+func (s *replyService) GetReplyTree(ctx context.Context, threadID int, opts entities.ReplyTreeOptions, cursor string) (*entities.ReplyTree, error) {
+	opts.MaxDepth = clampInt(opts.MaxDepth, DefaultReplyTreeDepth, MaxReplyTreeDepth)
+	opts.Limit = clampInt(opts.Limit, DefaultReplyPageSize, MaxReplyPageSize)
+	opts.ChildLimit = clampInt(opts.ChildLimit, DefaultReplyChildren, MaxReplyChildren)
+
+	thread, err := visibleThread(ctx, s.threads, s.users, threadID)
+	if err != nil {
+		return nil, err
+	}
+	var start entities.ReplyCursor
+	if cursor != "" {
+		if err := decodeCursor(cursor, &start); err != nil {
+			return nil, err
+		}
+		if start.At != nil && start.ID == 0 {
+			return nil, ErrInvalidCursor
+		}
+	}
+	tree := &entities.ReplyTree{ThreadID: threadID, Replies: []*entities.ReplyNode{}}
+	if start.Parent != 0 {
+		parentID := start.Parent
+		tree.ParentID = &parentID
+	}
+
+	// top level: fetch one extra row to learn whether another page exists
+	var after *entities.ReplyCursor
+	if start.At != nil {
+		after = &start
+	}
+	level, err := s.repo.ListReplyChildren(ctx, threadID, []int{start.Parent}, after, opts.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list replies: %w", err)
+	}
+	if len(level) > opts.Limit {
+		level = level[:opts.Limit]
+		if tree.NextCursor, err = replyCursorAfter(start.Parent, level[len(level)-1]); err != nil {
+			return nil, err
+		}
+	}
+	tree.Replies = append(tree.Replies, level...)
+	var all []*entities.Reply
+	// the accepted answer leads the first top-level page wherever it sits in the tree
+	if start.Parent == 0 && start.At == nil && thread.AcceptedReplyID != nil {
+		if rep, err := s.repo.GetReplyByID(ctx, *thread.AcceptedReplyID); err == nil && rep != nil {
+			rep.BodyHTML = s.content.Render(ctx, rep.Body)
+			tree.Accepted = &entities.ReplyNode{Reply: *rep}
+			all = append(all, &tree.Accepted.Reply)
+		}
+	}
+
+	// expand one level per query; children beyond ChildLimit or MaxDepth get a cursor instead
+	for depth := 1; len(level) > 0; depth++ {
+		var parents []int
+		byID := make(map[int]*entities.ReplyNode, len(level))
+		for _, n := range level {
+			n.BodyHTML = s.content.Render(ctx, n.Body)
+			all = append(all, &n.Reply)
+			if n.ChildCount > 0 {
+				parents = append(parents, n.ID)
+				byID[n.ID] = n
+			}
+		}
+		if len(parents) == 0 {
+			break
+		}
+		if depth >= opts.MaxDepth {
+			for _, n := range byID {
+				if n.MoreChildren, err = encodeCursor(entities.ReplyCursor{Parent: n.ID}); err != nil {
+					return nil, fmt.Errorf("encode cursor: %w", err)
+				}
+			}
+			break
+		}
+		children, err := s.repo.ListReplyChildren(ctx, threadID, parents, nil, opts.ChildLimit)
+		if err != nil {
+			return nil, fmt.Errorf("list replies: %w", err)
+		}
+		for _, ch := range children {
+			if ch.ParentID == nil || byID[*ch.ParentID] == nil {
+				continue
+			}
+			p := byID[*ch.ParentID]
+			p.Children = append(p.Children, ch)
+		}
+		for _, n := range byID {
+			if len(n.Children) > 0 && len(n.Children) < n.ChildCount {
+				if n.MoreChildren, err = replyCursorAfter(n.ID, n.Children[len(n.Children)-1]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		level = children
+	}
+	fillReplyMentions(ctx, s.mentions, all)
+	fillReplyAttachments(ctx, s.attachments, all)
+	fillReplyReactions(ctx, s.reactions, all)
+	fillReplyVotes(ctx, s.votes, all)
+	return tree, nil
+}
+
+// replyCursorAfter encodes the position just after last among parent's children.
+func replyCursorAfter(parent int, last *entities.ReplyNode) (string, error) {
+	at := last.CreatedAt
+	c, err := encodeCursor(entities.ReplyCursor{Parent: parent, ID: last.ID, At: &at})
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return c, nil
+}
+
+// clampInt returns def when v is not positive and max when v exceeds it.
+func clampInt(v, def, max int) int {
+	if v <= 0 {
+		return def
+	}
+	if v > max {
+		return max
+	}
+	return v
 }
 
 func (s *replyService) GetReplyByID(ctx context.Context, id int) (*entities.Reply, error) {
-	return s.repo.GetReplyByID(ctx, id)
+	rep, err := s.repo.GetReplyByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rep.BodyHTML = s.content.Render(ctx, rep.Body)
+	fillReplyMentions(ctx, s.mentions, []*entities.Reply{rep})
+	fillReplyAttachments(ctx, s.attachments, []*entities.Reply{rep})
+	fillReplyReactions(ctx, s.reactions, []*entities.Reply{rep})
+	return rep, nil
 }
 
-func (s *replyService) DeleteReply(ctx context.Context, id int, actorUserID int, isAdmin bool) error {
+func (s *replyService) DeleteReply(ctx context.Context, id int, actorUserID int, isAdmin bool) (int, error) {
 	rep, err := s.repo.GetReplyByID(ctx, id)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if rep == nil {
-		return fmt.Errorf("reply not found")
+		return 0, notFoundf("reply not found")
 	}
 	// If actor is admin, allow delete. Otherwise, only allow if actorUserID == rep.UserID
 	if !isAdmin && actorUserID != rep.UserID {
-		return fmt.Errorf("forbidden: cannot delete others' replies")
+		return 0, forbiddenf("cannot delete others' replies")
+	}
+	if err := s.repo.DeleteReply(ctx, id); err != nil {
+		return 0, err
 	}
-	return s.repo.DeleteReply(ctx, id)
+	return rep.ThreadID, nil
 }
 
-func (s *replyService) UpdateReply(ctx context.Context, r *entities.Reply, actorUserID int, isAdmin bool) error {
+func (s *replyService) UpdateReply(ctx context.Context, r *entities.Reply, edit entities.EditInfo, isAdmin bool) error {
 	if r == nil {
-		return fmt.Errorf("reply is nil")
+		return invalidf("reply is nil")
 	}
 	if r.Body == "" {
-		return fmt.Errorf("body is empty")
+		return invalidf("body is empty")
 	}
 	// fetch existing reply to check ownership
 	existing, err := s.repo.GetReplyByID(ctx, r.ID)
@@ -71,10 +356,21 @@ func (s *replyService) UpdateReply(ctx context.Context, r *entities.Reply, actor
 		return err
 	}
 	if existing == nil {
-		return fmt.Errorf("reply not found")
+		return notFoundf("reply not found")
 	}
-	if !isAdmin && actorUserID != existing.UserID {
-		return fmt.Errorf("forbidden: cannot edit others' replies")
+	if !isAdmin && edit.EditorID != existing.UserID {
+		return forbiddenf("cannot edit others' replies")
+	}
+	// edits on a locked thread are reserved for admins
+	if !isAdmin {
+		if _, err := ensureThreadOpen(ctx, s.threads, existing.ThreadID); err != nil {
+			return err
+		}
+	}
+	edit.Reason = strings.TrimSpace(edit.Reason)
+	if err := s.repo.UpdateReply(ctx, r, edit); err != nil {
+		return err
 	}
-	return s.repo.UpdateReply(ctx, r)
+	recordMentions(ctx, s.users, s.mentions, s.notifier, existing.UserID, existing.ThreadID, &r.ID, r.Body, true)
+	return nil
 }
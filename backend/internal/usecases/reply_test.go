@@ -0,0 +1,180 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeReplyRepo struct {
+	replies map[int]*entities.Reply
+	// attachments, when set, links AttachmentIDs on create
+	attachments *fakeAttachmentRepo
+}
+
+func newFakeReplyRepo() *fakeReplyRepo {
+	return &fakeReplyRepo{replies: map[int]*entities.Reply{}}
+}
+
+func (f *fakeReplyRepo) CreateReply(ctx context.Context, r *entities.Reply) (int, error) {
+	id := len(f.replies) + 1
+	if f.attachments != nil {
+		if err := f.attachments.link(r.UserID, r.AttachmentIDs, nil, &id); err != nil {
+			return 0, err
+		}
+	}
+	r.ID = id
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Date(2025, 1, 1, 0, 0, r.ID, 0, time.UTC)
+	}
+	f.replies[r.ID] = r
+	return r.ID, nil
+}
+func (f *fakeReplyRepo) GetRepliesByThread(ctx context.Context, threadID int) ([]entities.Reply, error) {
+	return nil, nil
+}
+func (f *fakeReplyRepo) GetReplyByID(ctx context.Context, id int) (*entities.Reply, error) {
+	return f.replies[id], nil
+}
+func (f *fakeReplyRepo) DeleteReply(ctx context.Context, id int) error { return nil }
+func (f *fakeReplyRepo) UpdateReply(ctx context.Context, r *entities.Reply, edit entities.EditInfo) error {
+	cur := *f.replies[r.ID]
+	cur.Body = r.Body
+	f.replies[r.ID] = &cur
+	return nil
+}
+func (f *fakeReplyRepo) ListReplyChildren(ctx context.Context, threadID int, parentIDs []int, after *entities.ReplyCursor, limit int) ([]*entities.ReplyNode, error) {
+	var out []*entities.ReplyNode
+	for _, pid := range parentIDs {
+		var kids []*entities.Reply
+		for _, r := range f.replies {
+			parent := 0
+			if r.ParentID != nil {
+				parent = *r.ParentID
+			}
+			if r.ThreadID == threadID && parent == pid && (after == nil || r.ID > after.ID) {
+				kids = append(kids, r)
+			}
+		}
+		sort.Slice(kids, func(i, j int) bool { return kids[i].ID < kids[j].ID })
+		if len(kids) > limit {
+			kids = kids[:limit]
+		}
+		for _, k := range kids {
+			n := &entities.ReplyNode{Reply: *k}
+			for _, r := range f.replies {
+				if r.ParentID != nil && *r.ParentID == k.ID {
+					n.ChildCount++
+				}
+			}
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// --- tests ---
+func TestReplyService_TreeDepthAndCursors(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{Title: "t", Body: "b"})
+	repo := newFakeReplyRepo()
+	add := func(parent int) int {
+		r := &entities.Reply{ThreadID: threadID, Body: "r"}
+		if parent != 0 {
+			r.ParentID = &parent
+		}
+		id, _ := repo.CreateReply(ctx, r)
+		return id
+	}
+	// 1 -> (2 -> 4 -> 5), 3
+	root := add(0)
+	child := add(root)
+	add(root)
+	grandchild := add(child)
+	add(grandchild)
+	svc := NewReplyService(ReplyServiceDeps{Repo: repo, Threads: threads})
+
+	tree, err := svc.GetReplyTree(ctx, threadID, entities.ReplyTreeOptions{MaxDepth: 2, ChildLimit: 1}, "")
+	if err != nil {
+		t.Fatalf("GetReplyTree: %v", err)
+	}
+	if len(tree.Replies) != 1 || tree.Replies[0].ID != root {
+		t.Fatalf("expected a single root, got %+v", tree.Replies)
+	}
+	top := tree.Replies[0]
+	if top.ChildCount != 2 || len(top.Children) != 1 || top.MoreChildren == "" {
+		t.Fatalf("expected 1 of 2 children plus a cursor, got %d of %d (cursor %q)", len(top.Children), top.ChildCount, top.MoreChildren)
+	}
+	// depth 2 stops at the child; its own children are only reachable by cursor
+	deep := top.Children[0]
+	if len(deep.Children) != 0 || deep.MoreChildren == "" {
+		t.Fatalf("expected depth cut-off with a cursor, got %+v", deep)
+	}
+
+	more, err := svc.GetReplyTree(ctx, threadID, entities.ReplyTreeOptions{MaxDepth: 2}, top.MoreChildren)
+	if err != nil {
+		t.Fatalf("load more children: %v", err)
+	}
+	if len(more.Replies) != 1 || more.Replies[0].ID == child || more.ParentID == nil || *more.ParentID != root {
+		t.Fatalf("expected the remaining sibling under %d, got %+v", root, more.Replies)
+	}
+	sub, err := svc.GetReplyTree(ctx, threadID, entities.ReplyTreeOptions{}, deep.MoreChildren)
+	if err != nil {
+		t.Fatalf("load subtree: %v", err)
+	}
+	if len(sub.Replies) != 1 || sub.Replies[0].ID != grandchild || len(sub.Replies[0].Children) != 1 {
+		t.Fatalf("unexpected subtree: %+v", sub.Replies)
+	}
+}
+
+func TestReplyService_CreateRejectsForeignParent(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	a, _ := threads.CreateThread(ctx, &entities.Thread{Title: "a", Body: "b", CategoryID: 1})
+	b, _ := threads.CreateThread(ctx, &entities.Thread{Title: "b", Body: "b", CategoryID: 1})
+	cats := newFakeCategoryRepo()
+	cats.CreateCategory(ctx, &entities.Category{Slug: "general"})
+	repo := newFakeReplyRepo()
+	other, _ := repo.CreateReply(ctx, &entities.Reply{ThreadID: b, Body: "r"})
+	svc := NewReplyService(ReplyServiceDeps{Repo: repo, Threads: threads, Categories: cats, Users: newFakeUserRepo()})
+
+	_, err := svc.CreateReply(ctx, &entities.Reply{ThreadID: a, UserID: 1, ParentID: &other, Body: "hi"})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a parent in another thread, got %v", err)
+	}
+	if _, err := svc.CreateReply(ctx, &entities.Reply{ThreadID: b, UserID: 1, ParentID: &other, Body: "hi"}); err != nil {
+		t.Fatalf("reply to a parent in the same thread: %v", err)
+	}
+}
+
+func TestReplyService_HiddenOnUnpublishedThreads(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b", Status: entities.ThreadStatusDraft})
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleUser}
+	users.users[3] = &entities.User{ID: 3, Role: entities.RoleAdmin}
+	polls := newFakePollRepo()
+	polls.CreatePoll(ctx, &entities.Poll{ThreadID: threadID, Question: "q?", Options: []entities.PollOption{{Text: "a"}, {Text: "b"}}})
+	svc := NewReplyService(ReplyServiceDeps{Repo: newFakeReplyRepo(), Threads: threads, Users: users})
+	pollSvc := NewPollService(polls, threads, users)
+
+	for viewer, visible := range map[int]bool{0: false, 1: true, 2: false, 3: true} {
+		vctx := WithViewer(ctx, viewer)
+		_, err := svc.GetRepliesByThread(vctx, threadID)
+		_, treeErr := svc.GetReplyTree(vctx, threadID, entities.ReplyTreeOptions{}, "")
+		_, pollErr := pollSvc.GetPoll(vctx, threadID)
+		for _, e := range []error{err, treeErr, pollErr} {
+			if visible != (e == nil) || (!visible && !errors.Is(e, ErrNotFound)) {
+				t.Fatalf("viewer %d: got %v, want visible=%v", viewer, e, visible)
+			}
+		}
+	}
+}
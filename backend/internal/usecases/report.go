@@ -15,18 +15,21 @@ type ReportService interface {
 }
 
 type reportService struct {
-	repo repositories.ReportRepository
+	repo     repositories.ReportRepository
+	threads  repositories.ThreadRepository
+	replies  repositories.ReplyRepository
+	notifier Notifier // optional
 }
 
-func NewReportService(repo repositories.ReportRepository) ReportService {
-	return &reportService{repo: repo}
+func NewReportService(repo repositories.ReportRepository, threads repositories.ThreadRepository, replies repositories.ReplyRepository, notifier Notifier) ReportService {
+	return &reportService{repo: repo, threads: threads, replies: replies, notifier: notifier}
 }
 
 func (s *reportService) CreateReport(ctx context.Context, r *entities.Report) (string, error) {
 	if r == nil {
 		return "", fmt.Errorf("report is nil")
 	}
-	if r.Kind != "thread" && r.Kind != "user" {
+	if r.Kind != "thread" && r.Kind != "reply" && r.Kind != "user" {
 		return "", fmt.Errorf("invalid kind")
 	}
 	if r.TargetID == 0 {
@@ -43,5 +46,50 @@ func (s *reportService) UpdateReportStatus(ctx context.Context, id string, statu
 	if status != "open" && status != "resolved" && status != "dismissed" {
 		return fmt.Errorf("invalid status")
 	}
-	return s.repo.UpdateReportStatus(ctx, id, status, resolvedBy)
+	var before *entities.Report
+	if status == "resolved" && s.notifier != nil {
+		// looked up first so that only the transition to resolved notifies
+		before, _ = s.repo.GetReportByID(ctx, id)
+	}
+	if err := s.repo.UpdateReportStatus(ctx, id, status, resolvedBy); err != nil {
+		return err
+	}
+	if before != nil && before.Status != "resolved" {
+		notify(ctx, s.notifier, s.resolvedNotifications(ctx, before, resolvedBy)...)
+	}
+	return nil
+}
+
+// resolvedNotifications tells the reporter their report was acted on and the reported
+// user (or the reported thread's or reply's author) that a moderator took action.
+func (s *reportService) resolvedNotifications(ctx context.Context, r *entities.Report, moderatorID *int) []*entities.Notification {
+	var out []*entities.Notification
+	if r.ReporterID != nil {
+		out = append(out, &entities.Notification{UserID: *r.ReporterID, Kind: entities.NotifyReportResolved, ActorID: moderatorID, ReportID: r.ID})
+	}
+	switch r.Kind {
+	case "user":
+		out = append(out, &entities.Notification{UserID: r.TargetID, Kind: entities.NotifyModAction, ActorID: moderatorID, Message: "a moderator acted on a report about your account"})
+	case "thread":
+		if s.threads == nil {
+			break
+		}
+		t, err := s.threads.GetThreadByID(ctx, r.TargetID)
+		if err != nil || t == nil {
+			break
+		}
+		threadID := t.ID
+		out = append(out, &entities.Notification{UserID: t.UserID, Kind: entities.NotifyModAction, ActorID: moderatorID, ThreadID: &threadID, Message: "a moderator acted on a report about your thread"})
+	case "reply":
+		if s.replies == nil {
+			break
+		}
+		rep, err := s.replies.GetReplyByID(ctx, r.TargetID)
+		if err != nil || rep == nil {
+			break
+		}
+		threadID, replyID := rep.ThreadID, rep.ID
+		out = append(out, &entities.Notification{UserID: rep.UserID, Kind: entities.NotifyModAction, ActorID: moderatorID, ThreadID: &threadID, ReplyID: &replyID, Message: "a moderator acted on a report about your reply"})
+	}
+	return out
 }
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeReportRepo struct {
+	reports map[string]*entities.Report
+}
+
+func (f *fakeReportRepo) CreateReport(ctx context.Context, r *entities.Report) (string, error) {
+	r.ID = fmt.Sprint(len(f.reports) + 1)
+	r.Status = "open"
+	cp := *r
+	f.reports[r.ID] = &cp
+	return r.ID, nil
+}
+func (f *fakeReportRepo) GetReports(ctx context.Context, kind *string) ([]*entities.Report, error) {
+	var out []*entities.Report
+	for _, r := range f.reports {
+		if kind == nil || r.Kind == *kind {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+func (f *fakeReportRepo) GetReportByID(ctx context.Context, id string) (*entities.Report, error) {
+	if r, ok := f.reports[id]; ok {
+		cp := *r
+		return &cp, nil
+	}
+	return nil, nil
+}
+func (f *fakeReportRepo) UpdateReportStatus(ctx context.Context, id string, status string, resolvedBy *int) error {
+	f.reports[id].Status = status
+	return nil
+}
+
+// --- tests ---
+func TestReportService_ResolvedReplyReportNotifiesAuthor(t *testing.T) {
+	ctx := context.Background()
+	replies := newFakeReplyRepo()
+	replyID, _ := replies.CreateReply(ctx, &entities.Reply{ThreadID: 7, UserID: 3, Body: "spam"})
+	notes := &fakeNotificationRepo{}
+	svc := NewReportService(&fakeReportRepo{reports: map[string]*entities.Report{}}, newFakeThreadRepo(), replies, NewNotificationService(notes, nil))
+
+	reporter, moderator := 2, 1
+	id, err := svc.CreateReport(ctx, &entities.Report{ReporterID: &reporter, Kind: "reply", TargetID: replyID})
+	if err != nil {
+		t.Fatalf("create report: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.UpdateReportStatus(ctx, id, "resolved", &moderator); err != nil {
+			t.Fatalf("resolve: %v", err)
+		}
+	}
+	if len(notes.ns) != 2 {
+		t.Fatalf("expected the reporter and the reply's author to be notified once, got %d notifications", len(notes.ns))
+	}
+	got := notes.ns[1]
+	if got.UserID != 3 || got.Kind != entities.NotifyModAction || got.ReplyID == nil || *got.ReplyID != replyID || *got.ThreadID != 7 {
+		t.Fatalf("expected a mod_action for the reply's author, got %+v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+// RevisionService exposes edit history for threads and replies. Revisions are recorded by
+// ThreadService.UpdateThread and ReplyService.UpdateReply; this service reads, diffs and reverts them.
+type RevisionService interface {
+	ListRevisions(ctx context.Context, kind entities.RevisionKind, targetID int) ([]entities.Revision, error)
+	// Diff compares two versions of a post; a revision id of 0 stands for the current version
+	Diff(ctx context.Context, kind entities.RevisionKind, targetID, from, to int) (*entities.RevisionDiff, error)
+	// Revert restores the content of a revision; the content being replaced becomes a new revision
+	Revert(ctx context.Context, kind entities.RevisionKind, targetID, revisionID int, edit entities.EditInfo) error
+}
+
+type revisionService struct {
+	revisions repositories.RevisionRepository
+	threads   repositories.ThreadRepository
+	replies   repositories.ReplyRepository
+	// reverts are written through the services so they get the same checks and side effects
+	// (tag normalization, mentions, cache invalidation) as any other edit
+	threadSvc ThreadService
+	replySvc  ReplyService
+}
+
+func NewRevisionService(revisions repositories.RevisionRepository, threads repositories.ThreadRepository, replies repositories.ReplyRepository, threadSvc ThreadService, replySvc ReplyService) RevisionService {
+	return &revisionService{revisions: revisions, threads: threads, replies: replies, threadSvc: threadSvc, replySvc: replySvc}
+}
+
+func (s *revisionService) ListRevisions(ctx context.Context, kind entities.RevisionKind, targetID int) ([]entities.Revision, error) {
+	revs, err := s.revisions.ListRevisions(ctx, kind, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if revs == nil {
+		revs = []entities.Revision{}
+	}
+	return revs, nil
+}
+
+// version loads the content of a post at revision id, or its current content when id is 0.
+func (s *revisionService) version(ctx context.Context, kind entities.RevisionKind, targetID, id int) (*entities.Revision, error) {
+	if id != 0 {
+		rev, err := s.revisions.GetRevision(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if rev == nil || rev.Kind != kind || rev.TargetID != targetID {
+			return nil, notFoundf("revision %d not found", id)
+		}
+		return rev, nil
+	}
+	switch kind {
+	case entities.RevisionKindThread:
+		t, err := s.threads.GetThreadByID(ctx, targetID)
+		if err != nil || t == nil {
+			return nil, notFoundf("thread not found")
+		}
+		return &entities.Revision{Kind: kind, TargetID: t.ID, Title: t.Title, Body: t.Body, Tags: t.Tags}, nil
+	case entities.RevisionKindReply:
+		r, err := s.replies.GetReplyByID(ctx, targetID)
+		if err != nil || r == nil {
+			return nil, notFoundf("reply not found")
+		}
+		return &entities.Revision{Kind: kind, TargetID: r.ID, Body: r.Body}, nil
+	default:
+		return nil, invalidf("invalid revision kind")
+	}
+}
+
+func (s *revisionService) Diff(ctx context.Context, kind entities.RevisionKind, targetID, from, to int) (*entities.RevisionDiff, error) {
+	a, err := s.version(ctx, kind, targetID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.version(ctx, kind, targetID, to)
+	if err != nil {
+		return nil, err
+	}
+	d := &entities.RevisionDiff{From: from, To: to, Body: diffLines(a.Body, b.Body)}
+	if kind == entities.RevisionKindThread {
+		d.Title = diffLines(a.Title, b.Title)
+		d.TagsAdded, d.TagsRemoved = diffTags(a.Tags, b.Tags)
+	}
+	return d, nil
+}
+
+func (s *revisionService) Revert(ctx context.Context, kind entities.RevisionKind, targetID, revisionID int, edit entities.EditInfo) error {
+	if revisionID == 0 {
+		return invalidf("revision id is required")
+	}
+	if edit.EditorID == 0 {
+		return invalidf("editor is required")
+	}
+	rev, err := s.version(ctx, kind, targetID, revisionID)
+	if err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("revert to revision %d", rev.ID)
+	if r := strings.TrimSpace(edit.Reason); r != "" {
+		reason += ": " + r
+	}
+	edit.Reason = reason
+
+	switch kind {
+	case entities.RevisionKindThread:
+		t := &entities.Thread{ID: targetID, Title: rev.Title, Body: rev.Body, Tags: rev.Tags}
+		if err := s.threadSvc.UpdateThread(ctx, t, edit); err != nil {
+			return fmt.Errorf("revert thread: %w", err)
+		}
+	case entities.RevisionKindReply:
+		// reverting is admin-only (see the router)
+		r := &entities.Reply{ID: targetID, Body: rev.Body}
+		if err := s.replySvc.UpdateReply(ctx, r, edit, true); err != nil {
+			return fmt.Errorf("revert reply: %w", err)
+		}
+	}
+	return nil
+}
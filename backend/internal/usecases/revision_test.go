@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeRevisionRepo struct {
+	revs map[int]*entities.Revision
+}
+
+func (f *fakeRevisionRepo) ListRevisions(ctx context.Context, kind entities.RevisionKind, targetID int) ([]entities.Revision, error) {
+	var out []entities.Revision
+	for _, r := range f.revs {
+		if r.Kind == kind && r.TargetID == targetID {
+			out = append(out, *r)
+		}
+	}
+	return out, nil
+}
+func (f *fakeRevisionRepo) GetRevision(ctx context.Context, id int) (*entities.Revision, error) {
+	return f.revs[id], nil
+}
+
+// --- tests ---
+func TestRevisionService_RevertGoesThroughServices(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "new", Body: "new body", Tags: []string{"rust"}, IsLocked: true})
+	replies := newFakeReplyRepo()
+	replyID, _ := replies.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 2, Body: "new reply"})
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Username: "alice"}
+	users.users[3] = &entities.User{ID: 3, Username: "bob"}
+	users.users[9] = &entities.User{ID: 9, Username: "admin", Role: entities.RoleAdmin}
+	mentions := newFakeMentionRepo()
+	related := &fakeRelatedCache{lists: map[int][]int{threadID: {42}}}
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Users: users, Mentions: mentions, Related: related})
+	replySvc := NewReplyService(ReplyServiceDeps{Repo: replies, Threads: threads, Users: users, Mentions: mentions})
+	revs := &fakeRevisionRepo{revs: map[int]*entities.Revision{
+		1: {ID: 1, Kind: entities.RevisionKindThread, TargetID: threadID, Title: "old", Body: "ask @bob", Tags: []string{"Go"}},
+		2: {ID: 2, Kind: entities.RevisionKindReply, TargetID: replyID, Body: "old reply"},
+	}}
+	svc := NewRevisionService(revs, threads, replies, threadSvc, replySvc)
+
+	if err := svc.Revert(ctx, entities.RevisionKindThread, threadID, 1, entities.EditInfo{}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput without an editor, got %v", err)
+	}
+	// the thread is locked, so only an admin's revert goes through UpdateThread
+	if err := svc.Revert(ctx, entities.RevisionKindThread, threadID, 1, entities.EditInfo{EditorID: 1}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for a non-admin on a locked thread, got %v", err)
+	}
+	if err := svc.Revert(ctx, entities.RevisionKindThread, threadID, 1, entities.EditInfo{EditorID: 9}); err != nil {
+		t.Fatalf("revert thread: %v", err)
+	}
+	got := threads.threads[threadID]
+	if got.Title != "old" || got.Body != "ask @bob" || strings.Join(got.Tags, ",") != "go" {
+		t.Fatalf("expected the revision's content with normalized tags, got %+v", got)
+	}
+	if _, ok := related.lists[threadID]; ok {
+		t.Fatalf("expected the tag change to drop the cached related list")
+	}
+	if ms := mentions.posts[mentionKey(threadID, nil)]; len(ms) != 1 || ms[0].UserID != 3 {
+		t.Fatalf("expected the reverted body's mention to be recorded, got %+v", ms)
+	}
+
+	if err := svc.Revert(ctx, entities.RevisionKindReply, replyID, 1, entities.EditInfo{EditorID: 9}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another post's revision, got %v", err)
+	}
+	if err := svc.Revert(ctx, entities.RevisionKindReply, replyID, 2, entities.EditInfo{EditorID: 9}); err != nil {
+		t.Fatalf("revert reply: %v", err)
+	}
+	if replies.replies[replyID].Body != "old reply" {
+		t.Fatalf("expected the reply body to be restored, got %q", replies.replies[replyID].Body)
+	}
+}
@@ -0,0 +1,98 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+const (
+	DefaultSearchPageSize = 20
+	MaxSearchPageSize     = 50
+	maxSearchQueryLen     = 200
+	// maxSearchOffset bounds how deep clients can page; rank-ordered results past this are noise
+	maxSearchOffset = 1000
+)
+
+// SearchService is the application port for full-text search over threads and replies.
+type SearchService interface {
+	Search(ctx context.Context, q entities.SearchQuery, cursor string) (*entities.SearchPage, error)
+}
+
+type searchService struct {
+	repo repositories.SearchRepository
+}
+
+func NewSearchService(repo repositories.SearchRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+// searchCursor is the decoded form of a search next_cursor. Ranked results have no stable
+// keyset, so paging is by offset within the same query.
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+func (s *searchService) Search(ctx context.Context, q entities.SearchQuery, cursor string) (*entities.SearchPage, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return nil, invalidf("query is required")
+	}
+	if len(q.Text) > maxSearchQueryLen {
+		return nil, invalidf("query must be at most %d characters", maxSearchQueryLen)
+	}
+	switch q.Kind {
+	case "":
+		q.Kind = entities.SearchKindAll
+	case entities.SearchKindAll, entities.SearchKindThread, entities.SearchKindReply:
+	default:
+		return nil, invalidf("invalid type")
+	}
+	q.Author = strings.TrimSpace(q.Author)
+	// tags may be aliases; the repository resolves them to canonical names as ListThreads does
+	tags, err := normalizeTags(q.Tags)
+	if err != nil {
+		return nil, err
+	}
+	q.Tags = tags
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchPageSize
+	}
+	if q.Limit > MaxSearchPageSize {
+		q.Limit = MaxSearchPageSize
+	}
+	q.Offset = 0
+	if cursor != "" {
+		var c searchCursor
+		if err := decodeCursor(cursor, &c); err != nil {
+			return nil, err
+		}
+		if c.Offset <= 0 || c.Offset > maxSearchOffset {
+			return nil, ErrInvalidCursor
+		}
+		q.Offset = c.Offset
+	}
+
+	limit := q.Limit
+	q.Limit = limit + 1
+	results, err := s.repo.Search(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	page := &entities.SearchPage{Results: results}
+	if len(results) > limit {
+		page.Results = results[:limit]
+		if next := q.Offset + limit; next <= maxSearchOffset {
+			if page.NextCursor, err = encodeCursor(searchCursor{Offset: next}); err != nil {
+				return nil, fmt.Errorf("encode cursor: %w", err)
+			}
+		}
+	}
+	if page.Results == nil {
+		page.Results = []entities.SearchResult{}
+	}
+	return page, nil
+}
@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeSearchRepo struct {
+	docs    []entities.SearchResult // in rank order
+	queries []entities.SearchQuery
+}
+
+func (f *fakeSearchRepo) Search(ctx context.Context, q entities.SearchQuery) ([]entities.SearchResult, error) {
+	f.queries = append(f.queries, q)
+	var hits []entities.SearchResult
+	for _, d := range f.docs {
+		if !strings.Contains(d.Snippet, q.Text) {
+			continue
+		}
+		if q.Kind != entities.SearchKindAll && d.Kind != q.Kind {
+			continue
+		}
+		if q.Author != "" && d.Author != q.Author {
+			continue
+		}
+		if !hasAllTags(d.Tags, q.Tags) {
+			continue
+		}
+		hits = append(hits, d)
+	}
+	if q.Offset >= len(hits) {
+		return nil, nil
+	}
+	hits = hits[q.Offset:]
+	if len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+func hasAllTags(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			found = found || h == w
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// --- tests ---
+func TestSearchService_ParsesQuery(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeSearchRepo{}
+	svc := NewSearchService(repo)
+
+	for _, q := range []entities.SearchQuery{
+		{Text: "   "},
+		{Text: strings.Repeat("x", maxSearchQueryLen+1)},
+		{Text: "go", Kind: "user"},
+		{Text: "go", Tags: []string{"!!!"}},
+	} {
+		if _, err := svc.Search(ctx, q, ""); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("expected ErrInvalidInput for %+v, got %v", q, err)
+		}
+	}
+	if len(repo.queries) != 0 {
+		t.Fatalf("expected invalid queries to stop before the repository, got %d calls", len(repo.queries))
+	}
+
+	if _, err := svc.Search(ctx, entities.SearchQuery{Text: "  go  ", Author: " alice ", Tags: []string{"Web Dev", "web-dev", "Go"}, Limit: 500}, ""); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	got := repo.queries[0]
+	if got.Text != "go" || got.Kind != entities.SearchKindAll || got.Author != "alice" || got.Offset != 0 {
+		t.Fatalf("expected a trimmed query across all kinds, got %+v", got)
+	}
+	if strings.Join(got.Tags, ",") != "web-dev,go" {
+		t.Fatalf("expected normalized, deduplicated tags, got %v", got.Tags)
+	}
+	if got.Limit != MaxSearchPageSize+1 {
+		t.Fatalf("expected the limit capped at %d plus one look-ahead row, got %d", MaxSearchPageSize, got.Limit)
+	}
+}
+
+func TestSearchService_FiltersAndCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeSearchRepo{}
+	for i := 1; i <= 5; i++ {
+		repo.docs = append(repo.docs, entities.SearchResult{Kind: entities.SearchKindThread, ThreadID: i, Author: "alice", Tags: []string{"go"}, Snippet: "golang"})
+	}
+	replyID := 9
+	repo.docs = append(repo.docs,
+		entities.SearchResult{Kind: entities.SearchKindReply, ThreadID: 1, ReplyID: &replyID, Author: "bob", Tags: []string{"go"}, Snippet: "golang"},
+		entities.SearchResult{Kind: entities.SearchKindThread, ThreadID: 6, Author: "bob", Tags: []string{"go", "http"}, Snippet: "golang"},
+	)
+	svc := NewSearchService(repo)
+
+	page, err := svc.Search(ctx, entities.SearchQuery{Text: "golang", Author: "bob"}, "")
+	if err != nil || len(page.Results) != 2 || page.NextCursor != "" {
+		t.Fatalf("expected bob's thread and reply on one page, got %+v (%v)", page, err)
+	}
+	page, err = svc.Search(ctx, entities.SearchQuery{Text: "golang", Kind: entities.SearchKindReply}, "")
+	if err != nil || len(page.Results) != 1 || *page.Results[0].ReplyID != replyID {
+		t.Fatalf("expected only the reply, got %+v (%v)", page, err)
+	}
+	page, err = svc.Search(ctx, entities.SearchQuery{Text: "golang", Tags: []string{"HTTP", "go"}}, "")
+	if err != nil || len(page.Results) != 1 || page.Results[0].ThreadID != 6 {
+		t.Fatalf("expected only the thread with both tags, got %+v (%v)", page, err)
+	}
+	page, err = svc.Search(ctx, entities.SearchQuery{Text: "nothing"}, "")
+	if err != nil || page.Results == nil || len(page.Results) != 0 {
+		t.Fatalf("expected an empty, non-nil result list, got %+v (%v)", page, err)
+	}
+
+	// page through alice's five threads two at a time
+	var seen []string
+	cursor := ""
+	for {
+		page, err := svc.Search(ctx, entities.SearchQuery{Text: "golang", Author: "alice", Limit: 2}, cursor)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		for _, r := range page.Results {
+			seen = append(seen, fmt.Sprint(r.ThreadID))
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if strings.Join(seen, ",") != "1,2,3,4,5" {
+		t.Fatalf("expected every thread exactly once across pages, got %v", seen)
+	}
+
+	for _, bad := range []string{"not-a-cursor", mustCursor(t, searchCursor{Offset: 0}), mustCursor(t, searchCursor{Offset: maxSearchOffset + 1})} {
+		if _, err := svc.Search(ctx, entities.SearchQuery{Text: "golang"}, bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for %q, got %v", bad, err)
+		}
+	}
+}
+
+func mustCursor(t *testing.T, v interface{}) string {
+	t.Helper()
+	c, err := encodeCursor(v)
+	if err != nil {
+		t.Fatalf("encode cursor: %v", err)
+	}
+	return c
+}
@@ -0,0 +1,111 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+const (
+	DefaultWatchingPageSize = 20
+	MaxWatchingPageSize     = 100
+)
+
+type SubscriptionService interface {
+	// Watch subscribes userID to a thread at level, or changes the level of an existing subscription
+	Watch(ctx context.Context, userID, threadID int, level entities.WatchLevel) error
+	Unwatch(ctx context.Context, userID, threadID int) error
+	// ListWatching returns one page of userID's subscriptions; cursor is the previous page's next_cursor
+	ListWatching(ctx context.Context, userID, limit int, cursor string) (*entities.WatchingPage, error)
+	// Watchers returns the thread's subscribers that are not muted, for fanning out thread events
+	Watchers(ctx context.Context, threadID int) ([]*entities.ThreadSubscription, error)
+}
+
+type subscriptionService struct {
+	repo    repositories.SubscriptionRepository
+	threads repositories.ThreadRepository
+	users   repositories.UserRepository
+}
+
+func NewSubscriptionService(repo repositories.SubscriptionRepository, threads repositories.ThreadRepository, users repositories.UserRepository) SubscriptionService {
+	return &subscriptionService{repo: repo, threads: threads, users: users}
+}
+
+func (s *subscriptionService) Watch(ctx context.Context, userID, threadID int, level entities.WatchLevel) error {
+	level, ok := entities.ParseWatchLevel(string(level))
+	if !ok {
+		return invalidf("watch level must be %q, %q or %q", entities.WatchAll, entities.WatchRepliesToMe, entities.WatchMuted)
+	}
+	// a thread can be watched by whoever can read it, so admins may follow drafts too
+	if _, err := visibleThread(WithViewer(ctx, userID), s.threads, s.users, threadID); err != nil {
+		return err
+	}
+	if err := s.repo.Watch(ctx, userID, threadID, level); err != nil {
+		return fmt.Errorf("watch thread: %w", err)
+	}
+	return nil
+}
+
+func (s *subscriptionService) Unwatch(ctx context.Context, userID, threadID int) error {
+	ok, err := s.repo.Unwatch(ctx, userID, threadID)
+	if err != nil {
+		return fmt.Errorf("unwatch thread: %w", err)
+	}
+	if !ok {
+		return notFoundf("not watching thread %d", threadID)
+	}
+	return nil
+}
+
+func (s *subscriptionService) ListWatching(ctx context.Context, userID, limit int, cursor string) (*entities.WatchingPage, error) {
+	limit = clampInt(limit, DefaultWatchingPageSize, MaxWatchingPageSize)
+	var after *entities.WatchCursor
+	if cursor != "" {
+		var c entities.WatchCursor
+		if err := decodeCursor(cursor, &c); err != nil {
+			return nil, err
+		}
+		if c.ThreadID == 0 {
+			return nil, ErrInvalidCursor
+		}
+		after = &c
+	}
+	subs, err := s.repo.ListWatching(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("list watching: %w", err)
+	}
+	page := &entities.WatchingPage{Subscriptions: subs}
+	if len(subs) > limit {
+		page.Subscriptions = subs[:limit]
+		last := page.Subscriptions[limit-1]
+		if page.NextCursor, err = encodeCursor(entities.WatchCursor{At: last.CreatedAt, ThreadID: last.ThreadID}); err != nil {
+			return nil, fmt.Errorf("encode cursor: %w", err)
+		}
+	}
+	if page.Subscriptions == nil {
+		page.Subscriptions = []*entities.ThreadSubscription{}
+	}
+	return page, nil
+}
+
+func (s *subscriptionService) Watchers(ctx context.Context, threadID int) ([]*entities.ThreadSubscription, error) {
+	subs, err := s.repo.ListWatchers(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchers: %w", err)
+	}
+	return subs, nil
+}
+
+// autoWatch subscribes a participant to a thread without overriding a level they chose,
+// muted included. It is best-effort: a failure is logged and never fails the post.
+func autoWatch(ctx context.Context, subs repositories.SubscriptionRepository, userID, threadID int, level entities.WatchLevel) {
+	if subs == nil || userID == 0 {
+		return
+	}
+	if err := subs.WatchIfAbsent(ctx, userID, threadID, level); err != nil {
+		log.Printf("auto-watch thread %d for user %d: %v", threadID, userID, err)
+	}
+}
@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeSubscriptionRepo struct {
+	subs map[[2]int]*entities.ThreadSubscription // (user, thread)
+	tick int
+}
+
+func newFakeSubscriptionRepo() *fakeSubscriptionRepo {
+	return &fakeSubscriptionRepo{subs: map[[2]int]*entities.ThreadSubscription{}}
+}
+
+func (f *fakeSubscriptionRepo) Watch(ctx context.Context, userID, threadID int, level entities.WatchLevel) error {
+	if s, ok := f.subs[[2]int{userID, threadID}]; ok {
+		s.Level = level
+		return nil
+	}
+	return f.WatchIfAbsent(ctx, userID, threadID, level)
+}
+func (f *fakeSubscriptionRepo) WatchIfAbsent(ctx context.Context, userID, threadID int, level entities.WatchLevel) error {
+	key := [2]int{userID, threadID}
+	if _, ok := f.subs[key]; !ok {
+		f.tick++
+		at := time.Date(2025, 1, 1, 0, f.tick, 0, 0, time.UTC)
+		f.subs[key] = &entities.ThreadSubscription{UserID: userID, ThreadID: threadID, Level: level, CreatedAt: at, UpdatedAt: at}
+	}
+	return nil
+}
+func (f *fakeSubscriptionRepo) Unwatch(ctx context.Context, userID, threadID int) (bool, error) {
+	_, ok := f.subs[[2]int{userID, threadID}]
+	delete(f.subs, [2]int{userID, threadID})
+	return ok, nil
+}
+func (f *fakeSubscriptionRepo) GetSubscription(ctx context.Context, userID, threadID int) (*entities.ThreadSubscription, error) {
+	return f.subs[[2]int{userID, threadID}], nil
+}
+func (f *fakeSubscriptionRepo) ListWatching(ctx context.Context, userID int, after *entities.WatchCursor, limit int) ([]*entities.ThreadSubscription, error) {
+	var out []*entities.ThreadSubscription
+	for _, s := range f.subs {
+		if s.UserID == userID && (after == nil || s.CreatedAt.Before(after.At)) {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+func (f *fakeSubscriptionRepo) ListWatchers(ctx context.Context, threadID int) ([]*entities.ThreadSubscription, error) {
+	var out []*entities.ThreadSubscription
+	for _, s := range f.subs {
+		if s.ThreadID == threadID && s.Level != entities.WatchMuted {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// --- tests ---
+func TestSubscriptions_AutoWatchRespectsMute(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	cats := newFakeCategoryRepo()
+	catID, _ := cats.CreateCategory(ctx, &entities.Category{Slug: "general"})
+	subs := newFakeSubscriptionRepo()
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Categories: cats, Users: newFakeUserRepo(), Subscriptions: subs})
+	replySvc := NewReplyService(ReplyServiceDeps{Repo: newFakeReplyRepo(), Threads: threads, Categories: cats, Users: newFakeUserRepo(), Subscriptions: subs})
+	svc := NewSubscriptionService(subs, threads, newFakeUserRepo())
+
+	threadID, err := threadSvc.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: catID, Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("create thread: %v", err)
+	}
+	if s := subs.subs[[2]int{1, threadID}]; s == nil || s.Level != entities.WatchAll {
+		t.Fatalf("expected the author to watch all activity, got %+v", s)
+	}
+
+	if err := svc.Watch(ctx, 2, threadID, entities.WatchMuted); err != nil {
+		t.Fatalf("mute: %v", err)
+	}
+	for _, uid := range []int{2, 3} {
+		if _, err := replySvc.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: uid, Body: "hi"}); err != nil {
+			t.Fatalf("reply: %v", err)
+		}
+	}
+	if s := subs.subs[[2]int{2, threadID}]; s.Level != entities.WatchMuted {
+		t.Fatalf("replying must not undo a mute, got %q", s.Level)
+	}
+	if s := subs.subs[[2]int{3, threadID}]; s == nil || s.Level != entities.WatchRepliesToMe {
+		t.Fatalf("expected the replier to follow replies to them, got %+v", s)
+	}
+
+	watchers, err := svc.Watchers(ctx, threadID)
+	if err != nil || len(watchers) != 2 {
+		t.Fatalf("expected 2 unmuted watchers, got %d (%v)", len(watchers), err)
+	}
+	if err := svc.Watch(ctx, 1, threadID, "loud"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for an unknown level, got %v", err)
+	}
+	if err := svc.Unwatch(ctx, 1, threadID); err != nil {
+		t.Fatalf("unwatch: %v", err)
+	}
+	if err := svc.Unwatch(ctx, 1, threadID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound unwatching twice, got %v", err)
+	}
+}
+
+func TestSubscriptions_WatchDraftFollowsReadRule(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleUser}
+	users.users[3] = &entities.User{ID: 3, Role: entities.RoleAdmin}
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b", Status: entities.ThreadStatusDraft})
+	svc := NewSubscriptionService(newFakeSubscriptionRepo(), threads, users)
+
+	if err := svc.Watch(ctx, 1, threadID, entities.WatchAll); err != nil {
+		t.Fatalf("author watching their draft: %v", err)
+	}
+	if err := svc.Watch(ctx, 3, threadID, entities.WatchAll); err != nil {
+		t.Fatalf("admin watching a draft: %v", err)
+	}
+	if err := svc.Watch(ctx, 2, threadID, entities.WatchAll); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for another user's draft, got %v", err)
+	}
+}
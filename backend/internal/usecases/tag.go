@@ -0,0 +1,210 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+const (
+	DefaultTagListSize = 100
+	MaxTagListSize     = 500
+)
+
+// TagService is the application port for browsing and administering tags.
+// Names passed in are normalized first, so "Go " and "go" address the same tag.
+type TagService interface {
+	ListTags(ctx context.Context, limit int) ([]entities.Tag, error)
+	// GetTag resolves a name or alias to its canonical tag
+	GetTag(ctx context.Context, name string) (*entities.Tag, error)
+	RenameTag(ctx context.Context, name, newName string) (*entities.Tag, error)
+	// MergeTags folds the tag `from` into `into`; from's name keeps working as an alias
+	MergeTags(ctx context.Context, from, into string) (*entities.Tag, error)
+	AddAlias(ctx context.Context, name, alias string) (*entities.Tag, error)
+	RemoveAlias(ctx context.Context, alias string) error
+	DeleteTag(ctx context.Context, name string) error
+}
+
+type tagService struct {
+	repo    repositories.TagRepository
+	related RelatedCache // optional
+}
+
+func NewTagService(repo repositories.TagRepository, related RelatedCache) TagService {
+	return &tagService{repo: repo, related: related}
+}
+
+// tagsChanged drops cached related lists after a rename, merge or delete changed the tags
+// threads carry.
+func (s *tagService) tagsChanged(ctx context.Context) {
+	if s.related != nil {
+		s.related.InvalidateAll(ctx)
+	}
+}
+
+// normalizeTags normalizes, de-duplicates and caps a thread's tag list.
+func normalizeTags(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	var tags []string
+	for _, r := range raw {
+		name, err := entities.NormalizeTag(r)
+		if err != nil {
+			return nil, invalidf("%s", err.Error())
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	if len(tags) > entities.MaxTagsPerThread {
+		return nil, invalidf("at most %d tags are allowed", entities.MaxTagsPerThread)
+	}
+	return tags, nil
+}
+
+func normalizeTagName(name string) (string, error) {
+	n, err := entities.NormalizeTag(name)
+	if err != nil {
+		return "", invalidf("%s", err.Error())
+	}
+	return n, nil
+}
+
+func (s *tagService) ListTags(ctx context.Context, limit int) ([]entities.Tag, error) {
+	if limit <= 0 {
+		limit = DefaultTagListSize
+	}
+	if limit > MaxTagListSize {
+		limit = MaxTagListSize
+	}
+	tags, err := s.repo.ListTags(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []entities.Tag{}
+	}
+	return tags, nil
+}
+
+func (s *tagService) GetTag(ctx context.Context, name string) (*entities.Tag, error) {
+	n, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.repo.GetTagByName(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, notFoundf("tag %q not found", n)
+	}
+	return tag, nil
+}
+
+// getCanonical is GetTag for admin mutations: it refuses aliases so that e.g. deleting
+// "golang" cannot silently delete the tag "go" it points to.
+func (s *tagService) getCanonical(ctx context.Context, name string) (*entities.Tag, error) {
+	tag, err := s.GetTag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := entities.NormalizeTag(name); n != tag.Name {
+		return nil, invalidf("%q is an alias of %q", n, tag.Name)
+	}
+	return tag, nil
+}
+
+// lookupFree fails with ErrConflict when name is already taken by a tag or an alias.
+func (s *tagService) lookupFree(ctx context.Context, name string) error {
+	existing, err := s.repo.GetTagByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return conflictf("%q is already used by tag %q; merge the tags instead", name, existing.Name)
+	}
+	return nil
+}
+
+func (s *tagService) RenameTag(ctx context.Context, name, newName string) (*entities.Tag, error) {
+	tag, err := s.getCanonical(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	n, err := normalizeTagName(newName)
+	if err != nil {
+		return nil, err
+	}
+	if n == tag.Name {
+		return tag, nil
+	}
+	if err := s.lookupFree(ctx, n); err != nil {
+		return nil, err
+	}
+	if err := s.repo.RenameTag(ctx, tag.ID, n); err != nil {
+		return nil, fmt.Errorf("rename tag: %w", err)
+	}
+	s.tagsChanged(ctx)
+	return s.GetTag(ctx, n)
+}
+
+func (s *tagService) MergeTags(ctx context.Context, from, into string) (*entities.Tag, error) {
+	src, err := s.getCanonical(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := s.GetTag(ctx, into)
+	if err != nil {
+		return nil, err
+	}
+	if src.ID == dst.ID {
+		return nil, invalidf("cannot merge a tag into itself")
+	}
+	if err := s.repo.MergeTags(ctx, src.ID, dst.ID); err != nil {
+		return nil, fmt.Errorf("merge tags: %w", err)
+	}
+	s.tagsChanged(ctx)
+	return s.GetTag(ctx, dst.Name)
+}
+
+func (s *tagService) AddAlias(ctx context.Context, name, alias string) (*entities.Tag, error) {
+	tag, err := s.GetTag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	a, err := normalizeTagName(alias)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lookupFree(ctx, a); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddAlias(ctx, tag.ID, a); err != nil {
+		return nil, fmt.Errorf("add alias: %w", err)
+	}
+	return s.GetTag(ctx, tag.Name)
+}
+
+func (s *tagService) RemoveAlias(ctx context.Context, alias string) error {
+	a, err := normalizeTagName(alias)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteAlias(ctx, a)
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, name string) error {
+	tag, err := s.getCanonical(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteTag(ctx, tag.ID); err != nil {
+		return err
+	}
+	s.tagsChanged(ctx)
+	return nil
+}
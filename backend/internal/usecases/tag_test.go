@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeTagRepo struct {
+	names   map[int]string
+	aliases map[string]int // alias -> tag ID
+	threads map[int]int    // tag ID -> thread count
+}
+
+func newFakeTagRepo(names ...string) *fakeTagRepo {
+	f := &fakeTagRepo{names: map[int]string{}, aliases: map[string]int{}, threads: map[int]int{}}
+	for i, n := range names {
+		f.names[i+1] = n
+	}
+	return f
+}
+
+func (f *fakeTagRepo) tag(id int) *entities.Tag {
+	t := &entities.Tag{ID: id, Name: f.names[id], ThreadCount: f.threads[id]}
+	for a, tid := range f.aliases {
+		if tid == id {
+			t.Aliases = append(t.Aliases, a)
+		}
+	}
+	sort.Strings(t.Aliases)
+	return t
+}
+func (f *fakeTagRepo) ListTags(ctx context.Context, limit int) ([]entities.Tag, error) {
+	var out []entities.Tag
+	for id := range f.names {
+		out = append(out, *f.tag(id))
+	}
+	return out, nil
+}
+func (f *fakeTagRepo) GetTagByName(ctx context.Context, name string) (*entities.Tag, error) {
+	for id, n := range f.names {
+		if n == name {
+			return f.tag(id), nil
+		}
+	}
+	if id, ok := f.aliases[name]; ok {
+		return f.tag(id), nil
+	}
+	return nil, nil
+}
+func (f *fakeTagRepo) RenameTag(ctx context.Context, id int, newName string) error {
+	f.names[id] = newName
+	return nil
+}
+func (f *fakeTagRepo) MergeTags(ctx context.Context, fromID, intoID int) error {
+	for a, tid := range f.aliases {
+		if tid == fromID {
+			f.aliases[a] = intoID
+		}
+	}
+	f.aliases[f.names[fromID]] = intoID
+	f.threads[intoID] += f.threads[fromID]
+	delete(f.names, fromID)
+	delete(f.threads, fromID)
+	return nil
+}
+func (f *fakeTagRepo) AddAlias(ctx context.Context, tagID int, alias string) error {
+	f.aliases[alias] = tagID
+	return nil
+}
+func (f *fakeTagRepo) DeleteAlias(ctx context.Context, alias string) error {
+	delete(f.aliases, alias)
+	return nil
+}
+func (f *fakeTagRepo) DeleteTag(ctx context.Context, id int) error {
+	delete(f.names, id)
+	return nil
+}
+
+// --- tests ---
+func TestNormalizeTags(t *testing.T) {
+	cases := []struct {
+		in   []string
+		want []string
+	}{
+		{[]string{"Go", "go ", " GO"}, []string{"go"}},
+		{[]string{"Machine  Learning", "machine_learning"}, []string{"machine-learning"}},
+		{[]string{"C++", "C#", ".NET"}, []string{"c++", "c#", ".net"}},
+		{[]string{"--rust--"}, []string{"rust"}},
+		{nil, nil},
+	}
+	for _, c := range cases {
+		got, err := normalizeTags(c.in)
+		if err != nil {
+			t.Fatalf("normalizeTags(%q) failed: %v", c.in, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("normalizeTags(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeTags_Rejects(t *testing.T) {
+	bad := [][]string{
+		{"!!!"},
+		{"this-tag-name-is-way-too-long-to-be-accepted"},
+		{"a", "b", "c", "d", "e", "f", "g", "h", "i"},
+	}
+	for _, in := range bad {
+		if _, err := normalizeTags(in); !errors.Is(err, ErrInvalidInput) {
+			t.Fatalf("normalizeTags(%q): expected ErrInvalidInput, got %v", in, err)
+		}
+	}
+}
+
+func TestTagService_RenameAndAlias(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeTagRepo("go", "rust")
+	svc := NewTagService(repo, nil)
+
+	if _, err := svc.RenameTag(ctx, "go", " Rust "); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict renaming onto an existing tag, got %v", err)
+	}
+	tag, err := svc.RenameTag(ctx, "GO", "Go Lang")
+	if err != nil || tag.ID != 1 || tag.Name != "go-lang" {
+		t.Fatalf("expected tag 1 renamed to go-lang, got %+v (%v)", tag, err)
+	}
+
+	tag, err = svc.AddAlias(ctx, "go-lang", "Golang")
+	if err != nil || !reflect.DeepEqual(tag.Aliases, []string{"golang"}) {
+		t.Fatalf("expected the normalized alias, got %+v (%v)", tag, err)
+	}
+	if _, err := svc.AddAlias(ctx, "rust", "golang"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict reusing an alias, got %v", err)
+	}
+	if got, err := svc.GetTag(ctx, "GoLang"); err != nil || got.ID != 1 {
+		t.Fatalf("expected the alias to resolve to tag 1, got %+v (%v)", got, err)
+	}
+	// admin mutations refuse aliases so they cannot hit the canonical tag by accident
+	if _, err := svc.RenameTag(ctx, "golang", "go"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput renaming through an alias, got %v", err)
+	}
+	if err := svc.DeleteTag(ctx, "golang"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput deleting through an alias, got %v", err)
+	}
+	if err := svc.RemoveAlias(ctx, "golang"); err != nil {
+		t.Fatalf("remove alias: %v", err)
+	}
+	if _, err := svc.GetTag(ctx, "golang"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after removing the alias, got %v", err)
+	}
+}
+
+func TestTagService_Merge(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeTagRepo("go", "golang", "rust")
+	repo.threads[1], repo.threads[2] = 3, 2
+	repo.aliases["go-lang"] = 2
+	related := &fakeRelatedCache{lists: map[int][]int{7: {8}}}
+	svc := NewTagService(repo, related)
+
+	if _, err := svc.MergeTags(ctx, "go", "Go"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput merging a tag into itself, got %v", err)
+	}
+	if _, err := svc.MergeTags(ctx, "go-lang", "go"); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput merging from an alias, got %v", err)
+	}
+	if _, err := svc.MergeTags(ctx, "golang", "python"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound merging into a missing tag, got %v", err)
+	}
+	tag, err := svc.MergeTags(ctx, "GoLang", "go")
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if tag.ID != 1 || tag.ThreadCount != 5 || !reflect.DeepEqual(tag.Aliases, []string{"go-lang", "golang"}) {
+		t.Fatalf("expected go to take golang's threads and names, got %+v", tag)
+	}
+	if len(related.lists) != 0 {
+		t.Fatalf("expected the merge to drop cached related lists")
+	}
+	if got, err := svc.GetTag(ctx, "golang"); err != nil || got.ID != 1 {
+		t.Fatalf("expected the merged name to resolve to go, got %+v (%v)", got, err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/nocson47/beaconofknowledge/internal/entities"
 	"github.com/nocson47/beaconofknowledge/internal/repositories"
@@ -14,37 +16,271 @@ import (
 // ThreadService is the application-level port consumed by adapters (handlers).
 type ThreadService interface {
 	CreateThread(ctx context.Context, t *entities.Thread) (int, error)
+	// GetThreadByID returns viewer-independent data so handlers can cache it; pass the
+	// result through ApplyViewerState before responding. Unpublished threads are only
+	// returned to their author and admins.
 	GetThreadByID(ctx context.Context, id int) (*entities.Thread, error)
-	GetAllThreads(ctx context.Context) ([]*entities.Thread, error)
-	UpdateThread(ctx context.Context, t *entities.Thread) error
+	// ApplyViewerState adds reaction counts and the requesting user's own state (MyVote,
+	// Bookmarked, Reacted) to threads
+	ApplyViewerState(ctx context.Context, threads ...*entities.Thread)
+	// ListThreads returns one page of threads; cursor is the opaque next_cursor of the previous page
+	ListThreads(ctx context.Context, opts entities.ThreadListOptions, cursor string) (*entities.ThreadPage, error)
+	// UpdateThread saves the edit; the previous content is kept as a revision attributed to edit
+	UpdateThread(ctx context.Context, t *entities.Thread, edit entities.EditInfo) error
 	DeleteThread(ctx context.Context, id int) error
+	// LockThread stops new replies and votes on a thread; reason is optional
+	LockThread(ctx context.Context, id int, actorID int, reason string) error
+	UnlockThread(ctx context.Context, id int, actorID int) error
+	// PinThread pins a thread globally or within one tag's listing, replacing any existing pin
+	PinThread(ctx context.Context, id int, actorID int, pin entities.ThreadPin) error
+	UnpinThread(ctx context.Context, id int) error
+	// AcceptAnswer marks replyID as the thread's accepted answer, replacing any previous one.
+	// Only the thread author or an admin may accept.
+	AcceptAnswer(ctx context.Context, threadID, replyID, actorID int) error
+	// UnacceptAnswer clears the accepted answer, marking the thread unsolved again
+	UnacceptAnswer(ctx context.Context, threadID, actorID int) error
+	// SetPublishing moves an unpublished thread to draft, scheduled (publishAt required) or
+	// published, which makes it go live now
+	SetPublishing(ctx context.Context, id int, status entities.ThreadStatus, publishAt *time.Time) error
+	// PublishDueThreads publishes scheduled threads whose time has come and returns their IDs
+	PublishDueThreads(ctx context.Context) ([]int, error)
+	// ClearExpiredPins drops pins past their expiry and returns the affected thread IDs
+	ClearExpiredPins(ctx context.Context) ([]int, error)
+	// MergeThread moves the replies and votes of sourceID into targetID and leaves sourceID as
+	// a locked redirect stub
+	MergeThread(ctx context.Context, sourceID, targetID, actorID int) error
+	// SplitThread moves a reply and everything below it into a new thread and returns its ID
+	SplitThread(ctx context.Context, split entities.ThreadSplit) (int, error)
+	// GetRelatedThreads returns up to limit threads similar to thread id by tags and title
+	GetRelatedThreads(ctx context.Context, id int, limit int) ([]*entities.Thread, error)
+	// ListTrending returns one page of threads ranked by trending score; cursor is the opaque
+	// next_cursor of the previous page
+	ListTrending(ctx context.Context, limit int, cursor string) (*entities.ThreadPage, error)
+	// RefreshTrending recomputes the trending ranking and returns how many threads it holds
+	RefreshTrending(ctx context.Context) (int, error)
 }
 
+const (
+	DefaultThreadPageSize = 20
+	MaxThreadPageSize     = 100
+)
+
 type threadService struct {
-	repo repositories.ThreadRepository
+	repo        repositories.ThreadRepository
+	categories  repositories.CategoryRepository
+	users       repositories.UserRepository
+	votes       repositories.VoteRepository
+	subs        repositories.SubscriptionRepository
+	mentions    repositories.MentionRepository
+	bookmarks   repositories.BookmarkRepository
+	reactions   repositories.ReactionRepository
+	attachments repositories.AttachmentRepository // optional
+	notifier    Notifier                          // optional
+	trending    TrendingStore                     // optional; ListTrending falls back to Postgres without it
+	related     RelatedCache                      // optional
+	content     *ContentPipeline
+}
+
+// ThreadServiceDeps holds what NewThreadService wires together. Repo is always required and
+// Categories is needed to create threads; the others may be nil, which skips what they back.
+type ThreadServiceDeps struct {
+	Repo          repositories.ThreadRepository
+	Categories    repositories.CategoryRepository
+	Users         repositories.UserRepository
+	Votes         repositories.VoteRepository
+	Subscriptions repositories.SubscriptionRepository
+	Mentions      repositories.MentionRepository
+	Bookmarks     repositories.BookmarkRepository
+	Reactions     repositories.ReactionRepository
+	Attachments   repositories.AttachmentRepository
+	Notifier      Notifier
+	// Trending serves the stored ranking; without it ListTrending ranks in Postgres
+	Trending TrendingStore
+	Related  RelatedCache
+	Content  *ContentPipeline
+}
+
+func NewThreadService(deps ThreadServiceDeps) ThreadService {
+	return &threadService{
+		repo:        deps.Repo,
+		categories:  deps.Categories,
+		users:       deps.Users,
+		votes:       deps.Votes,
+		subs:        deps.Subscriptions,
+		mentions:    deps.Mentions,
+		bookmarks:   deps.Bookmarks,
+		reactions:   deps.Reactions,
+		attachments: deps.Attachments,
+		notifier:    deps.Notifier,
+		trending:    deps.Trending,
+		related:     deps.Related,
+		content:     deps.Content,
+	}
+}
+
+// renderThreads fills BodyHTML, Mentions and Attachments for threads loaded with their full body.
+func (s *threadService) renderThreads(ctx context.Context, threads []*entities.Thread) {
+	for _, t := range threads {
+		t.BodyHTML = s.content.Render(ctx, t.Body)
+	}
+	fillThreadMentions(ctx, s.mentions, threads)
+	fillThreadAttachments(ctx, s.attachments, threads)
+}
+
+// fillPreviews fills Mentions and Attachments for list pages. Their Body is cut to a preview,
+// which would render as broken Markdown, so BodyHTML is left empty, and mentions whose
+// offsets run past the preview are dropped.
+func (s *threadService) fillPreviews(ctx context.Context, threads []*entities.Thread) {
+	fillThreadMentions(ctx, s.mentions, threads)
+	for _, t := range threads {
+		t.Mentions = mentionsWithin(t.Mentions, len(t.Body))
+	}
+	fillThreadAttachments(ctx, s.attachments, threads)
+}
+
+func (s *threadService) ListThreads(ctx context.Context, opts entities.ThreadListOptions, cursor string) (*entities.ThreadPage, error) {
+	if opts.Sort == "" {
+		opts.Sort = entities.ThreadSortNew
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultThreadPageSize
+	}
+	if opts.Limit > MaxThreadPageSize {
+		opts.Limit = MaxThreadPageSize
+	}
+	if opts.Tag != "" {
+		tag, err := normalizeTagName(opts.Tag)
+		if err != nil {
+			return nil, err
+		}
+		opts.Tag = tag
+	}
+	if opts.Category != "" {
+		cat, err := s.categories.GetCategoryBySlug(ctx, strings.ToLower(strings.TrimSpace(opts.Category)))
+		if err != nil {
+			return nil, fmt.Errorf("get category: %w", err)
+		}
+		if cat == nil {
+			return nil, notFoundf("category %q not found", opts.Category)
+		}
+		opts.CategoryID = cat.ID
+	}
+	opts.After = nil
+	if cursor != "" {
+		var c entities.ThreadCursor
+		if err := decodeCursor(cursor, &c); err != nil {
+			return nil, err
+		}
+		if c.Sort != opts.Sort || c.ID == 0 {
+			return nil, ErrInvalidCursor
+		}
+		opts.After = &c
+	}
+
+	// fetch one extra row to learn whether another page exists
+	limit := opts.Limit
+	opts.Limit = limit + 1
+	threads, err := s.repo.ListThreads(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list threads: %w", err)
+	}
+	page := &entities.ThreadPage{Threads: threads}
+	if len(threads) > limit {
+		page.Threads = threads[:limit]
+		next, err := encodeCursor(threadCursorFor(opts.Sort, page.Threads[limit-1]))
+		if err != nil {
+			return nil, fmt.Errorf("encode cursor: %w", err)
+		}
+		page.NextCursor = next
+	}
+	// pinned threads are excluded from the keyset query and lead the first page instead
+	if opts.After == nil {
+		pinned, err := s.repo.ListPinnedThreads(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list pinned threads: %w", err)
+		}
+		if len(pinned) > 0 {
+			page.Threads = append(pinned, page.Threads...)
+		}
+	}
+	if page.Threads == nil {
+		page.Threads = []*entities.Thread{}
+	}
+	s.fillPreviews(ctx, page.Threads)
+	s.ApplyViewerState(ctx, page.Threads...)
+	return page, nil
 }
 
-func NewThreadService(repo repositories.ThreadRepository) ThreadService {
-	return &threadService{repo: repo}
+// threadCursorFor captures the sort key of t so the next page can resume after it.
+func threadCursorFor(sort entities.ThreadSort, t *entities.Thread) entities.ThreadCursor {
+	c := entities.ThreadCursor{Sort: sort, ID: t.ID}
+	switch sort {
+	case entities.ThreadSortTop:
+		c.Score = t.Upvotes - t.Downvotes
+	case entities.ThreadSortDiscussed:
+		c.Score = t.ReplyCount
+	case entities.ThreadSortViews:
+		c.Score = t.Views
+	case entities.ThreadSortLastActivity:
+		at := t.LastActivityAt
+		c.At = &at
+	default:
+		at := t.CreatedAt
+		c.At = &at
+	}
+	return c
 }
 
-func (s *threadService) GetAllThreads(ctx context.Context) ([]*entities.Thread, error) {
-	return s.repo.GetAllThreads(ctx)
+func (s *threadService) ApplyViewerState(ctx context.Context, threads ...*entities.Thread) {
+	fillThreadVotes(ctx, s.votes, threads)
+	fillThreadBookmarks(ctx, s.bookmarks, threads)
+	fillThreadReactions(ctx, s.reactions, threads)
 }
 
 func (s *threadService) CreateThread(ctx context.Context, t *entities.Thread) (int, error) {
 	if t == nil {
-		return 0, errors.New("thread is nil")
+		return 0, invalidf("thread is nil")
 	}
 	t.Title = strings.TrimSpace(t.Title)
 	t.Body = strings.TrimSpace(t.Body)
 	if t.Title == "" || t.Body == "" {
-		return 0, errors.New("title and body are required")
+		return 0, invalidf("title and body are required")
+	}
+	if t.CategoryID == 0 {
+		return 0, invalidf("category_id is required")
+	}
+	if err := applyPublishing(t, t.Status, t.PublishAt, time.Now()); err != nil {
+		return 0, err
+	}
+	cat, err := s.categories.GetCategoryByID(ctx, t.CategoryID)
+	if err != nil {
+		return 0, fmt.Errorf("get category: %w", err)
+	}
+	if cat == nil {
+		return 0, invalidf("category %d does not exist", t.CategoryID)
+	}
+	if err := checkCanPost(ctx, s.users, cat, t.UserID); err != nil {
+		return 0, err
+	}
+	// the category's default tags go first so they survive the per-thread cap
+	tags, err := normalizeTags(append(append([]string{}, cat.Settings.DefaultTags...), t.Tags...))
+	if err != nil {
+		return 0, err
+	}
+	t.Tags = tags
+	if t.AttachmentIDs, err = checkAttachments(ctx, s.attachments, t.UserID, t.AttachmentIDs); err != nil {
+		return 0, err
 	}
 	id, err := s.repo.CreateThread(ctx, t)
+	if errors.Is(err, repositories.ErrAttachmentsUnavailable) {
+		return 0, errAttachmentsTaken
+	}
 	if err != nil {
 		return 0, fmt.Errorf("create thread: %w", err)
 	}
+	autoWatch(ctx, s.subs, t.UserID, id, entities.WatchAll)
+	// mentions in an unpublished thread are announced when it goes live
+	recordMentions(ctx, s.users, s.mentions, s.notifier, t.UserID, id, nil, t.Body, !t.Unpublished())
 	return id, nil
 }
 
@@ -53,22 +289,45 @@ func (s *threadService) GetThreadByID(ctx context.Context, id int) (*entities.Th
 	if err != nil {
 		return nil, fmt.Errorf("get thread by id %d: %w", id, err)
 	}
-	if thread == nil {
-		return nil, errors.New("thread not found")
+	if thread == nil || (thread.Unpublished() && !s.canSeeUnpublished(ctx, thread)) {
+		return nil, notFoundf("thread not found")
 	}
+	s.renderThreads(ctx, []*entities.Thread{thread})
 	return thread, nil
 }
 
-func (s *threadService) UpdateThread(ctx context.Context, t *entities.Thread) error {
+func (s *threadService) UpdateThread(ctx context.Context, t *entities.Thread, edit entities.EditInfo) error {
 	if t == nil {
-		return errors.New("thread is nil")
+		return invalidf("thread is nil")
 	}
 	if strings.TrimSpace(t.Title) == "" || strings.TrimSpace(t.Body) == "" {
-		return errors.New("title and body are required")
+		return invalidf("title and body are required")
+	}
+	tags, err := normalizeTags(t.Tags)
+	if err != nil {
+		return err
+	}
+	t.Tags = tags
+	edit.Reason = strings.TrimSpace(edit.Reason)
+	prev, err := s.repo.GetThreadByID(ctx, t.ID)
+	if err != nil {
+		return fmt.Errorf("get thread: %w", err)
+	}
+	if prev == nil || prev.IsDeleted {
+		return notFoundf("thread not found")
 	}
-	if err := s.repo.UpdateThread(ctx, t); err != nil {
+	// edits on a locked thread are reserved for admins, as for replies
+	if prev.IsLocked && !isAdmin(ctx, s.users, edit.EditorID) {
+		return forbiddenf("thread is locked")
+	}
+	if err := s.repo.UpdateThread(ctx, t, edit); err != nil {
 		return fmt.Errorf("update thread: %w", err)
 	}
+	// related threads are ranked by tags and title, so a change to either drops the cached lists
+	if s.related != nil && (prev.Title != t.Title || !sameTags(prev.Tags, t.Tags)) {
+		s.related.InvalidateAll(ctx)
+	}
+	recordMentions(ctx, s.users, s.mentions, s.notifier, prev.UserID, t.ID, nil, t.Body, !prev.Unpublished())
 	return nil
 }
 
@@ -79,4 +338,266 @@ func (s *threadService) DeleteThread(ctx context.Context, id int) error {
 	return nil
 }
 
+func (s *threadService) LockThread(ctx context.Context, id int, actorID int, reason string) error {
+	t, err := s.repo.GetThreadByID(ctx, id)
+	if err != nil || t == nil || t.IsDeleted {
+		return notFoundf("thread not found")
+	}
+	if t.IsLocked {
+		return conflictf("thread is already locked")
+	}
+	if err := s.repo.SetThreadLock(ctx, id, true, actorID, strings.TrimSpace(reason)); err != nil {
+		return fmt.Errorf("lock thread: %w", err)
+	}
+	return nil
+}
+
+func (s *threadService) UnlockThread(ctx context.Context, id int, actorID int) error {
+	t, err := s.repo.GetThreadByID(ctx, id)
+	if err != nil || t == nil || t.IsDeleted {
+		return notFoundf("thread not found")
+	}
+	if !t.IsLocked {
+		return conflictf("thread is not locked")
+	}
+	if err := s.repo.SetThreadLock(ctx, id, false, actorID, ""); err != nil {
+		return fmt.Errorf("unlock thread: %w", err)
+	}
+	return nil
+}
+
+func (s *threadService) PinThread(ctx context.Context, id int, actorID int, pin entities.ThreadPin) error {
+	t, err := s.repo.GetThreadByID(ctx, id)
+	if err != nil || t == nil || t.IsDeleted {
+		return notFoundf("thread not found")
+	}
+	switch pin.Scope {
+	case "", entities.PinScopeGlobal:
+		pin.Scope, pin.Tag = entities.PinScopeGlobal, ""
+	case entities.PinScopeTag:
+		tag, err := normalizeTagName(pin.Tag)
+		if err != nil {
+			return err
+		}
+		tagged := false
+		for _, tt := range t.Tags {
+			if tt == tag {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return invalidf("thread is not tagged %q", tag)
+		}
+		pin.Tag = tag
+	default:
+		return invalidf("pin scope must be %q or %q", entities.PinScopeGlobal, entities.PinScopeTag)
+	}
+	if pin.ExpiresAt != nil {
+		if !pin.ExpiresAt.After(time.Now()) {
+			return invalidf("pin expiry must be in the future")
+		}
+		// threads timestamps are stored without a zone, in UTC
+		exp := pin.ExpiresAt.UTC()
+		pin.ExpiresAt = &exp
+	}
+	pin.PinnedBy = &actorID
+	if err := s.repo.SetThreadPin(ctx, id, &pin); err != nil {
+		return fmt.Errorf("pin thread: %w", err)
+	}
+	return nil
+}
+
+func (s *threadService) UnpinThread(ctx context.Context, id int) error {
+	t, err := s.repo.GetThreadByID(ctx, id)
+	if err != nil || t == nil {
+		return notFoundf("thread not found")
+	}
+	if t.Pin == nil {
+		return conflictf("thread is not pinned")
+	}
+	if err := s.repo.SetThreadPin(ctx, id, nil); err != nil {
+		return fmt.Errorf("unpin thread: %w", err)
+	}
+	return nil
+}
+
+func (s *threadService) ClearExpiredPins(ctx context.Context) ([]int, error) {
+	return s.repo.ClearExpiredPins(ctx)
+}
+
+func (s *threadService) AcceptAnswer(ctx context.Context, threadID, replyID, actorID int) error {
+	if _, err := s.authorizeAnswer(ctx, threadID, actorID); err != nil {
+		return err
+	}
+	ok, err := s.repo.SetAcceptedReply(ctx, threadID, &replyID)
+	if err != nil {
+		return fmt.Errorf("accept answer: %w", err)
+	}
+	if !ok {
+		return invalidf("reply %d is not a reply in this thread", replyID)
+	}
+	return nil
+}
+
+func (s *threadService) UnacceptAnswer(ctx context.Context, threadID, actorID int) error {
+	t, err := s.authorizeAnswer(ctx, threadID, actorID)
+	if err != nil {
+		return err
+	}
+	if t.AcceptedReplyID == nil {
+		return conflictf("thread has no accepted answer")
+	}
+	if _, err := s.repo.SetAcceptedReply(ctx, threadID, nil); err != nil {
+		return fmt.Errorf("unaccept answer: %w", err)
+	}
+	return nil
+}
+
+// authorizeAnswer loads a live thread and checks that actorID is its author or an admin.
+func (s *threadService) authorizeAnswer(ctx context.Context, threadID, actorID int) (*entities.Thread, error) {
+	t, err := s.repo.GetThreadByID(ctx, threadID)
+	if err != nil || t == nil || t.IsDeleted {
+		return nil, notFoundf("thread not found")
+	}
+	if err := requireAuthorOrAdmin(ctx, s.users, t.UserID, actorID, "choose the accepted answer"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// requireAuthorOrAdmin returns a forbidden error, naming action, unless actorID is authorID or an admin.
+func requireAuthorOrAdmin(ctx context.Context, users repositories.UserRepository, authorID, actorID int, action string) error {
+	if authorID == actorID {
+		return nil
+	}
+	u, err := users.GetUserByID(ctx, actorID)
+	if err != nil || u == nil || u.Role != entities.RoleAdmin {
+		return forbiddenf("only the thread author or an admin can %s", action)
+	}
+	return nil
+}
+
+// canSeeUnpublished reports whether the requesting user is t's author or an admin.
+func (s *threadService) canSeeUnpublished(ctx context.Context, t *entities.Thread) bool {
+	return canSeeUnpublished(ctx, s.users, t)
+}
+
+func canSeeUnpublished(ctx context.Context, users repositories.UserRepository, t *entities.Thread) bool {
+	uid := ViewerID(ctx)
+	if uid != 0 && uid == t.UserID {
+		return true
+	}
+	return isAdmin(ctx, users, uid)
+}
+
+// visibleThread loads a live thread the requesting user may read; drafts and scheduled
+// threads are reported missing to everyone but their author and admins.
+func visibleThread(ctx context.Context, threads repositories.ThreadRepository, users repositories.UserRepository, id int) (*entities.Thread, error) {
+	t, err := threads.GetThreadByID(ctx, id)
+	if err != nil || t == nil || t.IsDeleted || (t.Unpublished() && !canSeeUnpublished(ctx, users, t)) {
+		return nil, notFoundf("thread not found")
+	}
+	return t, nil
+}
+
+func isAdmin(ctx context.Context, users repositories.UserRepository, userID int) bool {
+	if userID == 0 || users == nil {
+		return false
+	}
+	u, err := users.GetUserByID(ctx, userID)
+	return err == nil && u != nil && u.Role == entities.RoleAdmin
+}
+
+// applyPublishing validates a requested status and publish time and normalizes them into t.
+// An empty status means scheduled when publishAt is set and published otherwise.
+func applyPublishing(t *entities.Thread, status entities.ThreadStatus, publishAt *time.Time, now time.Time) error {
+	switch {
+	case publishAt != nil && (status == entities.ThreadStatusScheduled || status == ""):
+		if !publishAt.After(now) {
+			return invalidf("publish_at must be in the future")
+		}
+		// threads timestamps are stored without a zone, in UTC
+		at := publishAt.UTC()
+		t.Status, t.PublishAt = entities.ThreadStatusScheduled, &at
+	case publishAt != nil:
+		return invalidf("publish_at can only be set on scheduled threads")
+	case status == entities.ThreadStatusScheduled:
+		return invalidf("publish_at is required to schedule a thread")
+	case status == "":
+		t.Status, t.PublishAt = entities.ThreadStatusPublished, nil
+	default:
+		t.Status, t.PublishAt = status, nil
+	}
+	return nil
+}
+
+func (s *threadService) SetPublishing(ctx context.Context, id int, status entities.ThreadStatus, publishAt *time.Time) error {
+	t, err := s.repo.GetThreadByID(ctx, id)
+	if err != nil || t == nil || t.IsDeleted {
+		return notFoundf("thread not found")
+	}
+	if !t.Unpublished() {
+		return conflictf("thread is already published")
+	}
+	var next entities.Thread
+	if err := applyPublishing(&next, status, publishAt, time.Now()); err != nil {
+		return err
+	}
+	ok, err := s.repo.SetThreadPublishing(ctx, id, next.Status, next.PublishAt)
+	if err != nil {
+		return fmt.Errorf("set publishing: %w", err)
+	}
+	if !ok {
+		// the publisher got there first
+		return conflictf("thread is already published")
+	}
+	if next.Status == entities.ThreadStatusPublished {
+		s.announceMentions(ctx, t)
+	}
+	return nil
+}
+
+func (s *threadService) PublishDueThreads(ctx context.Context) ([]int, error) {
+	ids, err := s.repo.PublishDueThreads(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if t, err := s.repo.GetThreadByID(ctx, id); err == nil && t != nil {
+			s.announceMentions(ctx, t)
+		}
+	}
+	return ids, nil
+}
+
+// announceMentions notifies everyone mentioned in a thread that was just published.
+func (s *threadService) announceMentions(ctx context.Context, t *entities.Thread) {
+	if s.mentions == nil || s.notifier == nil {
+		return
+	}
+	byThread, err := s.mentions.GetThreadMentions(ctx, []int{t.ID})
+	if err != nil {
+		log.Printf("announce mentions in thread %d: %v", t.ID, err)
+		return
+	}
+	notifyMentioned(ctx, s.mentions, s.notifier, t.UserID, t.ID, nil, byThread[t.ID])
+}
+
+// ensureThreadOpen loads a thread and fails unless it exists, is not soft-deleted and is not
+// locked. Reply and vote usecases call it before accepting new activity.
+func ensureThreadOpen(ctx context.Context, threads repositories.ThreadRepository, id int) (*entities.Thread, error) {
+	t, err := threads.GetThreadByID(ctx, id)
+	if err != nil || t == nil || t.IsDeleted {
+		return nil, notFoundf("thread not found")
+	}
+	if t.IsLocked {
+		return nil, forbiddenf("thread is locked")
+	}
+	if t.Unpublished() {
+		return nil, forbiddenf("thread is not published yet")
+	}
+	return t, nil
+}
+
 // ...existing code...
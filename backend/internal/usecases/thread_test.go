@@ -0,0 +1,557 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeThreadRepo struct {
+	threads      map[int]*entities.Thread
+	relatedCalls int
+	// replies, when set, lets merges and splits move replies
+	replies *fakeReplyRepo
+	modLog  []entities.ModAction
+	// attachments, when set, links AttachmentIDs on create
+	attachments *fakeAttachmentRepo
+}
+
+func newFakeThreadRepo() *fakeThreadRepo {
+	return &fakeThreadRepo{threads: map[int]*entities.Thread{}}
+}
+
+func (f *fakeThreadRepo) CreateThread(ctx context.Context, t *entities.Thread) (int, error) {
+	id := len(f.threads) + 1
+	if f.attachments != nil {
+		if err := f.attachments.link(t.UserID, t.AttachmentIDs, &id, nil); err != nil {
+			return 0, err
+		}
+	}
+	t.ID = id
+	f.threads[t.ID] = t
+	return t.ID, nil
+}
+func (f *fakeThreadRepo) GetThreadByID(ctx context.Context, id int) (*entities.Thread, error) {
+	return f.threads[id], nil
+}
+
+// ListThreads mimics the Postgres keyset query for the "new" sort.
+func (f *fakeThreadRepo) ListThreads(ctx context.Context, opts entities.ThreadListOptions) ([]*entities.Thread, error) {
+	var all []*entities.Thread
+	for _, t := range f.threads {
+		all = append(all, t)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].CreatedAt.After(all[j].CreatedAt)
+		}
+		return all[i].ID > all[j].ID
+	})
+	var out []*entities.Thread
+	for _, t := range all {
+		if t.Unpublished() || (t.Pin != nil && t.Pin.Scope == entities.PinScopeGlobal) || !matchesListFilters(t, opts) {
+			continue
+		}
+		if c := opts.After; c != nil {
+			if t.CreatedAt.After(*c.At) || (t.CreatedAt.Equal(*c.At) && t.ID >= c.ID) {
+				continue
+			}
+		}
+		out = append(out, t)
+		if len(out) == opts.Limit {
+			break
+		}
+	}
+	return out, nil
+}
+func (f *fakeThreadRepo) UpdateThread(ctx context.Context, t *entities.Thread, edit entities.EditInfo) error {
+	// store a copy so threads read before the edit keep their old content, as with Postgres
+	cur := *f.threads[t.ID]
+	cur.Title, cur.Body, cur.Tags = t.Title, t.Body, t.Tags
+	f.threads[t.ID] = &cur
+	return nil
+}
+func (f *fakeThreadRepo) DeleteThread(ctx context.Context, id int) error {
+	delete(f.threads, id)
+	return nil
+}
+func (f *fakeThreadRepo) SetThreadLock(ctx context.Context, id int, locked bool, actorID int, reason string) error {
+	t := f.threads[id]
+	t.IsLocked, t.LockReason = locked, reason
+	t.LockedBy = nil
+	if locked {
+		t.LockedBy = &actorID
+	}
+	return nil
+}
+func (f *fakeThreadRepo) ListPinnedThreads(ctx context.Context, opts entities.ThreadListOptions) ([]*entities.Thread, error) {
+	var out []*entities.Thread
+	for _, t := range f.threads {
+		if t.Pin != nil && (t.Pin.Scope == entities.PinScopeGlobal || (opts.Tag != "" && t.Pin.Tag == opts.Tag)) && matchesListFilters(t, opts) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// matchesListFilters applies the category, author and solved filters of opts; categories
+// match exactly rather than by subtree.
+func matchesListFilters(t *entities.Thread, opts entities.ThreadListOptions) bool {
+	if opts.CategoryID != 0 && t.CategoryID != opts.CategoryID {
+		return false
+	}
+	if opts.AuthorID != 0 && t.UserID != opts.AuthorID {
+		return false
+	}
+	return opts.Solved == nil || *opts.Solved == (t.AcceptedReplyID != nil)
+}
+func (f *fakeThreadRepo) SetThreadPin(ctx context.Context, id int, pin *entities.ThreadPin) error {
+	f.threads[id].Pin = pin
+	return nil
+}
+func (f *fakeThreadRepo) ClearExpiredPins(ctx context.Context) ([]int, error) { return nil, nil }
+func (f *fakeThreadRepo) SetThreadPublishing(ctx context.Context, id int, status entities.ThreadStatus, publishAt *time.Time) (bool, error) {
+	t := f.threads[id]
+	if !t.Unpublished() {
+		return false, nil
+	}
+	t.Status, t.PublishAt = status, publishAt
+	return true, nil
+}
+func (f *fakeThreadRepo) PublishDueThreads(ctx context.Context) ([]int, error) {
+	var ids []int
+	for id, t := range f.threads {
+		if t.Status == entities.ThreadStatusScheduled && !t.PublishAt.After(time.Now()) {
+			t.Status = entities.ThreadStatusPublished
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+func (f *fakeThreadRepo) SetViewCounts(ctx context.Context, counts map[int]int) error {
+	for id, n := range counts {
+		if t, ok := f.threads[id]; ok && n > t.Views {
+			t.Views = n
+		}
+	}
+	return nil
+}
+func (f *fakeThreadRepo) GetThreadsByIDs(ctx context.Context, ids []int) ([]*entities.Thread, error) {
+	var out []*entities.Thread
+	for _, id := range ids {
+		if t, ok := f.threads[id]; ok && !t.IsDeleted && !t.Unpublished() {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// ListThreadActivity treats every reply as recent and every thread as active.
+func (f *fakeThreadRepo) ListThreadActivity(ctx context.Context, activeSince, repliesSince time.Time, limit int) ([]entities.ThreadActivity, error) {
+	var out []entities.ThreadActivity
+	for _, t := range f.threads {
+		if !t.IsDeleted && !t.Unpublished() && len(out) < limit {
+			out = append(out, entities.ThreadActivity{ThreadID: t.ID, Upvotes: t.Upvotes, Downvotes: t.Downvotes, RecentReplies: t.ReplyCount, CreatedAt: t.CreatedAt})
+		}
+	}
+	return out, nil
+}
+
+// RankTrendingThreads scores ListThreadActivity's threads with trendingScore.
+func (f *fakeThreadRepo) RankTrendingThreads(ctx context.Context, q entities.TrendingQuery) ([]int, error) {
+	activity, _ := f.ListThreadActivity(ctx, q.ActiveSince, q.RepliesSince, q.Candidates)
+	now := time.Now()
+	sort.Slice(activity, func(i, j int) bool {
+		si, sj := trendingScore(activity[i], now), trendingScore(activity[j], now)
+		if si != sj {
+			return si > sj
+		}
+		return activity[i].ThreadID > activity[j].ThreadID
+	})
+	var ids []int
+	for i := q.Offset; i < len(activity) && len(ids) < q.Limit; i++ {
+		ids = append(ids, activity[i].ThreadID)
+	}
+	return ids, nil
+}
+
+// ListRelatedThreads ranks by the number of shared tags only.
+func (f *fakeThreadRepo) ListRelatedThreads(ctx context.Context, threadID int, lockedSince time.Time, limit int) ([]int, error) {
+	f.relatedCalls++
+	shared := map[int]int{}
+	for _, tag := range f.threads[threadID].Tags {
+		for id, t := range f.threads {
+			if id == threadID || t.IsDeleted || t.Unpublished() || recentlyLocked(t, lockedSince) {
+				continue
+			}
+			for _, other := range t.Tags {
+				if other == tag {
+					shared[id]++
+				}
+			}
+		}
+	}
+	var ids []int
+	for id := range shared {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if shared[ids[i]] != shared[ids[j]] {
+			return shared[ids[i]] > shared[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+func (f *fakeThreadRepo) MergeThreads(ctx context.Context, sourceID, targetID, actorID int) (bool, error) {
+	src, dst := f.threads[sourceID], f.threads[targetID]
+	if src.MergedInto != nil || dst.MergedInto != nil {
+		return false, nil
+	}
+	for _, r := range f.replies.replies {
+		if r.ThreadID == sourceID {
+			r.ThreadID = targetID
+		}
+	}
+	dst.Upvotes += src.Upvotes
+	src.Upvotes, src.MergedInto, src.IsLocked = 0, &targetID, true
+	f.modLog = append(f.modLog, entities.ModActionMerge)
+	return true, nil
+}
+func (f *fakeThreadRepo) SplitThread(ctx context.Context, split entities.ThreadSplit) (int, bool, error) {
+	src := f.threads[split.ThreadID]
+	if src.MergedInto != nil {
+		return 0, false, nil
+	}
+	root, ok := f.replies.replies[split.ReplyID]
+	if !ok || root.ThreadID != split.ThreadID || root.IsDeleted {
+		return 0, true, nil
+	}
+	id, _ := f.CreateThread(ctx, &entities.Thread{UserID: root.UserID, CategoryID: src.CategoryID, Title: split.Title, Body: split.Body, Tags: src.Tags})
+	moved := map[int]bool{root.ID: true}
+	for grew := true; grew; {
+		grew = false
+		for _, r := range f.replies.replies {
+			if !moved[r.ID] && r.ParentID != nil && moved[*r.ParentID] {
+				moved[r.ID], grew = true, true
+			}
+		}
+	}
+	for rid := range moved {
+		f.replies.replies[rid].ThreadID = id
+	}
+	root.ParentID = nil
+	f.modLog = append(f.modLog, entities.ModActionSplit)
+	return id, true, nil
+}
+func (f *fakeThreadRepo) SetAcceptedReply(ctx context.Context, threadID int, replyID *int) (bool, error) {
+	t := f.threads[threadID]
+	t.AcceptedReplyID = replyID
+	t.Solved = replyID != nil
+	return true, nil
+}
+
+// fakeMarkdown wraps the body in a paragraph and passes it through unsanitized
+type fakeMarkdown struct{}
+
+func (fakeMarkdown) ToHTML(markdown string) (string, error) { return "<p>" + markdown + "</p>", nil }
+func (fakeMarkdown) Sanitize(html string) string            { return html }
+
+// --- tests ---
+func TestThreadService_ListThreadsPagesThroughAll(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		// two threads share a timestamp to exercise the id tie-breaker
+		repo.CreateThread(ctx, &entities.Thread{Title: "t", Body: "b", CreatedAt: base.Add(time.Duration(i/2) * time.Minute)})
+	}
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo})
+
+	seen := map[int]bool{}
+	cursor := ""
+	pages := 0
+	for {
+		page, err := svc.ListThreads(ctx, entities.ThreadListOptions{Limit: 2}, cursor)
+		if err != nil {
+			t.Fatalf("ListThreads failed: %v", err)
+		}
+		pages++
+		for _, th := range page.Threads {
+			if seen[th.ID] {
+				t.Fatalf("thread %d returned twice", th.ID)
+			}
+			seen[th.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != 5 || pages != 3 {
+		t.Fatalf("expected 5 threads over 3 pages, got %d threads over %d pages", len(seen), pages)
+	}
+}
+
+func TestThreadService_OnlyFullBodiesAreRendered(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	id, _ := repo.CreateThread(ctx, &entities.Thread{Title: "t", Body: "**b**"})
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo, Content: NewContentPipeline(fakeMarkdown{}, fakeMarkdown{}, nil)})
+
+	page, err := svc.ListThreads(ctx, entities.ThreadListOptions{}, "")
+	if err != nil || len(page.Threads) != 1 || page.Threads[0].BodyHTML != "" {
+		t.Fatalf("expected list previews without body_html, got %+v (%v)", page, err)
+	}
+	th, err := svc.GetThreadByID(ctx, id)
+	if err != nil || th.BodyHTML != "<p>**b**</p>" {
+		t.Fatalf("expected the full thread rendered, got %+v (%v)", th, err)
+	}
+}
+
+func TestThreadService_ListThreadsRejectsForeignCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	for i := 0; i < 3; i++ {
+		repo.CreateThread(ctx, &entities.Thread{Title: "t", Body: "b", CreatedAt: time.Now()})
+	}
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo})
+
+	page, err := svc.ListThreads(ctx, entities.ThreadListOptions{Limit: 1}, "")
+	if err != nil || page.NextCursor == "" {
+		t.Fatalf("expected a next cursor, got %v (err %v)", page, err)
+	}
+	if _, err := svc.ListThreads(ctx, entities.ThreadListOptions{Sort: entities.ThreadSortTop, Limit: 1}, page.NextCursor); err != ErrInvalidCursor {
+		t.Fatalf("expected ErrInvalidCursor for mismatched sort, got %v", err)
+	}
+	if _, err := svc.ListThreads(ctx, entities.ThreadListOptions{}, "not-a-cursor!"); err != ErrInvalidCursor {
+		t.Fatalf("expected ErrInvalidCursor for garbage cursor, got %v", err)
+	}
+}
+
+func TestThreadService_LockBlocksActivity(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	id, _ := repo.CreateThread(ctx, &entities.Thread{Title: "t", Body: "b"})
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo})
+
+	if _, err := ensureThreadOpen(ctx, repo, id); err != nil {
+		t.Fatalf("open thread rejected: %v", err)
+	}
+	if err := svc.LockThread(ctx, id, 7, " spam "); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+	if got := repo.threads[id]; got.LockReason != "spam" || got.LockedBy == nil || *got.LockedBy != 7 {
+		t.Fatalf("lock metadata not recorded: %+v", got)
+	}
+	if _, err := ensureThreadOpen(ctx, repo, id); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden on locked thread, got %v", err)
+	}
+	if err := svc.LockThread(ctx, id, 7, ""); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict on double lock, got %v", err)
+	}
+	if err := svc.UnlockThread(ctx, id, 7); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if _, err := ensureThreadOpen(ctx, repo, id); err != nil {
+		t.Fatalf("unlocked thread rejected: %v", err)
+	}
+	if _, err := ensureThreadOpen(ctx, repo, 99); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for missing thread, got %v", err)
+	}
+}
+
+func TestThreadService_PinnedThreadsLeadFirstPage(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		repo.CreateThread(ctx, &entities.Thread{Title: "t", Body: "b", Tags: []string{"go"}, CreatedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo})
+
+	// the oldest thread is pinned, so it would otherwise land on the last page
+	if err := svc.PinThread(ctx, 1, 9, entities.ThreadPin{}); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if pin := repo.threads[1].Pin; pin == nil || pin.Scope != entities.PinScopeGlobal || *pin.PinnedBy != 9 {
+		t.Fatalf("unexpected pin: %+v", pin)
+	}
+	page, err := svc.ListThreads(ctx, entities.ThreadListOptions{Limit: 2}, "")
+	if err != nil {
+		t.Fatalf("ListThreads failed: %v", err)
+	}
+	if len(page.Threads) != 3 || page.Threads[0].ID != 1 {
+		t.Fatalf("expected pinned thread 1 ahead of a full page, got %d threads starting at %d", len(page.Threads), page.Threads[0].ID)
+	}
+	next, err := svc.ListThreads(ctx, entities.ThreadListOptions{Limit: 2}, page.NextCursor)
+	if err != nil {
+		t.Fatalf("ListThreads failed: %v", err)
+	}
+	for _, th := range next.Threads {
+		if th.ID == 1 {
+			t.Fatalf("pinned thread repeated on a later page")
+		}
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if err := svc.PinThread(ctx, 2, 9, entities.ThreadPin{ExpiresAt: &past}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for past expiry, got %v", err)
+	}
+	if err := svc.PinThread(ctx, 2, 9, entities.ThreadPin{Scope: entities.PinScopeTag, Tag: "rust"}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a tag the thread lacks, got %v", err)
+	}
+	if err := svc.UnpinThread(ctx, 1); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if err := svc.UnpinThread(ctx, 1); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict when unpinning twice, got %v", err)
+	}
+}
+
+func TestThreadService_PinnedThreadsFollowFilters(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	cats := newFakeCategoryRepo()
+	a, _ := cats.CreateCategory(ctx, &entities.Category{Slug: "a"})
+	b, _ := cats.CreateCategory(ctx, &entities.Category{Slug: "b"})
+	answer := 5
+	pinnedA, _ := repo.CreateThread(ctx, &entities.Thread{UserID: 1, CategoryID: a, Title: "t", Body: "b"})
+	pinnedB, _ := repo.CreateThread(ctx, &entities.Thread{UserID: 2, CategoryID: b, Title: "t", Body: "b", AcceptedReplyID: &answer})
+	plainA, _ := repo.CreateThread(ctx, &entities.Thread{UserID: 2, CategoryID: a, Title: "t", Body: "b"})
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo, Categories: cats})
+	for _, id := range []int{pinnedA, pinnedB} {
+		if err := svc.PinThread(ctx, id, 9, entities.ThreadPin{}); err != nil {
+			t.Fatalf("pin: %v", err)
+		}
+	}
+
+	ids := func(opts entities.ThreadListOptions) []int {
+		page, err := svc.ListThreads(ctx, opts, "")
+		if err != nil {
+			t.Fatalf("ListThreads failed: %v", err)
+		}
+		var out []int
+		for _, th := range page.Threads {
+			out = append(out, th.ID)
+		}
+		return out
+	}
+	if got := ids(entities.ThreadListOptions{Category: "a"}); !reflect.DeepEqual(got, []int{pinnedA, plainA}) {
+		t.Fatalf("expected only category a's pin ahead of its threads, got %v", got)
+	}
+	solved := true
+	if got := ids(entities.ThreadListOptions{Solved: &solved}); !reflect.DeepEqual(got, []int{pinnedB}) {
+		t.Fatalf("expected only the solved pin, got %v", got)
+	}
+	if got := ids(entities.ThreadListOptions{Category: "b", Solved: new(bool)}); len(got) != 0 {
+		t.Fatalf("expected no unsolved threads in category b, got %v", got)
+	}
+	if got := ids(entities.ThreadListOptions{AuthorID: 2}); !reflect.DeepEqual(got, []int{pinnedB, plainA}) {
+		t.Fatalf("expected only user 2's threads, got %v", got)
+	}
+}
+
+func TestThreadService_AcceptAnswer(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	id, _ := repo.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "q", Body: "b"})
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleUser}
+	users.users[3] = &entities.User{ID: 3, Role: entities.RoleAdmin}
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo, Users: users})
+
+	if err := svc.AcceptAnswer(ctx, id, 7, 2); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for another user, got %v", err)
+	}
+	if err := svc.AcceptAnswer(ctx, id, 7, 1); err != nil {
+		t.Fatalf("author accept: %v", err)
+	}
+	// an admin may change the answer
+	if err := svc.AcceptAnswer(ctx, id, 8, 3); err != nil {
+		t.Fatalf("admin accept: %v", err)
+	}
+	if th := repo.threads[id]; !th.Solved || *th.AcceptedReplyID != 8 {
+		t.Fatalf("expected reply 8 accepted, got %+v", th.AcceptedReplyID)
+	}
+	if err := svc.UnacceptAnswer(ctx, id, 1); err != nil {
+		t.Fatalf("unaccept: %v", err)
+	}
+	if err := svc.UnacceptAnswer(ctx, id, 1); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict with no accepted answer, got %v", err)
+	}
+}
+
+func TestThreadService_UpdateLockedThread(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	id, _ := repo.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b"})
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[3] = &entities.User{ID: 3, Role: entities.RoleAdmin}
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo, Users: users})
+	if err := svc.LockThread(ctx, id, 3, "off topic"); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	if err := svc.UpdateThread(ctx, &entities.Thread{ID: id, Title: "t", Body: "edited"}, entities.EditInfo{EditorID: 1}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden for the author on a locked thread, got %v", err)
+	}
+	if err := svc.UpdateThread(ctx, &entities.Thread{ID: id, Title: "t", Body: "edited"}, entities.EditInfo{EditorID: 3}); err != nil {
+		t.Fatalf("admin edit: %v", err)
+	}
+	// an edit never touches the lock
+	if th := repo.threads[id]; !th.IsLocked || th.LockReason != "off topic" || th.Body != "edited" {
+		t.Fatalf("expected the edit saved and the lock kept, got %+v", th)
+	}
+}
+
+func TestThreadService_UnpublishedVisibility(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeThreadRepo()
+	at := time.Now().Add(time.Hour)
+	repo.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "live", Body: "b", Status: entities.ThreadStatusPublished})
+	id, _ := repo.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "soon", Body: "b", Status: entities.ThreadStatusScheduled, PublishAt: &at})
+	users := newFakeUserRepo()
+	users.users[1] = &entities.User{ID: 1, Role: entities.RoleUser}
+	users.users[2] = &entities.User{ID: 2, Role: entities.RoleUser}
+	users.users[3] = &entities.User{ID: 3, Role: entities.RoleAdmin}
+	svc := NewThreadService(ThreadServiceDeps{Repo: repo, Users: users})
+
+	for viewer, visible := range map[int]bool{0: false, 1: true, 2: false, 3: true} {
+		vctx := WithViewer(ctx, viewer)
+		_, err := svc.GetThreadByID(vctx, id)
+		if visible != (err == nil) {
+			t.Fatalf("viewer %d: GetThreadByID err = %v, want visible=%v", viewer, err, visible)
+		}
+		// listings only ever show published threads, whoever asks
+		page, err := svc.ListThreads(vctx, entities.ThreadListOptions{}, "")
+		if err != nil || len(page.Threads) != 1 {
+			t.Fatalf("viewer %d: expected only the published thread listed, got %+v (%v)", viewer, page, err)
+		}
+	}
+
+	past := time.Now().Add(-time.Minute)
+	if err := svc.SetPublishing(ctx, id, entities.ThreadStatusScheduled, &past); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput for a past publish time, got %v", err)
+	}
+	if err := svc.SetPublishing(ctx, id, entities.ThreadStatusPublished, nil); err != nil {
+		t.Fatalf("publish now: %v", err)
+	}
+	if _, err := svc.GetThreadByID(WithViewer(ctx, 2), id); err != nil {
+		t.Fatalf("published thread should be visible to everyone: %v", err)
+	}
+	if err := svc.SetPublishing(ctx, id, entities.ThreadStatusDraft, nil); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict unpublishing a live thread, got %v", err)
+	}
+}
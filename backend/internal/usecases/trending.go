@@ -0,0 +1,169 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// Trending ranks threads by net votes plus weighted recent replies, divided by a power of
+// their age so that older threads sink unless they keep attracting activity.
+const (
+	// TrendingWindow limits ranking to threads with activity this recent
+	TrendingWindow = 7 * 24 * time.Hour
+	// TrendingTTL is how long a stored ranking is served; a stalled job falls back to Postgres after it
+	TrendingTTL = 15 * time.Minute
+	// MaxTrendingThreads caps how many threads are ranked, and so how deep trending pages go
+	MaxTrendingThreads = 1000
+
+	trendingVelocityWindow = 24 * time.Hour
+	trendingReplyWeight    = 2.0
+	trendingGravity        = 1.5
+)
+
+// TrendingStore keeps the latest trending ranking. Range reports ok=false when no ranking is
+// stored, e.g. before the first refresh or after it expired; an empty ranking is still stored.
+type TrendingStore interface {
+	// Replace swaps in a new ranking as a whole, under a new generation; it expires after ttl
+	Replace(ctx context.Context, scores map[int]float64, ttl time.Duration) error
+	// Range returns up to limit thread IDs starting at offset, best first, and the generation
+	// of the ranking they come from
+	Range(ctx context.Context, offset, limit int) (ids []int, gen int64, ok bool, err error)
+}
+
+// trendingCursor carries the generation of the ranking the previous page came from; offsets
+// into a different ranking would skip or repeat threads. Rankings computed on the fly have
+// generation 0.
+type trendingCursor struct {
+	Offset int   `json:"o"`
+	Gen    int64 `json:"g,omitempty"`
+}
+
+func trendingScore(a entities.ThreadActivity, now time.Time) float64 {
+	points := float64(a.Upvotes-a.Downvotes) + trendingReplyWeight*float64(a.RecentReplies)
+	age := math.Max(now.Sub(a.CreatedAt).Hours(), 0)
+	return points / math.Pow(age+2, trendingGravity)
+}
+
+// trendingScores scores the threads active inside TrendingWindow.
+func (s *threadService) trendingScores(ctx context.Context) (map[int]float64, error) {
+	// thread and reply timestamps are stored without a zone, in UTC
+	now := time.Now().UTC()
+	activity, err := s.repo.ListThreadActivity(ctx, now.Add(-TrendingWindow), now.Add(-trendingVelocityWindow), MaxTrendingThreads)
+	if err != nil {
+		return nil, fmt.Errorf("list thread activity: %w", err)
+	}
+	scores := make(map[int]float64, len(activity))
+	for _, a := range activity {
+		scores[a.ThreadID] = trendingScore(a, now)
+	}
+	return scores, nil
+}
+
+func (s *threadService) RefreshTrending(ctx context.Context) (int, error) {
+	if s.trending == nil {
+		return 0, nil
+	}
+	scores, err := s.trendingScores(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.trending.Replace(ctx, scores, TrendingTTL); err != nil {
+		return 0, fmt.Errorf("store trending: %w", err)
+	}
+	return len(scores), nil
+}
+
+func (s *threadService) ListTrending(ctx context.Context, limit int, cursor string) (*entities.ThreadPage, error) {
+	limit = clampInt(limit, DefaultThreadPageSize, MaxThreadPageSize)
+	var c trendingCursor
+	if cursor != "" {
+		if err := decodeCursor(cursor, &c); err != nil {
+			return nil, err
+		}
+		if c.Offset <= 0 {
+			return nil, ErrInvalidCursor
+		}
+	}
+	page := &entities.ThreadPage{Threads: []*entities.Thread{}}
+	if c.Offset >= MaxTrendingThreads {
+		return page, nil
+	}
+
+	// fetch one extra ID to learn whether another page exists
+	ids, gen, ok := s.storedTrending(ctx, c.Offset, limit+1)
+	if c.Offset > 0 && gen != c.Gen {
+		// the ranking was replaced since the previous page; start over from its first page
+		c.Offset = 0
+		ids, gen, ok = s.storedTrending(ctx, 0, limit+1)
+	}
+	if !ok {
+		var err error
+		ids, err = s.repo.RankTrendingThreads(ctx, s.trendingQuery(c.Offset, limit+1))
+		if err != nil {
+			return nil, fmt.Errorf("rank trending threads: %w", err)
+		}
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+		next, err := encodeCursor(trendingCursor{Offset: c.Offset + limit, Gen: gen})
+		if err != nil {
+			return nil, fmt.Errorf("encode cursor: %w", err)
+		}
+		page.NextCursor = next
+	}
+
+	threads, err := s.repo.GetThreadsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get trending threads: %w", err)
+	}
+	byID := make(map[int]*entities.Thread, len(threads))
+	for _, t := range threads {
+		byID[t.ID] = t
+	}
+	// threads deleted or unpublished since the ranking was stored are skipped
+	for _, id := range ids {
+		if t, ok := byID[id]; ok {
+			page.Threads = append(page.Threads, t)
+		}
+	}
+	s.fillPreviews(ctx, page.Threads)
+	s.ApplyViewerState(ctx, page.Threads...)
+	return page, nil
+}
+
+// storedTrending reads a slice of the stored ranking; ok is false when there is none, and
+// gen is then 0.
+func (s *threadService) storedTrending(ctx context.Context, offset, limit int) ([]int, int64, bool) {
+	if s.trending == nil {
+		return nil, 0, false
+	}
+	ids, gen, ok, err := s.trending.Range(ctx, offset, limit)
+	if err != nil {
+		log.Printf("trending: read ranking: %v", err)
+		return nil, 0, false
+	}
+	if !ok {
+		return nil, 0, false
+	}
+	return ids, gen, true
+}
+
+// trendingQuery ranks threads in Postgres the way RefreshTrending does, when no stored
+// ranking is available.
+func (s *threadService) trendingQuery(offset, limit int) entities.TrendingQuery {
+	now := time.Now().UTC()
+	return entities.TrendingQuery{
+		ActiveSince:  now.Add(-TrendingWindow),
+		RepliesSince: now.Add(-trendingVelocityWindow),
+		ReplyWeight:  trendingReplyWeight,
+		Gravity:      trendingGravity,
+		Candidates:   MaxTrendingThreads,
+		Offset:       offset,
+		Limit:        limit,
+	}
+}
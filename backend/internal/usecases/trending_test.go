@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+type fakeTrendingStore struct {
+	ranked []int // nil means no ranking is stored
+	gen    int64
+}
+
+func (f *fakeTrendingStore) Replace(ctx context.Context, scores map[int]float64, ttl time.Duration) error {
+	f.gen++
+	f.ranked = []int{}
+	for id := range scores {
+		f.ranked = append(f.ranked, id)
+	}
+	sort.Slice(f.ranked, func(i, j int) bool { return scores[f.ranked[i]] > scores[f.ranked[j]] })
+	return nil
+}
+func (f *fakeTrendingStore) Range(ctx context.Context, offset, limit int) ([]int, int64, bool, error) {
+	if f.ranked == nil {
+		return nil, 0, false, nil
+	}
+	if offset >= len(f.ranked) {
+		return nil, f.gen, true, nil
+	}
+	ids := f.ranked[offset:]
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, f.gen, true, nil
+}
+
+// --- tests ---
+func TestTrendingScore_DecaysWithAge(t *testing.T) {
+	now := time.Now()
+	fresh := entities.ThreadActivity{Upvotes: 10, CreatedAt: now.Add(-time.Hour)}
+	old := entities.ThreadActivity{Upvotes: 10, CreatedAt: now.Add(-48 * time.Hour)}
+	busy := entities.ThreadActivity{Upvotes: 10, RecentReplies: 5, CreatedAt: now.Add(-48 * time.Hour)}
+	if trendingScore(fresh, now) <= trendingScore(old, now) {
+		t.Fatalf("expected a fresh thread to outrank an old one with the same votes")
+	}
+	if trendingScore(busy, now) <= trendingScore(old, now) {
+		t.Fatalf("expected recent replies to lift a thread")
+	}
+}
+
+func TestListTrending_FallsBackToPostgresAndPages(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	threads := newFakeThreadRepo()
+	for _, th := range []*entities.Thread{
+		{Title: "old", Upvotes: 10, CreatedAt: now.Add(-72 * time.Hour)},
+		{Title: "hot", Upvotes: 10, CreatedAt: now.Add(-time.Hour)},
+		{Title: "busy", Upvotes: 2, ReplyCount: 6, CreatedAt: now.Add(-time.Hour)},
+		{Title: "draft", Upvotes: 50, CreatedAt: now, Status: entities.ThreadStatusDraft},
+	} {
+		threads.CreateThread(ctx, th)
+	}
+	store := &fakeTrendingStore{}
+	svc := NewThreadService(ThreadServiceDeps{Repo: threads, Trending: store})
+
+	page, err := svc.ListTrending(ctx, 2, "")
+	if err != nil || len(page.Threads) != 2 || page.NextCursor == "" {
+		t.Fatalf("expected a first page of 2 with a cursor, got %+v (%v)", page, err)
+	}
+	if page.Threads[0].Title != "busy" || page.Threads[1].Title != "hot" {
+		t.Fatalf("unexpected order %s, %s", page.Threads[0].Title, page.Threads[1].Title)
+	}
+	page, err = svc.ListTrending(ctx, 2, page.NextCursor)
+	if err != nil || len(page.Threads) != 1 || page.Threads[0].Title != "old" || page.NextCursor != "" {
+		t.Fatalf("expected the last page to hold only the old thread, got %+v (%v)", page, err)
+	}
+
+	// once the job stores a ranking it is served from there, skipping threads deleted since
+	if n, err := svc.RefreshTrending(ctx); err != nil || n != 3 {
+		t.Fatalf("expected 3 ranked threads, got %d (%v)", n, err)
+	}
+	threads.threads[3].IsDeleted = true
+	page, err = svc.ListTrending(ctx, 10, "")
+	if err != nil || len(page.Threads) != 2 || page.Threads[0].Title != "hot" {
+		t.Fatalf("expected the stored ranking without the deleted thread, got %+v (%v)", page, err)
+	}
+}
+
+func TestListTrending_RestartsWhenRankingChanges(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	threads := newFakeThreadRepo()
+	for i := 0; i < 4; i++ {
+		threads.CreateThread(ctx, &entities.Thread{Title: "t", Upvotes: 10 - i, CreatedAt: now.Add(-time.Hour)})
+	}
+	store := &fakeTrendingStore{}
+	svc := NewThreadService(ThreadServiceDeps{Repo: threads, Trending: store})
+	if _, err := svc.RefreshTrending(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	first, err := svc.ListTrending(ctx, 2, "")
+	if err != nil || len(first.Threads) != 2 || first.Threads[0].ID != 1 {
+		t.Fatalf("expected threads 1 and 2 first, got %+v (%v)", first, err)
+	}
+
+	// thread 4 takes the lead in the next refresh; the old cursor restarts at the new top
+	threads.threads[4].Upvotes = 50
+	if _, err := svc.RefreshTrending(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	page, err := svc.ListTrending(ctx, 2, first.NextCursor)
+	if err != nil || len(page.Threads) != 2 || page.Threads[0].ID != 4 || page.Threads[1].ID != 1 {
+		t.Fatalf("expected the first page of the new ranking, got %+v (%v)", page, err)
+	}
+
+	// an empty ranking is served as such rather than recomputed from Postgres
+	for _, th := range threads.threads {
+		th.IsDeleted = true
+	}
+	if n, err := svc.RefreshTrending(ctx); err != nil || n != 0 {
+		t.Fatalf("expected an empty ranking, got %d (%v)", n, err)
+	}
+	for _, th := range threads.threads {
+		th.IsDeleted = false
+	}
+	page, err = svc.ListTrending(ctx, 2, "")
+	if err != nil || len(page.Threads) != 0 {
+		t.Fatalf("expected the stored empty ranking, got %+v (%v)", page, err)
+	}
+}
@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nocson47/beaconofknowledge/internal/repositories"
+)
+
+// viewFlushBatch bounds how many threads one round of FlushViewCounts reads and writes.
+const viewFlushBatch = 500
+
+// ViewCounter estimates unique viewers per thread and remembers which threads were viewed
+// since the last flush. Record is best-effort and must never fail a read.
+type ViewCounter interface {
+	Record(ctx context.Context, threadID int, visitor string)
+	// PopDirty removes and returns up to max threads viewed since they were last flushed
+	PopDirty(ctx context.Context, max int) ([]int, error)
+	// Counts returns the current estimate for each thread
+	Counts(ctx context.Context, threadIDs []int) (map[int]int, error)
+	// MarkDirty queues threads for the next flush again
+	MarkDirty(ctx context.Context, threadIDs []int) error
+}
+
+type ViewService interface {
+	// RecordView counts visitor as a viewer of threadID; visitor identifies a user or an
+	// anonymous client and is only used for deduplication
+	RecordView(ctx context.Context, threadID int, visitor string)
+	// FlushViewCounts copies the estimates of recently viewed threads into the thread store
+	// and returns how many threads were updated
+	FlushViewCounts(ctx context.Context) (int, error)
+}
+
+type viewService struct {
+	counter ViewCounter
+	threads repositories.ThreadRepository
+}
+
+func NewViewService(counter ViewCounter, threads repositories.ThreadRepository) ViewService {
+	return &viewService{counter: counter, threads: threads}
+}
+
+func (s *viewService) RecordView(ctx context.Context, threadID int, visitor string) {
+	if threadID <= 0 || visitor == "" {
+		return
+	}
+	s.counter.Record(ctx, threadID, visitor)
+}
+
+func (s *viewService) FlushViewCounts(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		ids, err := s.counter.PopDirty(ctx, viewFlushBatch)
+		if err != nil {
+			return flushed, fmt.Errorf("pop viewed threads: %w", err)
+		}
+		if len(ids) == 0 {
+			return flushed, nil
+		}
+		counts, err := s.counter.Counts(ctx, ids)
+		if err == nil {
+			err = s.threads.SetViewCounts(ctx, counts)
+		}
+		if err != nil {
+			// put the batch back so its views are not lost until the thread is viewed again
+			if rerr := s.counter.MarkDirty(ctx, ids); rerr != nil {
+				return flushed, fmt.Errorf("flush view counts: %v (requeue: %w)", err, rerr)
+			}
+			return flushed, fmt.Errorf("flush view counts: %w", err)
+		}
+		flushed += len(counts)
+		if len(ids) < viewFlushBatch {
+			return flushed, nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+// fakeViewCounter counts exact unique visitors, which is what a HyperLogLog estimates.
+type fakeViewCounter struct {
+	visitors map[int]map[string]bool
+	dirty    map[int]bool
+}
+
+func newFakeViewCounter() *fakeViewCounter {
+	return &fakeViewCounter{visitors: map[int]map[string]bool{}, dirty: map[int]bool{}}
+}
+
+func (f *fakeViewCounter) Record(ctx context.Context, threadID int, visitor string) {
+	if f.visitors[threadID] == nil {
+		f.visitors[threadID] = map[string]bool{}
+	}
+	f.visitors[threadID][visitor] = true
+	f.dirty[threadID] = true
+}
+func (f *fakeViewCounter) PopDirty(ctx context.Context, max int) ([]int, error) {
+	var ids []int
+	for id := range f.dirty {
+		if len(ids) == max {
+			break
+		}
+		ids = append(ids, id)
+		delete(f.dirty, id)
+	}
+	return ids, nil
+}
+func (f *fakeViewCounter) Counts(ctx context.Context, threadIDs []int) (map[int]int, error) {
+	out := map[int]int{}
+	for _, id := range threadIDs {
+		out[id] = len(f.visitors[id])
+	}
+	return out, nil
+}
+func (f *fakeViewCounter) MarkDirty(ctx context.Context, threadIDs []int) error {
+	for _, id := range threadIDs {
+		f.dirty[id] = true
+	}
+	return nil
+}
+
+// --- tests ---
+func TestViews_FlushStoresUniqueCountsAndNeverLowersThem(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	first, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "a", Body: "b"})
+	second, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "c", Body: "d"})
+	counter := newFakeViewCounter()
+	svc := NewViewService(counter, threads)
+
+	for _, v := range []string{"u:1", "u:1", "ip:10.0.0.1", ""} {
+		svc.RecordView(ctx, first, v)
+	}
+	svc.RecordView(ctx, second, "u:2")
+	n, err := svc.FlushViewCounts(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("expected two threads flushed, got %d (%v)", n, err)
+	}
+	if threads.threads[first].Views != 2 || threads.threads[second].Views != 1 {
+		t.Fatalf("unexpected views %d/%d", threads.threads[first].Views, threads.threads[second].Views)
+	}
+	if n, _ := svc.FlushViewCounts(ctx); n != 0 {
+		t.Fatalf("expected nothing left to flush, got %d", n)
+	}
+
+	// a reset counter (e.g. Redis lost its data) must not lower the stored count
+	counter.visitors[first] = nil
+	svc.RecordView(ctx, first, "u:3")
+	if _, err := svc.FlushViewCounts(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if threads.threads[first].Views != 2 {
+		t.Fatalf("expected views to stay at 2, got %d", threads.threads[first].Views)
+	}
+}
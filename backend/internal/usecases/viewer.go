@@ -0,0 +1,17 @@
+package usecases
+
+import "context"
+
+type viewerKey struct{}
+
+// WithViewer records the authenticated user making the request. Services use it to add
+// per-user state (such as the caller's own vote) to read responses.
+func WithViewer(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, viewerKey{}, userID)
+}
+
+// ViewerID returns the user recorded by WithViewer, or 0 for anonymous requests.
+func ViewerID(ctx context.Context) int {
+	id, _ := ctx.Value(viewerKey{}).(int)
+	return id
+}
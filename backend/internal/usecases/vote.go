@@ -2,7 +2,6 @@ package usecases
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/nocson47/beaconofknowledge/internal/entities"
@@ -13,31 +12,88 @@ type VoteService interface {
 	CreateVote(ctx context.Context, v *entities.Vote) (int, error)
 	GetVoteByID(ctx context.Context, id int) (*entities.Vote, error)
 	GetVoteCountsForThread(ctx context.Context, threadID int) (int, int, error)
+	GetVoteCountsForReply(ctx context.Context, replyID int) (int, int, error)
+	// RetractVote removes v.UserID's vote on v's thread or reply; v.Value is ignored
+	RetractVote(ctx context.Context, v *entities.Vote) error
 }
 
 type voteService struct {
-	repo repositories.VoteRepository
+	repo     repositories.VoteRepository
+	threads  repositories.ThreadRepository
+	replies  repositories.ReplyRepository
+	notifier Notifier // optional
 }
 
-func NewVoteService(repo repositories.VoteRepository) VoteService {
-	return &voteService{repo: repo}
+func NewVoteService(repo repositories.VoteRepository, threads repositories.ThreadRepository, replies repositories.ReplyRepository, notifier Notifier) VoteService {
+	return &voteService{repo: repo, threads: threads, replies: replies, notifier: notifier}
 }
 
 func (s *voteService) CreateVote(ctx context.Context, v *entities.Vote) (int, error) {
 	if v == nil {
-		return 0, errors.New("vote is nil")
+		return 0, invalidf("vote is nil")
 	}
 	if v.Value != 1 && v.Value != -1 {
-		return 0, errors.New("invalid vote value")
+		return 0, invalidf("invalid vote value")
+	}
+	authorID, threadID, err := s.ensureTargetOpen(ctx, v)
+	if err != nil {
+		return 0, err
 	}
 	// delegate to repository (repo can enforce unique constraint)
 	id, err := s.repo.CreateVote(ctx, v)
 	if err != nil {
 		return 0, fmt.Errorf("create vote: %w", err)
 	}
+	// the author hears about each voter's upvote once, however often it is retracted and cast again
+	if v.Value == 1 {
+		notify(ctx, s.notifier, &entities.Notification{
+			UserID: authorID, Kind: entities.NotifyUpvote, ActorID: &v.UserID, ThreadID: &threadID, ReplyID: v.ReplyID,
+			DedupeKey: upvoteDedupeKey(v),
+		})
+	}
 	return id, nil
 }
 
+// upvoteDedupeKey identifies the upvote notice for one voter and post.
+func upvoteDedupeKey(v *entities.Vote) string {
+	if v.ThreadID != nil {
+		return fmt.Sprintf("upvote:thread:%d:%d", *v.ThreadID, v.UserID)
+	}
+	return fmt.Sprintf("upvote:reply:%d:%d", *v.ReplyID, v.UserID)
+}
+
+// ensureTargetOpen checks the vote's target with ensurePostOpen.
+func (s *voteService) ensureTargetOpen(ctx context.Context, v *entities.Vote) (int, int, error) {
+	return ensurePostOpen(ctx, s.threads, s.replies, v.ThreadID, v.ReplyID)
+}
+
+// ensurePostOpen checks that exactly one of threadID and replyID is set and that the thread
+// the post belongs to (directly or through the reply) is neither deleted nor locked. It
+// returns the post's author and thread. Votes and reactions share it.
+func ensurePostOpen(ctx context.Context, threads repositories.ThreadRepository, replies repositories.ReplyRepository, threadID, replyID *int) (int, int, error) {
+	if (threadID == nil) == (replyID == nil) {
+		return 0, 0, invalidf("exactly one of thread_id or reply_id is required")
+	}
+	tid, authorID := 0, 0
+	if threadID != nil {
+		tid = *threadID
+	} else {
+		rep, err := replies.GetReplyByID(ctx, *replyID)
+		if err != nil || rep == nil {
+			return 0, 0, notFoundf("reply not found")
+		}
+		tid, authorID = rep.ThreadID, rep.UserID
+	}
+	t, err := ensureThreadOpen(ctx, threads, tid)
+	if err != nil {
+		return 0, 0, err
+	}
+	if threadID != nil {
+		authorID = t.UserID
+	}
+	return authorID, tid, nil
+}
+
 func (s *voteService) GetVoteByID(ctx context.Context, id int) (*entities.Vote, error) {
 	return s.repo.GetVoteByID(ctx, id)
 }
@@ -45,3 +101,69 @@ func (s *voteService) GetVoteByID(ctx context.Context, id int) (*entities.Vote,
 func (s *voteService) GetVoteCountsForThread(ctx context.Context, threadID int) (int, int, error) {
 	return s.repo.GetVoteCountsForThread(ctx, threadID)
 }
+
+func (s *voteService) GetVoteCountsForReply(ctx context.Context, replyID int) (int, int, error) {
+	rep, err := s.replies.GetReplyByID(ctx, replyID)
+	if err != nil || rep == nil {
+		return 0, 0, notFoundf("reply not found")
+	}
+	return s.repo.GetVoteCountsForReply(ctx, replyID)
+}
+
+func (s *voteService) RetractVote(ctx context.Context, v *entities.Vote) error {
+	if v == nil {
+		return invalidf("vote is nil")
+	}
+	if _, _, err := s.ensureTargetOpen(ctx, v); err != nil {
+		return err
+	}
+	removed, err := s.repo.RetractVote(ctx, v.UserID, v.ThreadID, v.ReplyID)
+	if err != nil {
+		return fmt.Errorf("retract vote: %w", err)
+	}
+	if !removed {
+		return notFoundf("no vote to retract")
+	}
+	return nil
+}
+
+// fillThreadVotes sets MyVote on threads for the request's viewer. It is best-effort: on
+// anonymous requests or lookup failure MyVote stays nil.
+func fillThreadVotes(ctx context.Context, votes repositories.VoteRepository, threads []*entities.Thread) {
+	viewer := ViewerID(ctx)
+	if viewer == 0 || votes == nil || len(threads) == 0 {
+		return
+	}
+	ids := make([]int, len(threads))
+	for i, t := range threads {
+		ids[i] = t.ID
+	}
+	mine, err := votes.GetUserThreadVotes(ctx, viewer, ids)
+	if err != nil {
+		return
+	}
+	for _, t := range threads {
+		v := mine[t.ID]
+		t.MyVote = &v
+	}
+}
+
+// fillReplyVotes is fillThreadVotes for replies.
+func fillReplyVotes(ctx context.Context, votes repositories.VoteRepository, replies []*entities.Reply) {
+	viewer := ViewerID(ctx)
+	if viewer == 0 || votes == nil || len(replies) == 0 {
+		return
+	}
+	ids := make([]int, len(replies))
+	for i, r := range replies {
+		ids[i] = r.ID
+	}
+	mine, err := votes.GetUserReplyVotes(ctx, viewer, ids)
+	if err != nil {
+		return
+	}
+	for _, r := range replies {
+		v := mine[r.ID]
+		r.MyVote = &v
+	}
+}
@@ -0,0 +1,182 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nocson47/beaconofknowledge/internal/entities"
+)
+
+// --- fakes ---
+// fakeVoteRepo keeps one vote per user per post and maintains the posts' denormalized
+// counters; reply votes need replies to be set.
+type fakeVoteRepo struct {
+	threadVotes map[[2]int]int // {userID, threadID} -> value
+	replyVotes  map[[2]int]int // {userID, replyID} -> value
+	threads     *fakeThreadRepo
+	replies     *fakeReplyRepo
+}
+
+func newFakeVoteRepo(threads *fakeThreadRepo) *fakeVoteRepo {
+	return &fakeVoteRepo{threadVotes: map[[2]int]int{}, replyVotes: map[[2]int]int{}, threads: threads}
+}
+
+// target returns the vote map, key and counters for the post a vote is on.
+func (f *fakeVoteRepo) target(userID int, threadID, replyID *int) (map[[2]int]int, [2]int, *int, *int) {
+	if threadID != nil {
+		t := f.threads.threads[*threadID]
+		return f.threadVotes, [2]int{userID, *threadID}, &t.Upvotes, &t.Downvotes
+	}
+	r := f.replies.replies[*replyID]
+	return f.replyVotes, [2]int{userID, *replyID}, &r.Upvotes, &r.Downvotes
+}
+
+func (f *fakeVoteRepo) CreateVote(ctx context.Context, v *entities.Vote) (int, error) {
+	votes, key, up, down := f.target(v.UserID, v.ThreadID, v.ReplyID)
+	switch votes[key] {
+	case 1:
+		*up--
+	case -1:
+		*down--
+	}
+	if v.Value == 1 {
+		*up++
+	} else {
+		*down++
+	}
+	votes[key] = v.Value
+	return len(f.threadVotes) + len(f.replyVotes), nil
+}
+func (f *fakeVoteRepo) GetVoteByID(ctx context.Context, id int) (*entities.Vote, error) {
+	return nil, nil
+}
+func (f *fakeVoteRepo) GetVotesForThread(ctx context.Context, threadID int) ([]entities.Vote, error) {
+	return nil, nil
+}
+func (f *fakeVoteRepo) GetVotesForReply(ctx context.Context, replyID int) ([]entities.Vote, error) {
+	return nil, nil
+}
+func (f *fakeVoteRepo) DeleteVote(ctx context.Context, id int) error { return nil }
+func (f *fakeVoteRepo) RetractVote(ctx context.Context, userID int, threadID, replyID *int) (bool, error) {
+	votes, key, up, down := f.target(userID, threadID, replyID)
+	value, ok := votes[key]
+	if !ok {
+		return false, nil
+	}
+	if value == 1 {
+		*up--
+	} else {
+		*down--
+	}
+	delete(votes, key)
+	return true, nil
+}
+func (f *fakeVoteRepo) GetVoteCountsForThread(ctx context.Context, threadID int) (int, int, error) {
+	t := f.threads.threads[threadID]
+	return t.Upvotes, t.Downvotes, nil
+}
+func (f *fakeVoteRepo) GetVoteCountsForReply(ctx context.Context, replyID int) (int, int, error) {
+	r := f.replies.replies[replyID]
+	return r.Upvotes, r.Downvotes, nil
+}
+func (f *fakeVoteRepo) GetUserThreadVotes(ctx context.Context, userID int, threadIDs []int) (map[int]int, error) {
+	out := map[int]int{}
+	for _, id := range threadIDs {
+		if v, ok := f.threadVotes[[2]int{userID, id}]; ok {
+			out[id] = v
+		}
+	}
+	return out, nil
+}
+func (f *fakeVoteRepo) GetUserReplyVotes(ctx context.Context, userID int, replyIDs []int) (map[int]int, error) {
+	out := map[int]int{}
+	for _, id := range replyIDs {
+		if v, ok := f.replyVotes[[2]int{userID, id}]; ok {
+			out[id] = v
+		}
+	}
+	return out, nil
+}
+
+// --- tests ---
+func TestVoteService_RetractAndMyVote(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	id, _ := threads.CreateThread(ctx, &entities.Thread{Title: "t", Body: "b"})
+	votes := newFakeVoteRepo(threads)
+	voteSvc := NewVoteService(votes, threads, nil, nil)
+	threadSvc := NewThreadService(ThreadServiceDeps{Repo: threads, Votes: votes})
+
+	if _, err := voteSvc.CreateVote(ctx, &entities.Vote{UserID: 7, ThreadID: &id, Value: -1}); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	th := threads.threads[id]
+	threadSvc.ApplyViewerState(ctx, th)
+	if th.MyVote != nil {
+		t.Fatalf("anonymous read got my_vote %d", *th.MyVote)
+	}
+	threadSvc.ApplyViewerState(WithViewer(ctx, 7), th)
+	if th.MyVote == nil || *th.MyVote != -1 {
+		t.Fatalf("expected my_vote -1, got %v", th.MyVote)
+	}
+
+	if err := voteSvc.RetractVote(ctx, &entities.Vote{UserID: 7, ThreadID: &id}); err != nil {
+		t.Fatalf("retract: %v", err)
+	}
+	if th.Downvotes != 0 {
+		t.Fatalf("expected the downvote to be rolled back, got %d", th.Downvotes)
+	}
+	if err := voteSvc.RetractVote(ctx, &entities.Vote{UserID: 7, ThreadID: &id}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on second retract, got %v", err)
+	}
+	threadSvc.ApplyViewerState(WithViewer(ctx, 7), th)
+	if th.MyVote == nil || *th.MyVote != 0 {
+		t.Fatalf("expected my_vote 0 after retracting, got %v", th.MyVote)
+	}
+}
+
+func TestVoteService_ReplyCounters(t *testing.T) {
+	ctx := context.Background()
+	threads := newFakeThreadRepo()
+	threadID, _ := threads.CreateThread(ctx, &entities.Thread{UserID: 1, Title: "t", Body: "b"})
+	replies := newFakeReplyRepo()
+	replyID, _ := replies.CreateReply(ctx, &entities.Reply{ThreadID: threadID, UserID: 2, Body: "r"})
+	votes := newFakeVoteRepo(threads)
+	votes.replies = replies
+	svc := NewVoteService(votes, threads, replies, nil)
+
+	expect := func(step string, wantUp, wantDown int) {
+		t.Helper()
+		up, down, err := svc.GetVoteCountsForReply(ctx, replyID)
+		if err != nil || up != wantUp || down != wantDown {
+			t.Fatalf("%s: expected %d up and %d down, got %d and %d (%v)", step, wantUp, wantDown, up, down, err)
+		}
+	}
+	if _, err := svc.CreateVote(ctx, &entities.Vote{UserID: 3, ReplyID: &replyID, Value: 1}); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if _, err := svc.CreateVote(ctx, &entities.Vote{UserID: 4, ReplyID: &replyID, Value: 1}); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	expect("vote", 2, 0)
+	if _, err := svc.CreateVote(ctx, &entities.Vote{UserID: 3, ReplyID: &replyID, Value: -1}); err != nil {
+		t.Fatalf("switch: %v", err)
+	}
+	expect("switch", 1, 1)
+	if err := svc.RetractVote(ctx, &entities.Vote{UserID: 3, ReplyID: &replyID}); err != nil {
+		t.Fatalf("retract: %v", err)
+	}
+	expect("retract", 1, 0)
+	if threads.threads[threadID].Upvotes != 0 || threads.threads[threadID].Downvotes != 0 {
+		t.Fatalf("expected reply votes to leave the thread's counters alone")
+	}
+
+	missing := replyID + 100
+	if _, _, err := svc.GetVoteCountsForReply(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing reply, got %v", err)
+	}
+	if _, err := svc.CreateVote(ctx, &entities.Vote{UserID: 3, ReplyID: &missing, Value: 1}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound voting on a missing reply, got %v", err)
+	}
+}
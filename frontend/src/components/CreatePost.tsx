@@ -1,11 +1,26 @@
-import React, { useState } from 'react';
+import React, { useEffect, useState } from 'react';
+import api from '../lib/api';
 
 interface Post {
     title: string;
     body: string;
     tags: string[];
+    category_id: number;
 }
 
+interface Category {
+    id: number;
+    name: string;
+    children?: Category[];
+}
+
+// flattenCategories lists the category tree depth-first with nested names indented.
+const flattenCategories = (cats: Category[], depth = 0): { id: number; label: string }[] =>
+    cats.flatMap(c => [
+        { id: c.id, label: `${'\u00a0\u00a0'.repeat(depth)}${c.name}` },
+        ...flattenCategories(c.children || [], depth + 1),
+    ]);
+
 interface Props {
     onSubmit: (post: Post) => void;
 }
@@ -14,11 +29,23 @@ const CreatePost: React.FC<Props> = ({ onSubmit }) => {
     const [title, setTitle] = useState('');
     const [body, setBody] = useState('');
     const [tags, setTags] = useState('');
+    const [categoryId, setCategoryId] = useState(0);
+    const [categories, setCategories] = useState<{ id: number; label: string }[]>([]);
+
+    useEffect(() => {
+        api.getCategories()
+            .then((resp: any) => {
+                const flat = flattenCategories(resp.categories || []);
+                setCategories(flat);
+                if (flat.length) setCategoryId(flat[0].id);
+            })
+            .catch(err => console.error(err));
+    }, []);
 
     const handleSubmit = (e: React.FormEvent) => {
         e.preventDefault();
         const tagArray = tags.split(',').map(tag => tag.trim()).filter(tag => tag !== '');
-        onSubmit({ title, body, tags: tagArray });
+        onSubmit({ title, body, tags: tagArray, category_id: categoryId });
         setTitle('');
         setBody('');
         setTags('');
@@ -35,6 +62,16 @@ const CreatePost: React.FC<Props> = ({ onSubmit }) => {
                         Create New Thread
                     </h1>
                     <div className="space-y-4">
+                        <select
+                            className="appearance-none rounded-lg relative block w-full px-4 py-3 border border-gray-300 text-gray-900 focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-blue-500 transition duration-200"
+                            value={categoryId}
+                            onChange={(e) => setCategoryId(Number(e.target.value))}
+                            required
+                        >
+                            {categories.map(c => (
+                                <option key={c.id} value={c.id}>{c.label}</option>
+                            ))}
+                        </select>
                         <input
                             className="appearance-none rounded-lg relative block w-full px-4 py-3 border border-gray-300 placeholder-gray-500 text-gray-900 focus:outline-none focus:ring-2 focus:ring-blue-500 focus:border-blue-500 transition duration-200"
                             placeholder="Title"
@@ -1,4 +1,4 @@
-import React, { useEffect, useMemo, useState } from 'react';
+import React, { useCallback, useEffect, useMemo, useRef, useState } from 'react';
 import PostItem from './PostItem';
 import type { Post } from '../types/post';
 import api from '../lib/api';
@@ -8,20 +8,39 @@ const PostList: React.FC = () => {
   const [err, setErr] = useState<string | null>(null);
   const [selectedTag, setSelectedTag] = useState<string>('All');
   const [sortOrder, setSortOrder] = useState<'newest' | 'oldest'>('newest');
+  // next_cursor of the last page loaded; null once the feed is exhausted
+  const [cursor, setCursor] = useState<string | null>(null);
+  const [loading, setLoading] = useState(false);
+  const sentinel = useRef<HTMLDivElement | null>(null);
 
-  useEffect(() => {
-    let mounted = true;
-    api.getThreads().then((data: any) => {
-      if (!mounted) return;
-      // assume data is array or { threads }
+  const loadPage = useCallback((after?: string) => {
+    setLoading(true);
+    return api.getThreads({ cursor: after }).then((data: any) => {
       const list = Array.isArray(data) ? data : data.threads || [];
       // defensive: filter out soft-deleted items if backend hasn't already
       const visible = (list || []).filter((p: any) => !(p.is_deleted === true || p.isDeleted === true));
-      setPosts(visible);
-    }).catch((e: any) => setErr(e.message || 'failed'));
-    return () => { mounted = false; };
+      setPosts(prev => {
+        if (!after) return visible;
+        const seen = new Set((prev || []).map((p: any) => p.id));
+        return [...(prev || []), ...visible.filter((p: any) => !seen.has(p.id))];
+      });
+      setCursor(data.next_cursor || null);
+    }).catch((e: any) => setErr(e.message || 'failed')).finally(() => setLoading(false));
   }, []);
 
+  useEffect(() => { loadPage(); }, [loadPage]);
+
+  // keep scrolling: fetch the next page once the bottom of the list comes into view
+  useEffect(() => {
+    const el = sentinel.current;
+    if (!el || !cursor || loading) return;
+    const obs = new IntersectionObserver(entries => {
+      if (entries.some(e => e.isIntersecting)) loadPage(cursor);
+    });
+    obs.observe(el);
+    return () => obs.disconnect();
+  }, [cursor, loading, loadPage]);
+
   // compute unique tags from posts
   const allTags = useMemo(() => {
     if (!posts) return [] as string[];
@@ -82,6 +101,10 @@ const PostList: React.FC = () => {
           <PostItem post={post} />
         </div>
       ))}
+
+      <div ref={sentinel} className="max-w-3xl mx-auto text-center text-gray-500">
+        {loading ? 'กำลังโหลด...' : null}
+      </div>
     </div>
   );
 };
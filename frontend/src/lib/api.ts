@@ -66,8 +66,23 @@ export function getUserLocal() {
   try { return JSON.parse(localStorage.getItem('auth_user') || 'null'); } catch { return null; }
 }
 
-export async function getThreads() {
-  return request('/threads');
+// getThreads fetches one page of GET /threads; pass the previous page's next_cursor to continue.
+export async function getThreads(opts: { cursor?: string; author?: number | string; limit?: number } = {}) {
+  const params = new URLSearchParams();
+  if (opts.cursor) params.set('cursor', opts.cursor);
+  if (opts.author) params.set('author', String(opts.author));
+  if (opts.limit) params.set('limit', String(opts.limit));
+  const qs = params.toString();
+  return request(qs ? `/threads?${qs}` : '/threads');
+}
+
+export async function search(q: string, opts: { type?: 'thread' | 'reply' | 'all'; tags?: string[]; author?: string; cursor?: string } = {}) {
+  const params = new URLSearchParams({ q });
+  if (opts.type) params.set('type', opts.type);
+  if (opts.tags && opts.tags.length) params.set('tags', opts.tags.join(','));
+  if (opts.author) params.set('author', opts.author);
+  if (opts.cursor) params.set('cursor', opts.cursor);
+  return request(`/search?${params.toString()}`);
 }
 
 export async function getThread(id: number | string) {
@@ -83,6 +98,10 @@ export async function getThreadCounts(id: number | string) {
   return request(`/threads/${id}/votes`);
 }
 
+export async function getCategories() {
+  return request('/categories');
+}
+
 export async function createThread(payload: any) {
   return request('/threads', { method: 'POST', body: JSON.stringify(payload) });
 }
@@ -130,12 +149,12 @@ export async function uploadAvatar(file: File, user_id: string) {
   return res.json();
 }
 
-export async function report(kind: 'thread' | 'user', target_id: number | string, reason?: string) {
+export async function report(kind: 'thread' | 'reply' | 'user', target_id: number | string, reason?: string) {
   const body = JSON.stringify({ kind, target_id: Number(target_id), reason });
   return request('/reports', { method: 'POST', body });
 }
 
-export async function getReports(kind?: 'thread' | 'user') {
+export async function getReports(kind?: 'thread' | 'reply' | 'user') {
   const q = kind ? `?kind=${encodeURIComponent(kind)}` : '';
   return request(`/reports${q}`);
 }
@@ -154,4 +173,4 @@ export async function resetPassword(token: string, password: string) {
   return request('/auth/reset', { method: 'POST', body });
 }
 
-export default { login, register, logout, getThreads, getThread, createThread, updateThread, deleteThread, voteThread, getThreadCounts, getUserByID, updateUser, uploadAvatar, report, getReports, getMe, getUserLocal, getRepliesByThread, createReply, updateReply, deleteReply, requestPasswordReset, resetPassword };
+export default { login, register, logout, getThreads, getCategories, search, getThread, createThread, updateThread, deleteThread, voteThread, getThreadCounts, getUserByID, updateUser, uploadAvatar, report, getReports, getMe, getUserLocal, getRepliesByThread, createReply, updateReply, deleteReply, requestPasswordReset, resetPassword };
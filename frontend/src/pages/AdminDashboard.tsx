@@ -11,12 +11,19 @@ const AdminDashboard: React.FC = () => {
   useEffect(() => {
     (async () => {
       try {
-  const threads: any = await api.getThreads();
+        // count published threads by walking every page of the listing
+        let threadTotal = 0;
+        let cursor: string | undefined;
+        do {
+          const page: any = await api.getThreads({ cursor, limit: 100 });
+          threadTotal += (page.threads || []).length;
+          cursor = page.next_cursor || undefined;
+        } while (cursor);
         // get all users endpoint exists at /users (may return array)
         let usersList: any[] = [];
         try { const ul = await fetch((import.meta.env.VITE_API_BASE_URL || 'http://localhost:3000') + '/users'); if (ul.ok) usersList = await ul.json(); } catch {}
         const reps: any = await api.getReports().catch(()=>({reports:[]}));
-        setThreadsCount(Array.isArray(threads) ? threads.length : (threads.threads ? threads.threads.length : null));
+        setThreadsCount(threadTotal);
         setUsersCount(Array.isArray(usersList) ? usersList.length : null);
         const rlist = reps.reports || reps || [];
         setReportsCount(Array.isArray(rlist) ? rlist.length : null);
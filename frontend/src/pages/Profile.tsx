@@ -6,6 +6,7 @@ const Profile: React.FC = () => {
   const { id } = useParams<{ id: string }>();
   const [user, setUser] = useState<Record<string, any> | null>(null);
   const [posts, setPosts] = useState<Array<Record<string, any>> | null>(null);
+  const [cursor, setCursor] = useState<string | null>(null);
   const [err, setErr] = useState<string | null>(null);
   const [editing, setEditing] = useState(false);
   const [bio, setBio] = useState('');
@@ -17,13 +18,23 @@ const Profile: React.FC = () => {
     if (!id) return;
     let mounted = true;
   api.getUserByID(id).then((u: Record<string, any>) => { if (mounted) { setUser(u); setBio(u.bio || ''); setSocial(u.social || ''); } }).catch((e: any) => setErr(e.message || 'failed to load user'));
-    api.getThreads().then((list: any) => {
-      const arr: Array<Record<string, any>> = Array.isArray(list) ? list : list.threads || [];
-      if (mounted) setPosts(arr.filter(p => String(p.user_id) === String(id)));
+    api.getThreads({ author: id }).then((page: any) => {
+      if (!mounted) return;
+      setPosts(page.threads || []);
+      setCursor(page.next_cursor || null);
     }).catch(() => {});
     return () => { mounted = false; };
   }, [id]);
 
+  const loadMore = async () => {
+    if (!cursor) return;
+    try {
+      const page: any = await api.getThreads({ author: id, cursor });
+      setPosts(prev => [...(prev || []), ...(page.threads || [])]);
+      setCursor(page.next_cursor || null);
+    } catch {}
+  };
+
   if (err) return <div className="text-red-500">{err}</div>;
   if (!user) return <div>Loading user...</div>;
 
@@ -120,6 +131,9 @@ const Profile: React.FC = () => {
           </div>
         )) : <div>Loading posts...</div>}
       </div>
+      {cursor ? (
+        <button className="mt-4 px-4 py-2 bg-gray-100 rounded" onClick={loadMore}>Load more</button>
+      ) : null}
     </div>
   );
 };
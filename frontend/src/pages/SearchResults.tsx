@@ -17,18 +17,13 @@ const SearchResults: React.FC = () => {
       setLoading(true);
       setErr(null);
       try {
-        // simple approach: fetch all threads and filter client-side
-        const resp: any = await api.getThreads();
-        const list = Array.isArray(resp) ? resp : (resp.threads || []);
-        const qlc = q.trim().toLowerCase();
-        if (!qlc) {
-          setResults(list);
-        } else {
-          const filtered = list.filter((t: any) => {
-            return (t.title || '').toLowerCase().includes(qlc) || (t.body || '').toLowerCase().includes(qlc);
-          });
-          setResults(filtered);
+        const qt = q.trim();
+        if (!qt) {
+          setResults([]);
+          return;
         }
+        const resp: any = await api.search(qt);
+        setResults(resp.results || []);
       } catch (e: any) {
         setErr(e.message || 'failed to search');
       } finally {
@@ -45,11 +40,14 @@ const SearchResults: React.FC = () => {
       {err && <div className="text-red-500">{err}</div>}
       {!loading && results.length === 0 && <div className="text-gray-600">No results</div>}
       <div className="space-y-4 mt-4">
-        {results.map((t: any) => (
-          <article key={t.id} className="p-4 bg-white rounded shadow">
-            <h3 className="text-lg font-bold"><Link to={`/threads/${t.id}`}>{t.title}</Link></h3>
-            <p className="text-sm text-gray-600">by {t.username || t.user_id}</p>
-            <p className="mt-2 text-gray-700">{(t.body || '').slice(0, 240)}{(t.body||'').length>240? '…':''}</p>
+        {results.map((r: any) => (
+          <article key={`${r.kind}-${r.reply_id ?? r.thread_id}`} className="p-4 bg-white rounded shadow">
+            <h3 className="text-lg font-bold">
+              <Link to={`/threads/${r.thread_id}${r.reply_id ? `#reply-${r.reply_id}` : ''}`}>{r.title}</Link>
+            </h3>
+            <p className="text-sm text-gray-600">{r.kind === 'reply' ? 'reply ' : ''}by {r.author || r.user_id}</p>
+            {/* snippet is HTML-escaped by the server; only <mark> tags are added */}
+            <p className="mt-2 text-gray-700" dangerouslySetInnerHTML={{ __html: r.snippet }} />
           </article>
         ))}
       </div>
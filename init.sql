@@ -111,11 +111,11 @@ WHERE u.username = 'alice' AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.user_id
 
 -- End of init.sql
 
--- Reports: user-submitted reports for threads or users
+-- Reports: user-submitted reports for threads, replies or users
   CREATE TABLE IF NOT EXISTS reports (
     id INT AUTO_INCREMENT PRIMARY KEY,
     reporter_id INT NULL,
-    kind ENUM('thread','user') NOT NULL,
+    kind ENUM('thread','reply','user') NOT NULL,
     target_id INT NOT NULL,
     reason TEXT,
     status ENUM('open','resolved','dismissed') NOT NULL DEFAULT 'open',
@@ -138,4 +138,330 @@ WHERE u.username = 'alice' AND NOT EXISTS (SELECT 1 FROM votes v WHERE v.user_id
 );
 
 CREATE INDEX idx_password_resets_user ON password_resets(user_id);
-CREATE INDEX idx_password_resets_token_hash ON password_resets(token_hash);
\ No newline at end of file
+CREATE INDEX idx_password_resets_token_hash ON password_resets(token_hash);
+
+-- Thread listing: denormalized reply counters and keyset pagination indexes
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS reply_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE threads t SET
+  reply_count = COALESCE((SELECT COUNT(*) FROM replies r WHERE r.thread_id = t.id AND r.is_deleted = false), 0),
+  last_activity_at = GREATEST(t.created_at, COALESCE((SELECT MAX(r.created_at) FROM replies r WHERE r.thread_id = t.id AND r.is_deleted = false), t.created_at));
+
+CREATE INDEX IF NOT EXISTS idx_threads_list_new ON threads (created_at DESC, id DESC) WHERE is_deleted = false;
+CREATE INDEX IF NOT EXISTS idx_threads_list_top ON threads ((upvotes - downvotes) DESC, id DESC) WHERE is_deleted = false;
+CREATE INDEX IF NOT EXISTS idx_threads_list_discussed ON threads (reply_count DESC, id DESC) WHERE is_deleted = false;
+CREATE INDEX IF NOT EXISTS idx_threads_list_activity ON threads (last_activity_at DESC, id DESC) WHERE is_deleted = false;
+
+-- Full-text search: generated tsvector columns (title weighted above body) and GIN indexes
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS search_vector tsvector
+  GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(body, '')), 'B')) STORED;
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS search_vector tsvector
+  GENERATED ALWAYS AS (to_tsvector('english', coalesce(body, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_threads_search ON threads USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_replies_search ON replies USING GIN (search_vector);
+
+-- Tag aliases: alternative spellings that resolve to a canonical tag (e.g. golang -> go)
+CREATE TABLE IF NOT EXISTS tag_aliases (
+  alias VARCHAR(100) PRIMARY KEY,
+  tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_tag_aliases_tag_id ON tag_aliases(tag_id);
+
+-- Fold tag rows written before names were normalized ("Go", "go ", "Web_Dev") into one row
+-- per normalized name: links and aliases move to the lowest id, the other rows are dropped
+-- and the survivor takes the normalized name. Mirrors entities.NormalizeTag.
+CREATE TEMP TABLE tag_renames AS
+SELECT id, canon, MIN(id) OVER (PARTITION BY canon) AS keep_id
+FROM (
+  SELECT id, btrim(regexp_replace(regexp_replace(lower(btrim(name)), '[^[:alnum:]+#.[:space:]_-]', '', 'g'), '[[:space:]_-]+', '-', 'g'), '-') AS canon
+  FROM tags
+) n
+WHERE canon <> '';
+INSERT INTO thread_tags (thread_id, tag_id)
+SELECT tt.thread_id, r.keep_id FROM thread_tags tt JOIN tag_renames r ON r.id = tt.tag_id WHERE r.id <> r.keep_id
+ON CONFLICT DO NOTHING;
+UPDATE tag_aliases a SET tag_id = r.keep_id FROM tag_renames r WHERE a.tag_id = r.id AND r.id <> r.keep_id;
+DELETE FROM tags t USING tag_renames r WHERE t.id = r.id AND r.id <> r.keep_id;
+UPDATE tags t SET name = r.canon FROM tag_renames r WHERE t.id = r.id AND t.name <> r.canon;
+-- an alias spelled like a tag name would never be consulted
+DELETE FROM tag_aliases a USING tags t WHERE a.alias = t.name;
+DROP TABLE tag_renames;
+
+-- Edit history: each row is the content of a thread or reply as it was before an edit
+CREATE TABLE IF NOT EXISTS post_revisions (
+  id SERIAL PRIMARY KEY,
+  thread_id INTEGER NULL REFERENCES threads(id) ON DELETE CASCADE,
+  reply_id INTEGER NULL REFERENCES replies(id) ON DELETE CASCADE,
+  editor_id INTEGER NULL REFERENCES users(id) ON DELETE SET NULL,
+  reason TEXT NULL,
+  title VARCHAR(200) NULL,
+  body TEXT NOT NULL,
+  tags TEXT[] NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CHECK ((thread_id IS NULL) <> (reply_id IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_post_revisions_thread ON post_revisions(thread_id, created_at DESC) WHERE thread_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_post_revisions_reply ON post_revisions(reply_id, created_at DESC) WHERE reply_id IS NOT NULL;
+
+-- Thread lock metadata: who locked a thread, when, and why
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS locked_by INTEGER NULL REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP NULL;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS lock_reason TEXT NULL;
+
+-- Pinned threads: global pins lead every listing, tag pins lead their tag's listing.
+-- A pin with pinned_until in the past is ignored on read and cleared by a background job.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS pin_scope TEXT NULL CHECK (pin_scope IN ('global', 'tag'));
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS pin_tag TEXT NULL;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS pinned_until TIMESTAMP NULL;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS pinned_by INTEGER NULL REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_threads_pinned ON threads (pinned_at DESC) WHERE pin_scope IS NOT NULL;
+
+-- Categories: a hierarchy of subforums. Every thread belongs to one category; existing
+-- threads are moved into "general".
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    parent_id INTEGER NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    slug VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    read_only BOOLEAN NOT NULL DEFAULT false,
+    min_post_role VARCHAR(20) NULL,
+    default_tags TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories (parent_id, position);
+
+INSERT INTO categories (slug, name, description) VALUES ('general', 'General', 'General discussion')
+ON CONFLICT (slug) DO NOTHING;
+
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS category_id INTEGER NULL REFERENCES categories(id) ON DELETE RESTRICT;
+UPDATE threads SET category_id = (SELECT id FROM categories WHERE slug = 'general') WHERE category_id IS NULL;
+ALTER TABLE threads ALTER COLUMN category_id SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_threads_category ON threads (category_id);
+
+-- Reply tree views page through each parent's children in creation order
+CREATE INDEX IF NOT EXISTS idx_replies_thread_parent ON replies (thread_id, parent_id, created_at, id);
+
+-- Denormalized reply vote counters, maintained by the vote repository
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS upvotes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE replies ADD COLUMN IF NOT EXISTS downvotes INTEGER NOT NULL DEFAULT 0;
+UPDATE replies r SET
+    upvotes = (SELECT COUNT(*) FROM votes v WHERE v.reply_id = r.id AND v.value = 1),
+    downvotes = (SELECT COUNT(*) FROM votes v WHERE v.reply_id = r.id AND v.value = -1);
+
+-- Q&A: the reply marked as the accepted answer; a thread is solved while one is set
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS accepted_reply_id INTEGER NULL REFERENCES replies(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_threads_solved ON threads ((accepted_reply_id IS NOT NULL), created_at DESC, id DESC) WHERE is_deleted = FALSE;
+
+-- Polls: at most one per thread. Each user has a single ballot per poll (the primary key),
+-- and a ballot may only pick options of its own poll (the composite foreign key).
+CREATE TABLE IF NOT EXISTS polls (
+    id SERIAL PRIMARY KEY,
+    thread_id INTEGER NOT NULL UNIQUE REFERENCES threads(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    multiple_choice BOOLEAN NOT NULL DEFAULT false,
+    results_before_vote BOOLEAN NOT NULL DEFAULT true,
+    closes_at TIMESTAMP NULL,
+    closed_at TIMESTAMP NULL,
+    created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id SERIAL PRIMARY KEY,
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    UNIQUE (poll_id, position),
+    UNIQUE (poll_id, id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_ballots (
+    poll_id INTEGER NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (poll_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS poll_ballot_choices (
+    poll_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    option_id INTEGER NOT NULL,
+    PRIMARY KEY (poll_id, user_id, option_id),
+    FOREIGN KEY (poll_id, user_id) REFERENCES poll_ballots(poll_id, user_id) ON DELETE CASCADE,
+    FOREIGN KEY (poll_id, option_id) REFERENCES poll_options(poll_id, id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_poll_ballot_choices_option ON poll_ballot_choices (option_id);
+
+-- Single-choice polls allow one choice per ballot. single_choice copies the poll's setting onto
+-- each choice so a partial unique index can enforce it; extra choices that slipped in before
+-- are dropped, keeping the lowest option.
+ALTER TABLE poll_ballot_choices ADD COLUMN IF NOT EXISTS single_choice BOOLEAN NOT NULL DEFAULT false;
+UPDATE poll_ballot_choices c SET single_choice = true
+FROM polls p WHERE p.id = c.poll_id AND NOT p.multiple_choice AND NOT c.single_choice;
+DELETE FROM poll_ballot_choices c USING poll_ballot_choices k
+WHERE c.single_choice AND k.poll_id = c.poll_id AND k.user_id = c.user_id AND k.option_id < c.option_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poll_ballot_single_choice ON poll_ballot_choices (poll_id, user_id) WHERE single_choice;
+
+-- Drafts: autosaved, unpublished posts, one per user and key (see entities.DraftKey).
+-- Every save pushes expires_at back; expired rows are hidden and purged by a background job.
+CREATE TABLE IF NOT EXISTS drafts (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key VARCHAR(64) NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+CREATE INDEX IF NOT EXISTS idx_drafts_expires ON drafts (expires_at);
+
+-- Scheduled publishing: draft and scheduled threads stay hidden from everyone but their author
+-- and admins until a background job (or the author) publishes them. Existing threads are published.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'published' CHECK (status IN ('draft', 'scheduled', 'published'));
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS publish_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_threads_publish_due ON threads (publish_at) WHERE status = 'scheduled';
+
+-- Thread subscriptions: who watches a thread and at which level. Muted rows are kept so
+-- auto-watch on participation does not subscribe the user again.
+CREATE TABLE IF NOT EXISTS thread_subscriptions (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    thread_id INTEGER NOT NULL REFERENCES threads(id) ON DELETE CASCADE,
+    level VARCHAR(16) NOT NULL DEFAULT 'all' CHECK (level IN ('all', 'replies-to-me', 'muted')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, thread_id)
+);
+CREATE INDEX IF NOT EXISTS idx_thread_subscriptions_user ON thread_subscriptions (user_id, created_at DESC, thread_id DESC);
+CREATE INDEX IF NOT EXISTS idx_thread_subscriptions_thread ON thread_subscriptions (thread_id) WHERE level <> 'muted';
+
+-- Notifications: one row per recipient, written by the reply, vote and report services.
+-- report_id is text because reports may live in MongoDB. Unread counts are cached in Redis.
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    actor_id INTEGER NULL REFERENCES users(id) ON DELETE SET NULL,
+    thread_id INTEGER NULL REFERENCES threads(id) ON DELETE CASCADE,
+    reply_id INTEGER NULL REFERENCES replies(id) ON DELETE CASCADE,
+    report_id TEXT NULL,
+    message TEXT NULL,
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+-- dedupe_key makes a notification one-off per user, e.g. one upvote notice per voter and post
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS dedupe_key TEXT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_dedupe ON notifications (user_id, dedupe_key) WHERE dedupe_key IS NOT NULL;
+
+-- Mentions: resolved @usernames per post. reply_id is NULL for the thread body itself;
+-- offsets are byte positions of the @username token in the Markdown body.
+CREATE TABLE IF NOT EXISTS post_mentions (
+    thread_id INTEGER NOT NULL REFERENCES threads(id) ON DELETE CASCADE,
+    reply_id INTEGER NULL REFERENCES replies(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(32) NOT NULL,
+    start_offset INTEGER NOT NULL,
+    end_offset INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_post_mentions_thread ON post_mentions (thread_id) WHERE reply_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_post_mentions_reply ON post_mentions (reply_id) WHERE reply_id IS NOT NULL;
+
+-- Users already notified about a mention in each post (thread body or reply), so removing
+-- and re-adding an @mention across edits never notifies the same user again.
+CREATE TABLE IF NOT EXISTS post_mention_notices (
+    thread_id INTEGER NULL REFERENCES threads(id) ON DELETE CASCADE,
+    reply_id INTEGER NULL REFERENCES replies(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((thread_id IS NULL) <> (reply_id IS NULL))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_mention_notices_thread ON post_mention_notices (thread_id, user_id) WHERE thread_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_mention_notices_reply ON post_mention_notices (reply_id, user_id) WHERE reply_id IS NOT NULL;
+-- mentions stored before this table existed were already notified, except in threads
+-- that are not published yet
+INSERT INTO post_mention_notices (thread_id, reply_id, user_id)
+SELECT DISTINCT CASE WHEN m.reply_id IS NULL THEN m.thread_id END, m.reply_id, m.user_id
+FROM post_mentions m JOIN threads t ON t.id = m.thread_id
+WHERE m.reply_id IS NOT NULL OR t.status = 'published'
+ON CONFLICT DO NOTHING;
+
+-- Bookmarks: a user's saved threads and replies, optionally filed into named folders
+-- ('' is unfiled). Each row targets exactly one of a thread or a reply.
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    thread_id INTEGER NULL REFERENCES threads(id) ON DELETE CASCADE,
+    reply_id INTEGER NULL REFERENCES replies(id) ON DELETE CASCADE,
+    folder VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((thread_id IS NULL) <> (reply_id IS NULL))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_thread ON bookmarks (user_id, thread_id) WHERE thread_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_user_reply ON bookmarks (user_id, reply_id) WHERE reply_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_id ON bookmarks (user_id, id DESC);
+
+-- Reactions: emoji responses to threads and replies, one per user, post and emoji. They are
+-- separate from votes and never touch the upvotes/downvotes counters.
+CREATE TABLE IF NOT EXISTS reactions (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    thread_id INTEGER NULL REFERENCES threads(id) ON DELETE CASCADE,
+    reply_id INTEGER NULL REFERENCES replies(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((thread_id IS NULL) <> (reply_id IS NULL))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_thread ON reactions (thread_id, user_id, emoji) WHERE thread_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_reply ON reactions (reply_id, user_id, emoji) WHERE reply_id IS NOT NULL;
+
+-- Views: approximate unique viewers per thread. Views are counted in Redis HyperLogLogs and
+-- flushed here by a background job, so the column lags reads by up to a minute.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS view_count BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_threads_list_viewed ON threads (view_count DESC, id DESC) WHERE is_deleted = false;
+
+-- Related threads: title similarity uses pg_trgm; the trigram index serves the % operator.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_threads_title_trgm ON threads USING GIN (title gin_trgm_ops);
+
+-- Merge and split: a merged thread stays as a locked redirect stub pointing at the thread
+-- that received its replies. moderation_log records these operations; its ids are kept as
+-- plain integers so entries outlive the threads they mention.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS merged_into INTEGER NULL REFERENCES threads(id) ON DELETE SET NULL;
+CREATE TABLE IF NOT EXISTS moderation_log (
+    id SERIAL PRIMARY KEY,
+    actor_id INTEGER NULL REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(32) NOT NULL,
+    thread_id INTEGER NOT NULL,
+    target_thread_id INTEGER NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_moderation_log_thread ON moderation_log (thread_id, created_at DESC);
+
+-- Attachments: uploaded files, stored in a blob store under storage_key. An upload starts
+-- unlinked; rows still unlinked after a grace period are garbage-collected together with
+-- their blobs. Hard-deleting a post unlinks its attachments so they are collected too.
+CREATE TABLE IF NOT EXISTS attachments (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    thread_id INTEGER NULL REFERENCES threads(id) ON DELETE SET NULL,
+    reply_id INTEGER NULL REFERENCES replies(id) ON DELETE SET NULL,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(128) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (thread_id IS NULL OR reply_id IS NULL)
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_user ON attachments (user_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_thread ON attachments (thread_id) WHERE thread_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_reply ON attachments (reply_id) WHERE reply_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_unlinked ON attachments (created_at) WHERE thread_id IS NULL AND reply_id IS NULL;